@@ -4,19 +4,94 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"strings"
+	"time"
 
 	"github.com/cloudfoundry/bosh-utils/errors"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type ServerConfig struct {
-	Port                   int
-	CertificateFilePath    string `json:"certificate_file_path"`
-	PrivateKeyFilePath     string `json:"private_key_file_path"`
-	JwtVerificationKeyPath string `json:"jwt_verification_key_path"`
-	CACertificateFilePath  string `json:"ca_certificate_file_path"`
-	CAPrivateKeyFilePath   string `json:"ca_private_key_file_path"`
-	Store                  string
-	Database               DBConfig
+	Port                          int
+	CertificateFilePath           string              `json:"certificate_file_path"`
+	PrivateKeyFilePath            string              `json:"private_key_file_path"`
+	JwtVerificationKeyPath        string              `json:"jwt_verification_key_path"`
+	JwtVerificationKeysDir        string              `json:"jwt_verification_keys_dir"`
+	JwtTokenKeysURL               string              `json:"jwt_token_keys_url"`
+	JwtTokenKeysCACertificatePath string              `json:"jwt_token_keys_ca_certificate_path"`
+	JwtKeysRefreshSeconds         int                 `json:"jwt_keys_refresh_seconds"`
+	JwtIssuer                     string              `json:"jwt_issuer"`
+	JwtAudience                   string              `json:"jwt_audience"`
+	JwtClockSkewSeconds           int                 `json:"jwt_clock_skew_seconds"`
+	ClientCertificates            ClientCertsConfig   `json:"client_certificates"`
+	APIKeys                       []APIKeyConfig      `json:"api_keys"`
+	CACertificateFilePath         string              `json:"ca_certificate_file_path"`
+	CAPrivateKeyFilePath          string              `json:"ca_private_key_file_path"`
+	CertificateAuthorities        map[string]CAConfig `json:"certificate_authorities"`
+	DeletedRetentionHours         int                 `json:"deleted_retention_hours"`
+	Webhooks                      []WebhookConfig     `json:"webhooks"`
+	WebhookDeadLetterPath         string              `json:"webhook_dead_letter_path"`
+	AuditLogPath                  string              `json:"audit_log_path"`
+	PolicyFilePath                string              `json:"policy_file_path"`
+	ShutdownTimeoutSeconds        int                 `json:"shutdown_timeout_seconds"`
+	Store                         string
+	Database                      DBConfig
+}
+
+// DefaultDeletedRetention is how long deleted names can be undeleted when
+// deleted_retention_hours is not configured.
+const DefaultDeletedRetention = 7 * 24 * time.Hour
+
+// DefaultJwtKeysRefresh is how often JWT verification keys are reloaded when
+// jwt_keys_refresh_seconds is not configured.
+const DefaultJwtKeysRefresh = 5 * time.Minute
+
+// DefaultShutdownTimeout is how long in-flight requests are drained on
+// shutdown when shutdown_timeout_seconds is not configured.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// DefaultWebhookMaxAttempts is how often a notification is sent before it is
+// dead-lettered when max_attempts is not configured.
+const DefaultWebhookMaxAttempts = 5
+
+// WebhookConfig registers a URL to be notified of writes to names starting
+// with Prefix. Notifications are signed with Secret.
+type WebhookConfig struct {
+	URL         string `json:"url"`
+	Prefix      string `json:"prefix"`
+	Secret      string `json:"secret"`
+	MaxAttempts int    `json:"max_attempts"`
+}
+
+// ClientCertsConfig enables authentication with client certificates signed
+// by the CA at CACertificatePath. Certificates are mapped to identities by
+// their subject common name or DNS subject alternative names.
+type ClientCertsConfig struct {
+	CACertificatePath string                 `json:"ca_certificate_path"`
+	Identities        []ClientIdentityConfig `json:"identities"`
+}
+
+// ClientIdentityConfig grants Scopes to certificates with CommonName as their
+// subject common name, or DNSName as one of their DNS names. Name is the
+// identity of the client and defaults to the matched name.
+type ClientIdentityConfig struct {
+	Name       string   `json:"name"`
+	CommonName string   `json:"common_name"`
+	DNSName    string   `json:"dns_name"`
+	Scopes     []string `json:"scopes"`
+}
+
+// APIKeyConfig grants Scopes to callers presenting the API key with ID
+// whose secret matches the bcrypt Hash. Keys without ExpiresAt never expire.
+type APIKeyConfig struct {
+	ID        string    `json:"id"`
+	Hash      string    `json:"hash"`
+	Scopes    []string  `json:"scopes"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type CAConfig struct {
+	CertificateFilePath string `json:"certificate_file_path"`
+	PrivateKeyFilePath  string `json:"private_key_file_path"`
 }
 
 type DBConnectionConfig struct {
@@ -34,6 +109,38 @@ type DBConfig struct {
 	ConnectionOptions DBConnectionConfig `json:"connection_options"`
 }
 
+// DeletedRetention returns how long deleted names are kept before being purged
+func (c ServerConfig) DeletedRetention() time.Duration {
+	if c.DeletedRetentionHours == 0 {
+		return DefaultDeletedRetention
+	}
+	return time.Duration(c.DeletedRetentionHours) * time.Hour
+}
+
+// JwtKeysRefresh returns how often JWT verification keys are reloaded
+func (c ServerConfig) JwtKeysRefresh() time.Duration {
+	if c.JwtKeysRefreshSeconds == 0 {
+		return DefaultJwtKeysRefresh
+	}
+	return time.Duration(c.JwtKeysRefreshSeconds) * time.Second
+}
+
+// ShutdownTimeout returns how long in-flight requests are drained on shutdown
+func (c ServerConfig) ShutdownTimeout() time.Duration {
+	if c.ShutdownTimeoutSeconds == 0 {
+		return DefaultShutdownTimeout
+	}
+	return time.Duration(c.ShutdownTimeoutSeconds) * time.Second
+}
+
+// Attempts returns how often a notification is sent before it is dead-lettered
+func (c WebhookConfig) Attempts() int {
+	if c.MaxAttempts == 0 {
+		return DefaultWebhookMaxAttempts
+	}
+	return c.MaxAttempts
+}
+
 func ParseConfig(filename string) (ServerConfig, error) {
 	config := ServerConfig{}
 
@@ -55,6 +162,78 @@ func ParseConfig(filename string) (ServerConfig, error) {
 		return config, errors.Error("CA Certificate file path and key file path should be defined")
 	}
 
+	for name, caConfig := range config.CertificateAuthorities {
+		if caConfig.CertificateFilePath == "" || caConfig.PrivateKeyFilePath == "" {
+			return config, errors.Errorf("Certificate file path and key file path of CA '%s' should be defined", name)
+		}
+	}
+
+	jwtKeySources := 0
+	for _, source := range []string{config.JwtVerificationKeyPath, config.JwtVerificationKeysDir, config.JwtTokenKeysURL} {
+		if source != "" {
+			jwtKeySources++
+		}
+	}
+	if jwtKeySources > 1 {
+		return config, errors.Error("Only one of JWT verification key path, keys directory and token keys URL should be defined")
+	}
+
+	if config.JwtKeysRefreshSeconds < 0 {
+		return config, errors.Error("JWT keys refresh seconds should not be negative")
+	}
+
+	if config.JwtClockSkewSeconds < 0 {
+		return config, errors.Error("JWT clock skew seconds should not be negative")
+	}
+
+	if config.ShutdownTimeoutSeconds < 0 {
+		return config, errors.Error("Shutdown timeout seconds should not be negative")
+	}
+
+	if config.DeletedRetentionHours < 0 {
+		return config, errors.Error("Deleted retention hours should not be negative")
+	}
+
+	for i, webhook := range config.Webhooks {
+		if webhook.URL == "" || webhook.Secret == "" {
+			return config, errors.Errorf("URL and secret of webhook %d should be defined", i)
+		}
+		if webhook.MaxAttempts < 0 {
+			return config, errors.Errorf("Max attempts of webhook %d should not be negative", i)
+		}
+	}
+
+	if len(config.ClientCertificates.Identities) > 0 && config.ClientCertificates.CACertificatePath == "" {
+		return config, errors.Error("CA certificate path of client certificates should be defined")
+	}
+
+	for i, identity := range config.ClientCertificates.Identities {
+		if (identity.CommonName == "") == (identity.DNSName == "") {
+			return config, errors.Errorf("Exactly one of common name and DNS name of client identity %d should be defined", i)
+		}
+		if len(identity.Scopes) == 0 {
+			return config, errors.Errorf("Scopes of client identity %d should be defined", i)
+		}
+	}
+
+	apiKeyIDs := map[string]bool{}
+	for i, apiKey := range config.APIKeys {
+		if apiKey.ID == "" || strings.Contains(apiKey.ID, ".") {
+			return config, errors.Errorf("ID of API key %d should be defined and not contain '.'", i)
+		}
+		if apiKeyIDs[apiKey.ID] {
+			return config, errors.Errorf("ID of API key %d is not unique", i)
+		}
+		apiKeyIDs[apiKey.ID] = true
+
+		if _, err := bcrypt.Cost([]byte(apiKey.Hash)); err != nil {
+			return config, errors.WrapErrorf(err, "Hash of API key '%s' should be a bcrypt hash", apiKey.ID)
+		}
+		if len(apiKey.Scopes) == 0 {
+			return config, errors.Errorf("Scopes of API key '%s' should be defined", apiKey.ID)
+		}
+	}
+
 	if (&config.Database != nil) && (&config.Database.Adapter != nil) {
 		config.Database.Adapter = strings.ToLower(config.Database.Adapter)
 	}
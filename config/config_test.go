@@ -5,6 +5,7 @@ import (
 
 	"io/ioutil"
 	"os"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -83,6 +84,417 @@ var _ = Describe("ParseConfig", func() {
 				Expect(serverConfig.Database.ConnectionOptions.MaxOpenConnections).To(Equal(12))
 				Expect(serverConfig.Database.ConnectionOptions.MaxIdleConnections).To(Equal(25))
 			})
+
+			It("should return named certificate authorities", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "certificate_authorities": {
+      "nats": {
+         "certificate_file_path": "/path/to/nats/cert",
+         "private_key_file_path": "/path/to/nats/key"
+      },
+      "uaa": {
+         "certificate_file_path": "/path/to/uaa/cert",
+         "private_key_file_path": "/path/to/uaa/key"
+      }
+   }
+}
+`)
+				serverConfig, err := ParseConfig(configFile.Name())
+				Expect(err).To(BeNil())
+
+				Expect(serverConfig.CertificateAuthorities).To(Equal(map[string]CAConfig{
+					"nats": {CertificateFilePath: "/path/to/nats/cert", PrivateKeyFilePath: "/path/to/nats/key"},
+					"uaa":  {CertificateFilePath: "/path/to/uaa/cert", PrivateKeyFilePath: "/path/to/uaa/key"},
+				}))
+			})
+		})
+
+		Context("has shutdown timeout", func() {
+			It("should return configured timeout", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "shutdown_timeout_seconds": 5
+}
+`)
+				serverConfig, err := ParseConfig(configFile.Name())
+				Expect(err).To(BeNil())
+				Expect(serverConfig.ShutdownTimeout()).To(Equal(5 * time.Second))
+			})
+
+			It("should default timeout when not configured", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key"
+}
+`)
+				serverConfig, err := ParseConfig(configFile.Name())
+				Expect(err).To(BeNil())
+				Expect(serverConfig.ShutdownTimeout()).To(Equal(DefaultShutdownTimeout))
+			})
+
+			It("should error when timeout is negative", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "shutdown_timeout_seconds": -1
+}
+`)
+				_, err := ParseConfig(configFile.Name())
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal("Shutdown timeout seconds should not be negative"))
+			})
+		})
+
+		Context("has deleted retention", func() {
+			It("should return configured retention", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "deleted_retention_hours": 48
+}
+`)
+				serverConfig, err := ParseConfig(configFile.Name())
+				Expect(err).To(BeNil())
+				Expect(serverConfig.DeletedRetention()).To(Equal(48 * time.Hour))
+			})
+
+			It("should default retention when not configured", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key"
+}
+`)
+				serverConfig, err := ParseConfig(configFile.Name())
+				Expect(err).To(BeNil())
+				Expect(serverConfig.DeletedRetention()).To(Equal(DefaultDeletedRetention))
+			})
+
+			It("should error when retention is negative", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "deleted_retention_hours": -1
+}
+`)
+				_, err := ParseConfig(configFile.Name())
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal("Deleted retention hours should not be negative"))
+			})
+		})
+
+		Context("has JWT verification keys", func() {
+			It("should return configured token keys URL and refresh interval", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "jwt_token_keys_url": "https://uaa.example.com/token_keys",
+   "jwt_token_keys_ca_certificate_path": "/path/to/uaa/ca",
+   "jwt_keys_refresh_seconds": 60
+}
+`)
+				serverConfig, err := ParseConfig(configFile.Name())
+				Expect(err).To(BeNil())
+				Expect(serverConfig.JwtTokenKeysURL).To(Equal("https://uaa.example.com/token_keys"))
+				Expect(serverConfig.JwtTokenKeysCACertificatePath).To(Equal("/path/to/uaa/ca"))
+				Expect(serverConfig.JwtKeysRefresh()).To(Equal(time.Minute))
+			})
+
+			It("should default refresh interval when not configured", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "jwt_verification_keys_dir": "/path/to/keys"
+}
+`)
+				serverConfig, err := ParseConfig(configFile.Name())
+				Expect(err).To(BeNil())
+				Expect(serverConfig.JwtVerificationKeysDir).To(Equal("/path/to/keys"))
+				Expect(serverConfig.JwtKeysRefresh()).To(Equal(DefaultJwtKeysRefresh))
+			})
+
+			It("should error when more than one source of keys is defined", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "jwt_verification_key_path": "/path/to/key.pem",
+   "jwt_verification_keys_dir": "/path/to/keys"
+}
+`)
+				_, err := ParseConfig(configFile.Name())
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal("Only one of JWT verification key path, keys directory and token keys URL should be defined"))
+			})
+
+			It("should return configured claim requirements", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "jwt_issuer": "https://uaa.example.com/oauth/token",
+   "jwt_audience": "config_server",
+   "jwt_clock_skew_seconds": 30
+}
+`)
+				serverConfig, err := ParseConfig(configFile.Name())
+				Expect(err).To(BeNil())
+				Expect(serverConfig.JwtIssuer).To(Equal("https://uaa.example.com/oauth/token"))
+				Expect(serverConfig.JwtAudience).To(Equal("config_server"))
+				Expect(serverConfig.JwtClockSkewSeconds).To(Equal(30))
+			})
+
+			It("should error when clock skew is negative", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "jwt_clock_skew_seconds": -1
+}
+`)
+				_, err := ParseConfig(configFile.Name())
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal("JWT clock skew seconds should not be negative"))
+			})
+
+			It("should error when refresh interval is negative", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "jwt_keys_refresh_seconds": -1
+}
+`)
+				_, err := ParseConfig(configFile.Name())
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal("JWT keys refresh seconds should not be negative"))
+			})
+		})
+
+		Context("has client certificates", func() {
+			It("should return configured client certificates", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "client_certificates": {
+      "ca_certificate_path": "/path/to/client/ca",
+      "identities": [
+         {"common_name": "worker", "scopes": ["config_server.read"]},
+         {"dns_name": "deployer.bosh", "name": "deployer", "scopes": ["config_server.admin"]}
+      ]
+   }
+}
+`)
+				serverConfig, err := ParseConfig(configFile.Name())
+				Expect(err).To(BeNil())
+				Expect(serverConfig.ClientCertificates).To(Equal(ClientCertsConfig{
+					CACertificatePath: "/path/to/client/ca",
+					Identities: []ClientIdentityConfig{
+						{CommonName: "worker", Scopes: []string{"config_server.read"}},
+						{DNSName: "deployer.bosh", Name: "deployer", Scopes: []string{"config_server.admin"}},
+					},
+				}))
+			})
+
+			It("should error when CA certificate path is missing", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "client_certificates": {"identities": [{"common_name": "worker", "scopes": ["config_server.read"]}]}
+}
+`)
+				_, err := ParseConfig(configFile.Name())
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal("CA certificate path of client certificates should be defined"))
+			})
+
+			It("should error when identity is invalid", func() {
+				for identity, message := range map[string]string{
+					`{"scopes": ["config_server.read"]}`:                                      "Exactly one of common name and DNS name of client identity 0 should be defined",
+					`{"common_name": "a", "dns_name": "b", "scopes": ["config_server.read"]}`: "Exactly one of common name and DNS name of client identity 0 should be defined",
+					`{"common_name": "worker"}`:                                               "Scopes of client identity 0 should be defined",
+				} {
+					Expect(configFile.Truncate(0)).To(Succeed())
+					configFile.WriteAt([]byte(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "client_certificates": {"ca_certificate_path": "/path/to/client/ca", "identities": [`+identity+`]}
+}
+`), 0)
+					_, err := ParseConfig(configFile.Name())
+					Expect(err).ToNot(BeNil())
+					Expect(err.Error()).To(Equal(message))
+				}
+			})
+		})
+
+		Context("has API keys", func() {
+			hash := "$2a$04$eSSm4e0rFsAk34PZ/JmNjO5szRkpSW0bpkDjTWLCdYUPB/VWNZeCi"
+
+			It("should return configured API keys", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "api_keys": [
+      {"id": "local-dev", "hash": "` + hash + `", "scopes": ["config_server.admin"]},
+      {"id": "smoke-tests", "hash": "` + hash + `", "scopes": ["config_server.read"], "expires_at": "2030-01-02T03:04:05Z"}
+   ]
+}
+`)
+				serverConfig, err := ParseConfig(configFile.Name())
+				Expect(err).To(BeNil())
+				Expect(serverConfig.APIKeys).To(Equal([]APIKeyConfig{
+					{ID: "local-dev", Hash: hash, Scopes: []string{"config_server.admin"}},
+					{ID: "smoke-tests", Hash: hash, Scopes: []string{"config_server.read"}, ExpiresAt: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)},
+				}))
+			})
+
+			It("should error when API key is invalid", func() {
+				for apiKeys, message := range map[string]string{
+					`{"hash": "` + hash + `", "scopes": ["config_server.read"]}`:                                                 "ID of API key 0 should be defined and not contain '.'",
+					`{"id": "a.b", "hash": "` + hash + `", "scopes": ["config_server.read"]}`:                                    "ID of API key 0 should be defined and not contain '.'",
+					`{"id": "a", "hash": "` + hash + `", "scopes": ["s"]}, {"id": "a", "hash": "` + hash + `", "scopes": ["s"]}`: "ID of API key 1 is not unique",
+					`{"id": "a", "hash": "s3cr3t", "scopes": ["config_server.read"]}`:                                            "Hash of API key 'a' should be a bcrypt hash",
+					`{"id": "a", "hash": "` + hash + `"}`:                                                                        "Scopes of API key 'a' should be defined",
+				} {
+					Expect(configFile.Truncate(0)).To(Succeed())
+					configFile.WriteAt([]byte(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "api_keys": [`+apiKeys+`]
+}
+`), 0)
+					_, err := ParseConfig(configFile.Name())
+					Expect(err).ToNot(BeNil())
+					Expect(err.Error()).To(HavePrefix(message))
+				}
+			})
+		})
+
+		Context("has webhooks", func() {
+			It("should return configured webhooks", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "webhooks": [
+      {"url": "https://example.com/hook", "prefix": "/deployments/cf/", "secret": "shh", "max_attempts": 2},
+      {"url": "https://example.com/all", "secret": "shh"}
+   ],
+   "webhook_dead_letter_path": "/path/to/dead-letters"
+}
+`)
+				serverConfig, err := ParseConfig(configFile.Name())
+				Expect(err).To(BeNil())
+				Expect(serverConfig.Webhooks).To(Equal([]WebhookConfig{
+					{URL: "https://example.com/hook", Prefix: "/deployments/cf/", Secret: "shh", MaxAttempts: 2},
+					{URL: "https://example.com/all", Secret: "shh"},
+				}))
+				Expect(serverConfig.Webhooks[0].Attempts()).To(Equal(2))
+				Expect(serverConfig.Webhooks[1].Attempts()).To(Equal(DefaultWebhookMaxAttempts))
+				Expect(serverConfig.WebhookDeadLetterPath).To(Equal("/path/to/dead-letters"))
+			})
+
+			It("should error when a webhook is missing its secret", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "webhooks": [{"url": "https://example.com/hook"}]
+}
+`)
+				_, err := ParseConfig(configFile.Name())
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal("URL and secret of webhook 0 should be defined"))
+			})
+
+			It("should error when max attempts is negative", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "webhooks": [{"url": "https://example.com/hook", "secret": "shh", "max_attempts": -1}]
+}
+`)
+				_, err := ParseConfig(configFile.Name())
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal("Max attempts of webhook 0 should not be negative"))
+			})
+		})
+
+		Context("has audit log path", func() {
+			It("should return configured path", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "audit_log_path": "/path/to/audit.jsonl"
+}
+`)
+				serverConfig, err := ParseConfig(configFile.Name())
+				Expect(err).To(BeNil())
+				Expect(serverConfig.AuditLogPath).To(Equal("/path/to/audit.jsonl"))
+			})
 		})
 
 		Context("has missing keys", func() {
@@ -186,6 +598,25 @@ var _ = Describe("ParseConfig", func() {
 				Expect(err).ToNot(BeNil())
 				Expect(err.Error()).To(Equal("CA Certificate file path and key file path should be defined"))
 			})
+
+			It("should error when a named certificate authority is missing a file path", func() {
+				configFile.WriteString(`
+{
+   "certificate_file_path":"/path/to/cert",
+   "private_key_file_path":"/path/to/key",
+   "ca_certificate_file_path" : "/path/to/ca/cert",
+   "ca_private_key_file_path": "/path/to/ca/private/key",
+   "certificate_authorities": {
+      "nats": {
+         "certificate_file_path": "/path/to/nats/cert"
+      }
+   }
+}
+`)
+				_, err := ParseConfig(configFile.Name())
+				Expect(err).ToNot(BeNil())
+				Expect(err.Error()).To(Equal("Certificate file path and key file path of CA 'nats' should be defined"))
+			})
 		})
 	})
 })
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// Policy allows the callers it applies to the given operations on names
+// matching any of Names. A policy applies to callers matching every one of
+// Client, User and Group that is set.
+type Policy struct {
+	Client     string   `json:"client"`
+	User       string   `json:"user"`
+	Group      string   `json:"group"`
+	Names      []string `json:"names"`
+	Operations []string `json:"operations"`
+}
+
+type policyFile struct {
+	Policies []Policy `json:"policies"`
+}
+
+func ParsePolicies(filename string) ([]Policy, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to read policy file")
+	}
+
+	var file policyFile
+	err = json.Unmarshal(data, &file)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to parse policy file")
+	}
+
+	for i, policy := range file.Policies {
+		if policy.Client == "" && policy.User == "" && policy.Group == "" {
+			return nil, errors.Errorf("Policy %d should define a client, user or group", i)
+		}
+
+		if len(policy.Names) == 0 || len(policy.Operations) == 0 {
+			return nil, errors.Errorf("Names and operations of policy %d should be defined", i)
+		}
+	}
+
+	return file.Policies, nil
+}
@@ -0,0 +1,72 @@
+package config_test
+
+import (
+	. "github.com/cloudfoundry/config-server/config"
+
+	"io/ioutil"
+	"os"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParsePolicies", func() {
+
+	Context("Policy file does not exist", func() {
+		It("should return an error", func() {
+			_, err := ParsePolicies("non-existent-file.json")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Failed to read policy file"))
+		})
+	})
+
+	Describe("Policy file exists", func() {
+
+		var policyFile *os.File
+
+		BeforeEach(func() {
+			policyFile, _ = ioutil.TempFile(os.TempDir(), "server-policies-")
+		})
+
+		AfterEach(func() {
+			os.Remove(policyFile.Name())
+		})
+
+		It("should return an error when content is invalid", func() {
+			policyFile.WriteString("garbage")
+			_, err := ParsePolicies(policyFile.Name())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Failed to parse policy file"))
+		})
+
+		It("should return policies", func() {
+			policyFile.WriteString(`
+{
+   "policies":[
+      {"client":"director", "names":["/cf/**"], "operations":["read", "write"]},
+      {"user":"admin", "group":"operators", "names":["**"], "operations":["read"]}
+   ]
+}`)
+			policies, err := ParsePolicies(policyFile.Name())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(policies).To(Equal([]Policy{
+				{Client: "director", Names: []string{"/cf/**"}, Operations: []string{"read", "write"}},
+				{User: "admin", Group: "operators", Names: []string{"**"}, Operations: []string{"read"}},
+			}))
+		})
+
+		It("should return an error when a policy applies to everyone", func() {
+			policyFile.WriteString(`{"policies":[{"names":["**"], "operations":["read"]}]}`)
+			_, err := ParsePolicies(policyFile.Name())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Policy 0 should define a client, user or group"))
+		})
+
+		It("should return an error when names or operations are missing", func() {
+			policyFile.WriteString(`{"policies":[{"client":"director", "names":["**"]}]}`)
+			_, err := ParsePolicies(policyFile.Name())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Names and operations of policy 0 should be defined"))
+		})
+	})
+})
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cloudfoundry/config-server/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type apiKeyAuthenticator struct {
+	keys map[string]config.APIKeyConfig
+}
+
+// NewAPIKeyAuthenticator returns an Authenticator checking API keys sent as
+// "Authorization: ApiKey <id>.<secret>" against the bcrypt hash of the
+// configured key with that ID. API keys are also the client ID of callers.
+func NewAPIKeyAuthenticator(keys []config.APIKeyConfig) Authenticator {
+	keysByID := map[string]config.APIKeyConfig{}
+	for _, key := range keys {
+		keysByID[key.ID] = key
+	}
+
+	return apiKeyAuthenticator{keys: keysByID}
+}
+
+func (a apiKeyAuthenticator) Handles(req *http.Request) bool {
+	_, ok := authorizationCredentials(req, "apikey")
+	return ok
+}
+
+func (a apiKeyAuthenticator) Authenticate(req *http.Request) (Identity, error) {
+	credentials, ok := authorizationCredentials(req, "apikey")
+	if !ok {
+		return Identity{}, errors.Error("Missing API key")
+	}
+
+	parts := strings.SplitN(credentials, ".", 2)
+	if len(parts) != 2 {
+		return Identity{}, errors.Error("Invalid API key format")
+	}
+
+	id, secret := parts[0], parts[1]
+
+	key, found := a.keys[id]
+	if !found {
+		return Identity{}, errors.Errorf("Unknown API key '%s'", id)
+	}
+
+	if !key.ExpiresAt.IsZero() && !time.Now().Before(key.ExpiresAt) {
+		return Identity{}, errors.Errorf("API key '%s' expired", id)
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(key.Hash), []byte(secret))
+	if err != nil {
+		return Identity{}, errors.WrapErrorf(err, "Validating API key '%s'", id)
+	}
+
+	return Identity{
+		Name:          id,
+		ClientID:      id,
+		Scopes:        append([]string{}, key.Scopes...),
+		Authenticator: AuthenticatorAPIKey,
+	}, nil
+}
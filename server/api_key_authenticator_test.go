@@ -0,0 +1,78 @@
+package server_test
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cloudfoundry/config-server/config"
+	. "github.com/cloudfoundry/config-server/server"
+	"golang.org/x/crypto/bcrypt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func apiKeyHash(secret string) string {
+	hash, err := bcrypt.GenerateFromPassword([]byte(secret), bcrypt.MinCost)
+	Expect(err).ToNot(HaveOccurred())
+	return string(hash)
+}
+
+func requestWithAuthorization(authorization string) *http.Request {
+	req, _ := http.NewRequest("GET", "/v1/data?name=/cf/password", nil)
+	req.Header.Set("Authorization", authorization)
+	return req
+}
+
+var _ = Describe("APIKeyAuthenticator", func() {
+	var authenticator Authenticator
+
+	BeforeEach(func() {
+		authenticator = NewAPIKeyAuthenticator([]config.APIKeyConfig{
+			{ID: "local-dev", Hash: apiKeyHash("s3cr3t"), Scopes: []string{"config_server.admin"}},
+			{ID: "smoke-tests", Hash: apiKeyHash("s3cr3t"), Scopes: []string{"config_server.read"}, ExpiresAt: time.Now().Add(time.Hour)},
+			{ID: "old", Hash: apiKeyHash("s3cr3t"), Scopes: []string{"config_server.read"}, ExpiresAt: time.Now().Add(-time.Hour)},
+		})
+	})
+
+	It("handles requests with ApiKey authorization only", func() {
+		Expect(authenticator.Handles(requestWithAuthorization("ApiKey local-dev.s3cr3t"))).To(BeTrue())
+		Expect(authenticator.Handles(requestWithAuthorization("apikey local-dev.s3cr3t"))).To(BeTrue())
+		Expect(authenticator.Handles(requestWithAuthorization("bearer some-token"))).To(BeFalse())
+		Expect(authenticator.Handles(requestWithAuthorization(""))).To(BeFalse())
+	})
+
+	It("returns the identity of valid API keys", func() {
+		identity, err := authenticator.Authenticate(requestWithAuthorization("ApiKey local-dev.s3cr3t"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(identity).To(Equal(Identity{Name: "local-dev", ClientID: "local-dev", Scopes: []string{"config_server.admin"}, Authenticator: AuthenticatorAPIKey}))
+
+		identity, err = authenticator.Authenticate(requestWithAuthorization("ApiKey smoke-tests.s3cr3t"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(identity.Scopes).To(Equal([]string{"config_server.read"}))
+	})
+
+	It("returns an error for wrong secrets", func() {
+		_, err := authenticator.Authenticate(requestWithAuthorization("ApiKey local-dev.wrong"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(HavePrefix("Validating API key 'local-dev'"))
+	})
+
+	It("returns an error for unknown API keys", func() {
+		_, err := authenticator.Authenticate(requestWithAuthorization("ApiKey stranger.s3cr3t"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Unknown API key 'stranger'"))
+	})
+
+	It("returns an error for expired API keys", func() {
+		_, err := authenticator.Authenticate(requestWithAuthorization("ApiKey old.s3cr3t"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("API key 'old' expired"))
+	})
+
+	It("returns an error for API keys without secret", func() {
+		_, err := authenticator.Authenticate(requestWithAuthorization("ApiKey local-dev"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Invalid API key format"))
+	})
+})
@@ -0,0 +1,188 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+const (
+	auditLogTag = "AuditHandler"
+
+	// auditResponseLimit is how much of a response is kept to find the ID of
+	// the version it returns
+	auditResponseLimit = 64 * 1024
+)
+
+type auditHandler struct {
+	auditLog    AuditLog
+	nextHandler http.Handler
+	logger      boshlog.Logger
+}
+
+// NewAuditHandler returns a handler recording every request it serves to
+// auditLog. It must be wrapped by the authentication handler so that the
+// actor is known.
+func NewAuditHandler(auditLog AuditLog, nextHandler http.Handler, logger boshlog.Logger) http.Handler {
+	return auditHandler{
+		auditLog:    auditLog,
+		nextHandler: nextHandler,
+		logger:      logger,
+	}
+}
+
+func (handler auditHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	entry := AuditEntry{
+		Time:      time.Now().UTC().Format(time.RFC3339),
+		Actor:     IdentityFromRequest(req).Actor(),
+		Operation: requestOperation(req),
+		Path:      req.URL.Query().Get("path"),
+		SourceIP:  sourceIP(req),
+	}
+
+	if name := req.URL.Query().Get("name"); name != "" {
+		entry.Names = []string{name}
+	} else {
+		entry.Names = readNamesFromBody(req)
+	}
+
+	auditedNames := &[]string{}
+	req = req.WithContext(context.WithValue(req.Context(), auditNamesContextKey{}, auditedNames))
+
+	recorder := &auditResponseWriter{ResponseWriter: resWriter, status: http.StatusOK}
+	handler.nextHandler.ServeHTTP(recorder, req)
+
+	if len(entry.Names) == 0 {
+		entry.Names = *auditedNames
+	}
+	entry.Status = recorder.status
+	entry.ID = auditVersionID(req, recorder)
+
+	if err := handler.auditLog.Record(entry); err != nil {
+		handler.logger.Error(auditLogTag, "Failed to record %s by '%s': %s", entry.Operation, entry.Actor, err.Error())
+	}
+}
+
+type auditNamesContextKey struct{}
+
+// recordAuditNames records the names a handler found req to refer to, for
+// requests whose names cannot be read from the query or a data request body,
+// such as the placeholders of a document to interpolate. It does nothing for
+// requests that are not audited.
+func recordAuditNames(req *http.Request, names []string) {
+	if auditedNames, ok := req.Context().Value(auditNamesContextKey{}).(*[]string); ok {
+		*auditedNames = append([]string(nil), names...)
+	}
+}
+
+// readNamesFromBody returns the names a JSON request body refers to, leaving
+// the body to be read again by the next handler
+func readNamesFromBody(req *http.Request) []string {
+	if req.Body == nil || !strings.HasPrefix(req.URL.Path, "/v1/data") || !strings.EqualFold(req.Header.Get("Content-Type"), "application/json") {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return nil
+	}
+
+	// Fields are decoded one by one so that a malformed field does not hide
+	// the names in the others
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil {
+		return nil
+	}
+
+	var names []string
+
+	var name string
+	if json.Unmarshal(fields["name"], &name) == nil && name != "" {
+		names = append(names, name)
+	}
+
+	var variables []map[string]json.RawMessage
+	if json.Unmarshal(fields["variables"], &variables) != nil {
+		return names
+	}
+	for _, variable := range variables {
+		var variableName string
+		if json.Unmarshal(variable["name"], &variableName) == nil {
+			names = append(names, variableName)
+		}
+	}
+	return names
+}
+
+// auditVersionID returns the version a request addressed by ID, or else the
+// version returned by a successful response for a single name
+func auditVersionID(req *http.Request, recorder *auditResponseWriter) string {
+	if strings.HasPrefix(req.URL.Path, "/v1/data/") {
+		if id, found := extractRestoreIDFromURLPath(req.URL.Path); found {
+			return id
+		}
+		if id, err := extractIDFromURLPath(req.URL.Path); err == nil {
+			return id
+		}
+	}
+
+	if recorder.status < 200 || recorder.status > 299 || recorder.truncated {
+		return ""
+	}
+
+	var response struct {
+		ID string `json:"id"`
+	}
+	if json.Unmarshal(recorder.body.Bytes(), &response) != nil {
+		return ""
+	}
+	return response.ID
+}
+
+func sourceIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// auditResponseWriter records the status and the start of the response
+type auditResponseWriter struct {
+	http.ResponseWriter
+	status    int
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *auditResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *auditResponseWriter) Write(data []byte) (int, error) {
+	if remaining := auditResponseLimit - w.body.Len(); len(data) > remaining {
+		w.body.Write(data[:remaining])
+		w.truncated = true
+	} else {
+		w.body.Write(data)
+	}
+
+	return w.ResponseWriter.Write(data)
+}
+
+// Flush lets the watch stream through
+func (w *auditResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
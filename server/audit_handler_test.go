@@ -0,0 +1,126 @@
+package server_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	. "github.com/cloudfoundry/config-server/server"
+	. "github.com/cloudfoundry/config-server/server/serverfakes"
+	"github.com/cloudfoundry/config-server/store"
+	"github.com/cloudfoundry/config-server/types/typesfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AuditHandler", func() {
+	var (
+		fakeAuditLog *FakeAuditLog
+		memoryStore  store.MemoryStore
+		auditHandler http.Handler
+	)
+
+	serve := func(method, url, body string) (*httptest.ResponseRecorder, AuditEntry) {
+		req, _ := generateHTTPRequest(method, url, strings.NewReader(body))
+		req.RemoteAddr = "10.0.0.1:51234"
+		req = WithIdentity(req, Identity{Name: "admin", ClientID: "admin", Authenticator: AuthenticatorToken})
+
+		recorder := httptest.NewRecorder()
+		auditHandler.ServeHTTP(recorder, req)
+
+		Expect(fakeAuditLog.RecordCallCount()).To(Equal(1))
+		return recorder, fakeAuditLog.RecordArgsForCall(0)
+	}
+
+	BeforeEach(func() {
+		fakeAuditLog = &FakeAuditLog{}
+		memoryStore = store.NewMemoryStore()
+		memoryStore.Put("/cf/password", `{"value":"secret"}`, store.Metadata{})
+
+		requestHandler, _ := NewRequestHandler(memoryStore, &typesfakes.FakeValueGeneratorFactory{}, allowingAuthorizer())
+		auditHandler = NewAuditHandler(fakeAuditLog, requestHandler, boshlog.NewLogger(boshlog.LevelNone))
+	})
+
+	It("records reads by name", func() {
+		recorder, entry := serve("GET", "/v1/data?name=/cf/password&current=true", "")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(entry.Time).ToNot(BeEmpty())
+		entry.Time = ""
+		Expect(entry).To(Equal(AuditEntry{
+			Actor:     "token:client:admin",
+			Operation: "read",
+			Names:     []string{"/cf/password"},
+			Status:    http.StatusOK,
+			SourceIP:  "10.0.0.1",
+		}))
+	})
+
+	It("records reads by id", func() {
+		_, entry := serve("GET", "/v1/data/0", "")
+
+		Expect(entry.Operation).To(Equal("read"))
+		Expect(entry.ID).To(Equal("0"))
+	})
+
+	It("records writes with the name from the body and the new id", func() {
+		recorder, entry := serve("PUT", "/v1/data", `{"name":"/cf/password","value":"new-secret"}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(entry.Operation).To(Equal("write"))
+		Expect(entry.Names).To(Equal([]string{"/cf/password"}))
+		Expect(entry.ID).To(Equal("1"))
+		Expect(entry.Status).To(Equal(http.StatusOK))
+	})
+
+	It("records the names of bodies with malformed fields", func() {
+		recorder, entry := serve("POST", "/v1/data", `{"name":"/cf/other","type":"password","variables":5}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+		Expect(entry.Names).To(Equal([]string{"/cf/other"}))
+	})
+
+	It("records failed requests", func() {
+		_, entry := serve("DELETE", "/v1/data?name=/cf/missing", "")
+
+		Expect(entry.Operation).To(Equal("delete"))
+		Expect(entry.Names).To(Equal([]string{"/cf/missing"}))
+		Expect(entry.Status).To(Equal(http.StatusNotFound))
+	})
+
+	It("records listings by path", func() {
+		_, entry := serve("GET", "/v1/data?path=/cf/", "")
+
+		Expect(entry.Path).To(Equal("/cf/"))
+		Expect(entry.Names).To(BeEmpty())
+	})
+
+	It("records the names referenced by documents to interpolate", func() {
+		interpolationHandler, _ := NewInterpolationHandler(memoryStore, allowingAuthorizer())
+		auditHandler = NewAuditHandler(fakeAuditLog, interpolationHandler, boshlog.NewLogger(boshlog.LevelNone))
+
+		recorder, entry := serve("POST", "/v1/interpolate", `{"password":"((/cf/password))","other":"((/cf/other))"}`)
+
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(entry.Operation).To(Equal("interpolate"))
+		Expect(entry.Names).To(ConsistOf("/cf/password", "/cf/other"))
+	})
+
+	It("records restores with the restored id", func() {
+		_, entry := serve("POST", "/v1/data/0/restore", "")
+
+		Expect(entry.Operation).To(Equal("restore"))
+		Expect(entry.ID).To(Equal("0"))
+	})
+
+	It("does not fail the request when recording fails", func() {
+		fakeAuditLog.RecordReturns(errors.New("disk full"))
+
+		recorder, _ := serve("GET", "/v1/data/0", "")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+	})
+})
@@ -0,0 +1,53 @@
+package server
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// AuditEntry describes one authenticated request. It never contains values.
+type AuditEntry struct {
+	Time      string   `json:"time"`
+	Actor     string   `json:"actor"`
+	Operation string   `json:"operation"`
+	Names     []string `json:"names,omitempty"`
+	Path      string   `json:"path,omitempty"`
+	ID        string   `json:"id,omitempty"`
+	Status    int      `json:"status"`
+	SourceIP  string   `json:"source_ip"`
+}
+
+type AuditLog interface {
+	Record(entry AuditEntry) error
+}
+
+type fileAuditLog struct {
+	mutex *sync.Mutex
+	file  *os.File
+}
+
+// NewFileAuditLog returns an AuditLog appending entries to path as JSON lines
+func NewFileAuditLog(path string) (AuditLog, error) {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to open audit log")
+	}
+
+	return fileAuditLog{mutex: &sync.Mutex{}, file: file}, nil
+}
+
+func (l fileAuditLog) Record(entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	_, err = l.file.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,50 @@
+package server_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/cloudfoundry/config-server/server"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FileAuditLog", func() {
+	var tempDir string
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "audit-log")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	It("appends entries as JSON lines", func() {
+		path := filepath.Join(tempDir, "audit.jsonl")
+		Expect(ioutil.WriteFile(path, []byte("existing\n"), 0600)).To(Succeed())
+
+		auditLog, err := NewFileAuditLog(path)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(auditLog.Record(AuditEntry{Time: "2016-11-02T15:30:00Z", Actor: "admin", Operation: "read", Names: []string{"/cf/password"}, Status: 200, SourceIP: "10.0.0.1"})).To(Succeed())
+		Expect(auditLog.Record(AuditEntry{Time: "2016-11-02T15:31:00Z", Actor: "admin", Operation: "delete", ID: "3", Status: 204, SourceIP: "10.0.0.1"})).To(Succeed())
+
+		contents, err := ioutil.ReadFile(path)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(contents)).To(Equal(`existing
+{"time":"2016-11-02T15:30:00Z","actor":"admin","operation":"read","names":["/cf/password"],"status":200,"source_ip":"10.0.0.1"}
+{"time":"2016-11-02T15:31:00Z","actor":"admin","operation":"delete","id":"3","status":204,"source_ip":"10.0.0.1"}
+`))
+	})
+
+	It("returns an error when the file cannot be opened", func() {
+		_, err := NewFileAuditLog(filepath.Join(tempDir, "missing", "audit.jsonl"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Failed to open audit log"))
+	})
+})
@@ -2,55 +2,39 @@ package server
 
 import (
 	"net/http"
-	"strings"
 
 	"github.com/cloudfoundry/bosh-utils/errors"
 )
 
 type authenticationHandler struct {
-	tokenValidator TokenValidator
+	authenticators []Authenticator
 	nextHandler    http.Handler
 }
 
-func NewAuthenticationHandler(tokenValidator TokenValidator, nextHandler http.Handler) http.Handler {
+// NewAuthenticationHandler returns a handler authenticating requests with
+// the first of authenticators handling their credentials. Requests that no
+// authenticator handles are unauthorized.
+func NewAuthenticationHandler(authenticators []Authenticator, nextHandler http.Handler) http.Handler {
 	return authenticationHandler{
-		tokenValidator: tokenValidator,
+		authenticators: authenticators,
 		nextHandler:    nextHandler,
 	}
 }
 
 func (handler authenticationHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
-	if handler.authenticate(req) == nil {
-		handler.nextHandler.ServeHTTP(resWriter, req)
+	if identity, err := handler.authenticate(req); err == nil {
+		handler.nextHandler.ServeHTTP(resWriter, WithIdentity(req, identity))
 	} else {
 		http.Error(resWriter, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
 	}
 }
 
-func (handler authenticationHandler) authenticate(req *http.Request) error {
-	authHeader := req.Header.Get("Authorization")
-	if len(authHeader) == 0 {
-		return errors.Error("Missing Token")
+func (handler authenticationHandler) authenticate(req *http.Request) (Identity, error) {
+	for _, authenticator := range handler.authenticators {
+		if authenticator.Handles(req) {
+			return authenticator.Authenticate(req)
+		}
 	}
 
-	jwtToken, err := handler.checkTokenFormat(authHeader)
-	if err != nil {
-		return err
-	}
-
-	return handler.tokenValidator.Validate(jwtToken)
-}
-
-func (handler authenticationHandler) checkTokenFormat(token string) (string, error) {
-	tokenParts := strings.Split(token, " ")
-	if len(tokenParts) != 2 {
-		return "", errors.Error("Invalid token format")
-	}
-
-	tokenType, userToken := tokenParts[0], tokenParts[1]
-	if !strings.EqualFold(tokenType, "bearer") {
-		return "", errors.Error("Invalid token type: " + tokenType)
-	}
-
-	return userToken, nil
+	return Identity{}, errors.Error("Missing credentials")
 }
@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"strings"
 
+	"github.com/cloudfoundry/config-server/config"
 	. "github.com/cloudfoundry/config-server/server"
 	. "github.com/cloudfoundry/config-server/server/serverfakes"
 
@@ -21,11 +22,11 @@ var _ = Describe("AuthenticationHandler", func() {
 	BeforeEach(func() {
 		mockTokenValidator = &FakeTokenValidator{}
 		mockNextHandler = &FakeHandler{}
-		authHandler = NewAuthenticationHandler(mockTokenValidator, mockNextHandler)
+		authHandler = NewAuthenticationHandler([]Authenticator{NewTokenAuthenticator(mockTokenValidator)}, mockNextHandler)
 	})
 
 	It("should forward request to next handler if token is valid", func() {
-		mockTokenValidator.ValidateReturns(nil)
+		mockTokenValidator.ValidateReturns(Identity{Name: "admin"}, nil)
 
 		req, _ := http.NewRequest("PUT", "/v1/data/bla", strings.NewReader("{\"value\":\"blabla\"}"))
 		req.Header.Set("Authorization", "bearer fake-auth-header")
@@ -34,10 +35,26 @@ var _ = Describe("AuthenticationHandler", func() {
 		authHandler.ServeHTTP(recorder, req)
 
 		Expect(mockNextHandler.ServeHTTPCallCount()).To(Equal(1))
+		Expect(mockTokenValidator.ValidateArgsForCall(0)).To(Equal("fake-auth-header"))
 
 		capturedResWriter, capturedReq := mockNextHandler.ServeHTTPArgsForCall(0)
 		Expect(capturedResWriter).To(Equal(recorder))
-		Expect(capturedReq).To(Equal(req))
+		Expect(capturedReq.Method).To(Equal(req.Method))
+		Expect(capturedReq.URL).To(Equal(req.URL))
+		Expect(capturedReq.Header).To(Equal(req.Header))
+	})
+
+	It("should attach the identity of the token to the forwarded request", func() {
+		mockTokenValidator.ValidateReturns(Identity{Name: "admin"}, nil)
+
+		req, _ := http.NewRequest("PUT", "/v1/data/bla", strings.NewReader("{\"value\":\"blabla\"}"))
+		req.Header.Set("Authorization", "bearer fake-auth-header")
+
+		authHandler.ServeHTTP(httptest.NewRecorder(), req)
+
+		_, capturedReq := mockNextHandler.ServeHTTPArgsForCall(0)
+		Expect(IdentityFromRequest(capturedReq)).To(Equal(Identity{Name: "admin", Authenticator: AuthenticatorToken}))
+		Expect(IdentityFromRequest(req)).To(Equal(Identity{}))
 	})
 
 	It("should return 401 Unauthorized if token is missing from request header", func() {
@@ -64,4 +81,90 @@ var _ = Describe("AuthenticationHandler", func() {
 		Expect(recorder2.Code).To(Equal(http.StatusUnauthorized))
 	})
 
+	Context("with client certificates", func() {
+		BeforeEach(func() {
+			authHandler = NewAuthenticationHandler([]Authenticator{
+				NewCertificateAuthenticator([]config.ClientIdentityConfig{
+					{CommonName: "worker", Scopes: []string{"config_server.read"}},
+				}),
+				NewTokenAuthenticator(mockTokenValidator),
+			}, mockNextHandler)
+		})
+
+		It("should authenticate requests with a verified certificate by the certificate", func() {
+			req := requestWithClientCertificate("worker")
+			req.Header.Set("Authorization", "bearer fake-auth-header")
+
+			authHandler.ServeHTTP(httptest.NewRecorder(), req)
+
+			Expect(mockTokenValidator.ValidateCallCount()).To(Equal(0))
+			_, capturedReq := mockNextHandler.ServeHTTPArgsForCall(0)
+			Expect(IdentityFromRequest(capturedReq)).To(Equal(Identity{Name: "worker", ClientID: "worker", Scopes: []string{"config_server.read"}, Authenticator: AuthenticatorCertificate}))
+		})
+
+		It("should return 401 Unauthorized for certificates not mapped to an identity", func() {
+			recorder := httptest.NewRecorder()
+			authHandler.ServeHTTP(recorder, requestWithClientCertificate("stranger"))
+
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+			Expect(mockNextHandler.ServeHTTPCallCount()).To(Equal(0))
+		})
+
+		It("should authenticate requests without certificate by their token", func() {
+			mockTokenValidator.ValidateReturns(Identity{Name: "admin"}, nil)
+
+			req, _ := http.NewRequest("GET", "/v1/data?name=/cf/password", nil)
+			req.Header.Set("Authorization", "bearer fake-auth-header")
+
+			authHandler.ServeHTTP(httptest.NewRecorder(), req)
+
+			Expect(mockTokenValidator.ValidateCallCount()).To(Equal(1))
+			Expect(mockNextHandler.ServeHTTPCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("with API keys", func() {
+		BeforeEach(func() {
+			authHandler = NewAuthenticationHandler([]Authenticator{
+				NewTokenAuthenticator(mockTokenValidator),
+				NewAPIKeyAuthenticator([]config.APIKeyConfig{
+					{ID: "local-dev", Hash: apiKeyHash("s3cr3t"), Scopes: []string{"config_server.admin"}},
+				}),
+			}, mockNextHandler)
+		})
+
+		It("should authenticate requests with an API key by the API key", func() {
+			req, _ := http.NewRequest("GET", "/v1/data?name=/cf/password", nil)
+			req.Header.Set("Authorization", "ApiKey local-dev.s3cr3t")
+
+			authHandler.ServeHTTP(httptest.NewRecorder(), req)
+
+			Expect(mockTokenValidator.ValidateCallCount()).To(Equal(0))
+			_, capturedReq := mockNextHandler.ServeHTTPArgsForCall(0)
+			Expect(IdentityFromRequest(capturedReq)).To(Equal(Identity{Name: "local-dev", ClientID: "local-dev", Scopes: []string{"config_server.admin"}, Authenticator: AuthenticatorAPIKey}))
+		})
+
+		It("should return 401 Unauthorized for invalid API keys", func() {
+			req, _ := http.NewRequest("GET", "/v1/data?name=/cf/password", nil)
+			req.Header.Set("Authorization", "ApiKey local-dev.wrong")
+
+			recorder := httptest.NewRecorder()
+			authHandler.ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+			Expect(mockNextHandler.ServeHTTPCallCount()).To(Equal(0))
+		})
+
+		It("should authenticate requests with a bearer token by their token", func() {
+			mockTokenValidator.ValidateReturns(Identity{Name: "admin"}, nil)
+
+			req, _ := http.NewRequest("GET", "/v1/data?name=/cf/password", nil)
+			req.Header.Set("Authorization", "bearer fake-auth-header")
+
+			authHandler.ServeHTTP(httptest.NewRecorder(), req)
+
+			Expect(mockTokenValidator.ValidateCallCount()).To(Equal(1))
+			Expect(mockNextHandler.ServeHTTPCallCount()).To(Equal(1))
+		})
+	})
 })
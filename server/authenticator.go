@@ -0,0 +1,58 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// Authenticator returns the identity of requests made with the kind of
+// credentials it checks. Handles reports whether a request carries such
+// credentials, so that requests are authenticated by the first of a list of
+// authenticators handling them.
+type Authenticator interface {
+	Handles(req *http.Request) bool
+	Authenticate(req *http.Request) (Identity, error)
+}
+
+type tokenAuthenticator struct {
+	tokenValidator TokenValidator
+}
+
+// NewTokenAuthenticator returns an Authenticator validating bearer tokens
+// sent in the Authorization header with tokenValidator
+func NewTokenAuthenticator(tokenValidator TokenValidator) Authenticator {
+	return tokenAuthenticator{tokenValidator: tokenValidator}
+}
+
+func (a tokenAuthenticator) Handles(req *http.Request) bool {
+	_, ok := authorizationCredentials(req, "bearer")
+	return ok
+}
+
+func (a tokenAuthenticator) Authenticate(req *http.Request) (Identity, error) {
+	token, ok := authorizationCredentials(req, "bearer")
+	if !ok {
+		return Identity{}, errors.Error("Missing Token")
+	}
+
+	identity, err := a.tokenValidator.Validate(token)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	identity.Authenticator = AuthenticatorToken
+	return identity, nil
+}
+
+// authorizationCredentials returns the credentials of the Authorization
+// header of req if they are of the given type
+func authorizationCredentials(req *http.Request, credentialsType string) (string, bool) {
+	headerParts := strings.Split(req.Header.Get("Authorization"), " ")
+	if len(headerParts) != 2 || !strings.EqualFold(headerParts[0], credentialsType) {
+		return "", false
+	}
+
+	return headerParts[1], true
+}
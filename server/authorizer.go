@@ -0,0 +1,108 @@
+package server
+
+// Operations a caller can be allowed to perform
+const (
+	OperationRead     = "read"
+	OperationWrite    = "write"
+	OperationGenerate = "generate"
+	OperationDelete   = "delete"
+	OperationAdmin    = "admin"
+)
+
+// AdminScope grants every operation
+const AdminScope = "config_server.admin"
+
+// configServerScopes are the scopes of which a token needs at least one
+var configServerScopes = []string{
+	AdminScope,
+	ScopeFor(OperationRead),
+	ScopeFor(OperationWrite),
+	ScopeFor(OperationGenerate),
+	ScopeFor(OperationDelete),
+}
+
+// ScopeFor returns the scope granting operation
+func ScopeFor(operation string) string {
+	return "config_server." + operation
+}
+
+// AccessRequest describes what a request accesses: the operation, names it
+// addresses and paths under which it addresses every name
+type AccessRequest struct {
+	Operation string
+	Names     []string
+	Paths     []string
+}
+
+type Authorizer interface {
+	Authorize(identity Identity, access AccessRequest) (bool, error)
+}
+
+type allAuthorizers []Authorizer
+
+// NewAllAuthorizers returns an Authorizer allowing access only if every one
+// of authorizers allows it
+func NewAllAuthorizers(authorizers ...Authorizer) Authorizer {
+	return allAuthorizers(authorizers)
+}
+
+func (a allAuthorizers) Authorize(identity Identity, access AccessRequest) (bool, error) {
+	for _, authorizer := range a {
+		allowed, err := authorizer.Authorize(identity, access)
+		if err != nil || !allowed {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+type anyAuthorizers []Authorizer
+
+// NewAnyAuthorizers returns an Authorizer allowing access if every name and
+// path of a request is allowed by at least one of authorizers
+func NewAnyAuthorizers(authorizers ...Authorizer) Authorizer {
+	return anyAuthorizers(authorizers)
+}
+
+func (a anyAuthorizers) Authorize(identity Identity, access AccessRequest) (bool, error) {
+	var accesses []AccessRequest
+	for _, name := range access.Names {
+		accesses = append(accesses, AccessRequest{Operation: access.Operation, Names: []string{name}})
+	}
+	for _, path := range access.Paths {
+		accesses = append(accesses, AccessRequest{Operation: access.Operation, Paths: []string{path}})
+	}
+	if len(accesses) == 0 {
+		accesses = append(accesses, access)
+	}
+
+	for _, single := range accesses {
+		allowed, err := a.authorizeAny(identity, single)
+		if err != nil || !allowed {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func (a anyAuthorizers) authorizeAny(identity Identity, access AccessRequest) (bool, error) {
+	for _, authorizer := range a {
+		allowed, err := authorizer.Authorize(identity, access)
+		if err != nil || allowed {
+			return allowed, err
+		}
+	}
+	return false, nil
+}
+
+type scopeAuthorizer struct{}
+
+// NewScopeAuthorizer returns an Authorizer allowing operations granted by the
+// scopes of the caller's token
+func NewScopeAuthorizer() Authorizer {
+	return scopeAuthorizer{}
+}
+
+func (scopeAuthorizer) Authorize(identity Identity, access AccessRequest) (bool, error) {
+	return identity.HasScope(AdminScope) || identity.HasScope(ScopeFor(access.Operation)), nil
+}
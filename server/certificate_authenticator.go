@@ -0,0 +1,70 @@
+package server
+
+import (
+	"crypto/x509"
+	"net/http"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cloudfoundry/config-server/config"
+)
+
+type certificateAuthenticator struct {
+	identities []config.ClientIdentityConfig
+}
+
+// NewCertificateAuthenticator returns an Authenticator mapping
+// certificates that were verified during the TLS handshake to the first of
+// identities matching their subject common name or one of their DNS names.
+// Client certificate identities are also their client ID.
+func NewCertificateAuthenticator(identities []config.ClientIdentityConfig) Authenticator {
+	return certificateAuthenticator{identities: identities}
+}
+
+func (a certificateAuthenticator) Handles(req *http.Request) bool {
+	return verifiedClientCertificate(req) != nil
+}
+
+func (a certificateAuthenticator) Authenticate(req *http.Request) (Identity, error) {
+	certificate := verifiedClientCertificate(req)
+	if certificate == nil {
+		return Identity{}, errors.Error("Missing verified client certificate")
+	}
+
+	for _, identity := range a.identities {
+		name, matches := certificateMatches(certificate, identity)
+		if !matches {
+			continue
+		}
+
+		if identity.Name != "" {
+			name = identity.Name
+		}
+
+		return Identity{
+			Name:          name,
+			ClientID:      name,
+			Scopes:        append([]string{}, identity.Scopes...),
+			Authenticator: AuthenticatorCertificate,
+		}, nil
+	}
+
+	return Identity{}, errors.Errorf("Client certificate '%s' is not mapped to an identity", certificate.Subject.CommonName)
+}
+
+// verifiedClientCertificate returns the client certificate of a request if
+// it was verified against the configured client CA
+func verifiedClientCertificate(req *http.Request) *x509.Certificate {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	return req.TLS.VerifiedChains[0][0]
+}
+
+func certificateMatches(certificate *x509.Certificate, identity config.ClientIdentityConfig) (string, bool) {
+	if identity.CommonName != "" {
+		return identity.CommonName, certificate.Subject.CommonName == identity.CommonName
+	}
+
+	return identity.DNSName, containsString(certificate.DNSNames, identity.DNSName)
+}
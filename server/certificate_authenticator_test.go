@@ -0,0 +1,62 @@
+package server_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+
+	"github.com/cloudfoundry/config-server/config"
+	. "github.com/cloudfoundry/config-server/server"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func requestWithClientCertificate(commonName string, dnsNames ...string) *http.Request {
+	req, _ := http.NewRequest("GET", "/v1/data?name=/cf/password", nil)
+	req.TLS = &tls.ConnectionState{
+		VerifiedChains: [][]*x509.Certificate{{
+			{Subject: pkix.Name{CommonName: commonName}, DNSNames: dnsNames},
+		}},
+	}
+	return req
+}
+
+var _ = Describe("CertificateAuthenticator", func() {
+	var authenticator Authenticator
+
+	BeforeEach(func() {
+		authenticator = NewCertificateAuthenticator([]config.ClientIdentityConfig{
+			{CommonName: "worker", Scopes: []string{"config_server.read"}},
+			{DNSName: "deployer.bosh", Name: "deployer", Scopes: []string{"config_server.write", "config_server.generate"}},
+		})
+	})
+
+	It("maps certificates by their subject common name", func() {
+		identity, err := authenticator.Authenticate(requestWithClientCertificate("worker"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(identity).To(Equal(Identity{Name: "worker", ClientID: "worker", Scopes: []string{"config_server.read"}, Authenticator: AuthenticatorCertificate}))
+	})
+
+	It("maps certificates by their DNS names to the configured name", func() {
+		identity, err := authenticator.Authenticate(requestWithClientCertificate("some-vm", "vm.bosh", "deployer.bosh"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(identity).To(Equal(Identity{Name: "deployer", ClientID: "deployer", Scopes: []string{"config_server.write", "config_server.generate"}, Authenticator: AuthenticatorCertificate}))
+	})
+
+	It("returns an error for certificates not mapped to an identity", func() {
+		_, err := authenticator.Authenticate(requestWithClientCertificate("stranger", "stranger.bosh"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Client certificate 'stranger' is not mapped to an identity"))
+	})
+
+	It("returns an error for requests without verified certificate", func() {
+		req, _ := http.NewRequest("GET", "/v1/data?name=/cf/password", nil)
+		req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "worker"}}}}
+
+		_, err := authenticator.Authenticate(req)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Missing verified client certificate"))
+	})
+})
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"time"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/cloudfoundry/config-server/store"
+)
+
+const certificateExpiryLogTag = "CertificateExpiryCollector"
+
+// CertificateExpiryCollector periodically records when the certificate
+// expiring first among the latest values of every name expires. Its name is
+// only logged, since metrics are served without authentication.
+type CertificateExpiryCollector struct {
+	store    store.Store
+	metrics  Metrics
+	interval time.Duration
+	logger   boshlog.Logger
+}
+
+func NewCertificateExpiryCollector(store store.Store, metrics Metrics, interval time.Duration, logger boshlog.Logger) CertificateExpiryCollector {
+	return CertificateExpiryCollector{
+		store:    store,
+		metrics:  metrics,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Run collects right away and then once every interval until stop is closed
+func (c CertificateExpiryCollector) Run(stop <-chan struct{}) {
+	c.Collect()
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.Collect()
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (c CertificateExpiryCollector) Collect() {
+	configurations, err := c.store.GetByPath("")
+	if err != nil {
+		c.logger.Error(certificateExpiryLogTag, "Failed to load stored certificates: %s", err.Error())
+		return
+	}
+
+	var earliestName string
+	var earliest time.Time
+
+	for _, configuration := range configurations {
+		notAfter, ok := certificateNotAfter(configuration.Value)
+		if ok && (earliestName == "" || notAfter.Before(earliest)) {
+			earliestName, earliest = configuration.Name, notAfter
+		}
+	}
+
+	c.metrics.Reset(MetricCertificateEarliestNotAfter)
+	if earliestName != "" {
+		c.metrics.Set(MetricCertificateEarliestNotAfter, float64(earliest.Unix()))
+		c.logger.Debug(certificateExpiryLogTag, "Certificate '%s' expires first, at %s", earliestName, earliest.UTC().Format(time.RFC3339))
+	}
+}
+
+// certificateNotAfter returns the expiry of a stored value if it is a
+// certificate
+func certificateNotAfter(value string) (time.Time, bool) {
+	certificate, ok := storedCertificate(value)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return certificate.NotAfter, true
+}
+
+// storedCertificate returns the certificate of a stored value if it is one
+func storedCertificate(value string) (*x509.Certificate, bool) {
+	var stored struct {
+		Value struct {
+			Certificate string `json:"certificate"`
+		} `json:"value"`
+	}
+
+	if err := json.Unmarshal([]byte(value), &stored); err != nil {
+		return nil, false
+	}
+
+	block, _ := pem.Decode([]byte(stored.Value.Certificate))
+	if block == nil {
+		return nil, false
+	}
+
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, false
+	}
+
+	return certificate, true
+}
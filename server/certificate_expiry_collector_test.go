@@ -0,0 +1,79 @@
+package server_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"time"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	. "github.com/cloudfoundry/config-server/server"
+	"github.com/cloudfoundry/config-server/store"
+	. "github.com/cloudfoundry/config-server/store/storefakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CertificateExpiryCollector", func() {
+	var (
+		memoryStore store.MemoryStore
+		metrics     Metrics
+		collector   CertificateExpiryCollector
+	)
+
+	putCertificate := func(name string, notAfter time.Time) {
+		certificatePEM, _ := selfSignedCertificate(name, notAfter)
+		value, err := json.Marshal(map[string]interface{}{"value": map[string]string{"certificate": string(certificatePEM)}})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = memoryStore.Put(name, string(value), store.Metadata{})
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	writtenMetrics := func() string {
+		var out bytes.Buffer
+		Expect(metrics.Write(&out)).To(Succeed())
+		return out.String()
+	}
+
+	BeforeEach(func() {
+		memoryStore = store.NewMemoryStore()
+		metrics = NewMetrics()
+		collector = NewCertificateExpiryCollector(memoryStore, metrics, time.Hour, boshlog.NewLogger(boshlog.LevelNone))
+	})
+
+	It("records the latest certificate expiring first", func() {
+		putCertificate("/nats", time.Unix(2000000000, 0))
+		putCertificate("/uaa", time.Unix(1900000000, 0))
+		putCertificate("/uaa", time.Unix(2100000000, 0))
+		memoryStore.Put("/password", `{"value":"s3cr3t"}`, store.Metadata{})
+		memoryStore.Put("/options", `{"value":{"certificate":"not a certificate"}}`, store.Metadata{})
+
+		collector.Collect()
+
+		Expect(writtenMetrics()).To(ContainSubstring("\n" + MetricCertificateEarliestNotAfter + " 2e+09\n"))
+		Expect(writtenMetrics()).ToNot(ContainSubstring("/nats"))
+	})
+
+	It("removes the record when no certificate is stored anymore", func() {
+		putCertificate("/nats", time.Unix(2000000000, 0))
+		collector.Collect()
+
+		memoryStore.Delete("/nats")
+		collector.Collect()
+
+		Expect(writtenMetrics()).ToNot(ContainSubstring("\n" + MetricCertificateEarliestNotAfter + " "))
+	})
+
+	It("keeps the record when store errors", func() {
+		putCertificate("/nats", time.Unix(2000000000, 0))
+		collector.Collect()
+
+		fakeStore := &FakeStore{}
+		fakeStore.GetByPathReturns(nil, errors.New("Kaboom!"))
+		NewCertificateExpiryCollector(fakeStore, metrics, time.Hour, boshlog.NewLogger(boshlog.LevelNone)).Collect()
+
+		Expect(writtenMetrics()).To(ContainSubstring(MetricCertificateEarliestNotAfter + " 2e+09\n"))
+	})
+})
@@ -0,0 +1,54 @@
+package server
+
+import (
+	"time"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/cloudfoundry/config-server/store"
+)
+
+const purgerLogTag = "DeletedPurger"
+
+// DeletedPurger periodically removes deleted names whose retention expired
+type DeletedPurger struct {
+	store     store.Store
+	retention time.Duration
+	interval  time.Duration
+	logger    boshlog.Logger
+}
+
+func NewDeletedPurger(store store.Store, retention time.Duration, interval time.Duration, logger boshlog.Logger) DeletedPurger {
+	return DeletedPurger{
+		store:     store,
+		retention: retention,
+		interval:  interval,
+		logger:    logger,
+	}
+}
+
+// Run purges once every interval until stop is closed
+func (p DeletedPurger) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.Purge()
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (p DeletedPurger) Purge() {
+	purged, err := p.store.PurgeDeleted(p.retention)
+	if err != nil {
+		p.logger.Error(purgerLogTag, "Failed to purge deleted values: %s", err.Error())
+		return
+	}
+
+	if purged > 0 {
+		p.logger.Info(purgerLogTag, "Purged %d deleted values", purged)
+	}
+}
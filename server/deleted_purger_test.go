@@ -0,0 +1,57 @@
+package server_test
+
+import (
+	"errors"
+	"time"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	. "github.com/cloudfoundry/config-server/server"
+	. "github.com/cloudfoundry/config-server/store/storefakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DeletedPurger", func() {
+	var (
+		mockStore *FakeStore
+		purger    DeletedPurger
+	)
+
+	BeforeEach(func() {
+		mockStore = &FakeStore{}
+		purger = NewDeletedPurger(mockStore, 48*time.Hour, 10*time.Millisecond, boshlog.NewLogger(boshlog.LevelNone))
+	})
+
+	Describe("Purge", func() {
+		It("purges values deleted longer than retention ago", func() {
+			purger.Purge()
+
+			Expect(mockStore.PurgeDeletedCallCount()).To(Equal(1))
+			Expect(mockStore.PurgeDeletedArgsForCall(0)).To(Equal(48 * time.Hour))
+		})
+
+		It("does not panic when store errors", func() {
+			mockStore.PurgeDeletedReturns(0, errors.New("Kaboom!"))
+
+			Expect(purger.Purge).ToNot(Panic())
+		})
+	})
+
+	Describe("Run", func() {
+		It("purges every interval until stopped", func() {
+			stop := make(chan struct{})
+			done := make(chan struct{})
+
+			go func() {
+				purger.Run(stop)
+				close(done)
+			}()
+
+			Eventually(mockStore.PurgeDeletedCallCount).Should(BeNumerically(">=", 2))
+
+			close(stop)
+			Eventually(done).Should(BeClosed())
+		})
+	})
+})
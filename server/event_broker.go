@@ -0,0 +1,85 @@
+package server
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/config-server/store"
+)
+
+// eventBufferSize is the number of events a watcher may fall behind before it
+// is dropped
+const eventBufferSize = 64
+
+type eventSubscription struct {
+	matches func(name string) bool
+	events  chan store.Event
+}
+
+// EventBroker fans events out to the watchers of this config server
+type EventBroker struct {
+	mutex         *sync.Mutex
+	subscriptions map[*eventSubscription]struct{}
+}
+
+func NewEventBroker() EventBroker {
+	return EventBroker{
+		mutex:         &sync.Mutex{},
+		subscriptions: map[*eventSubscription]struct{}{},
+	}
+}
+
+// Subscribe returns a channel receiving events for names accepted by matches,
+// and a function ending the subscription. The channel is closed when the
+// subscription ends, including when the watcher falls too far behind.
+func (b EventBroker) Subscribe(matches func(name string) bool) (<-chan store.Event, func()) {
+	subscription := &eventSubscription{
+		matches: matches,
+		events:  make(chan store.Event, eventBufferSize),
+	}
+
+	b.mutex.Lock()
+	b.subscriptions[subscription] = struct{}{}
+	b.mutex.Unlock()
+
+	return subscription.events, func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+		b.unsubscribe(subscription)
+	}
+}
+
+func (b EventBroker) Publish(event store.Event) error {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for subscription := range b.subscriptions {
+		if !subscription.matches(event.Name) {
+			continue
+		}
+
+		select {
+		case subscription.events <- event:
+		default:
+			b.unsubscribe(subscription)
+		}
+	}
+
+	return nil
+}
+
+func (b EventBroker) unsubscribe(subscription *eventSubscription) {
+	if _, found := b.subscriptions[subscription]; found {
+		delete(b.subscriptions, subscription)
+		close(subscription.events)
+	}
+}
+
+// Close ends every subscription, so that watchers stop streaming
+func (b EventBroker) Close() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for subscription := range b.subscriptions {
+		b.unsubscribe(subscription)
+	}
+}
@@ -0,0 +1,73 @@
+package server_test
+
+import (
+	"strings"
+
+	. "github.com/cloudfoundry/config-server/server"
+	"github.com/cloudfoundry/config-server/store"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EventBroker", func() {
+	var broker EventBroker
+
+	BeforeEach(func() {
+		broker = NewEventBroker()
+	})
+
+	It("delivers events for matching names only", func() {
+		events, unsubscribe := broker.Subscribe(func(name string) bool { return strings.HasPrefix(name, "/cf/") })
+		defer unsubscribe()
+
+		broker.Publish(store.Event{Type: store.EventPut, ID: "1", Name: "/bosh/password"})
+		broker.Publish(store.Event{Type: store.EventPut, ID: "2", Name: "/cf/password"})
+
+		Expect(<-events).To(Equal(store.Event{Type: store.EventPut, ID: "2", Name: "/cf/password"}))
+		Expect(events).ToNot(Receive())
+	})
+
+	It("delivers each event to every subscriber", func() {
+		events1, unsubscribe1 := broker.Subscribe(func(string) bool { return true })
+		defer unsubscribe1()
+		events2, unsubscribe2 := broker.Subscribe(func(string) bool { return true })
+		defer unsubscribe2()
+
+		broker.Publish(store.Event{Type: store.EventDelete, Name: "/cf/password"})
+
+		Expect(events1).To(Receive(Equal(store.Event{Type: store.EventDelete, Name: "/cf/password"})))
+		Expect(events2).To(Receive(Equal(store.Event{Type: store.EventDelete, Name: "/cf/password"})))
+	})
+
+	It("closes the channel when unsubscribing", func() {
+		events, unsubscribe := broker.Subscribe(func(string) bool { return true })
+		unsubscribe()
+		unsubscribe()
+
+		Expect(events).To(BeClosed())
+		Expect(broker.Publish(store.Event{Type: store.EventPut, Name: "/cf/password"})).To(Succeed())
+	})
+
+	It("drops subscribers that fall behind instead of blocking", func() {
+		events, unsubscribe := broker.Subscribe(func(string) bool { return true })
+		defer unsubscribe()
+
+		for i := 0; i < 1000; i++ {
+			broker.Publish(store.Event{Type: store.EventPut, Name: "/cf/password"})
+		}
+
+		Eventually(events).Should(BeClosed())
+	})
+
+	It("closes every channel when closed", func() {
+		events1, unsubscribe1 := broker.Subscribe(func(string) bool { return true })
+		events2, _ := broker.Subscribe(func(string) bool { return false })
+
+		broker.Close()
+		unsubscribe1()
+
+		Expect(events1).To(BeClosed())
+		Expect(events2).To(BeClosed())
+	})
+})
@@ -0,0 +1,102 @@
+package server
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cloudfoundry/config-server/store"
+)
+
+// generationMode controls what a generate request does when a value already
+// exists under the requested name.
+type generationMode string
+
+const (
+	noOverwriteMode generationMode = "no-overwrite"
+	overwriteMode   generationMode = "overwrite"
+	convergeMode    generationMode = "converge"
+)
+
+func readGenerationMode(jsonMap map[string]interface{}) (generationMode, error) {
+	if _, keyExists := jsonMap["mode"]; !keyExists {
+		return noOverwriteMode, nil
+	}
+
+	mode, err := getStringValueFromJSONBody(jsonMap, "mode")
+	if err != nil {
+		return "", err
+	}
+
+	switch generationMode(mode) {
+	case noOverwriteMode, overwriteMode, convergeMode:
+		return generationMode(mode), nil
+	default:
+		return "", errors.Errorf("JSON request body key 'mode' must be one of '%s', '%s' or '%s'", noOverwriteMode, overwriteMode, convergeMode)
+	}
+}
+
+// needsGeneration reports whether a new version has to be generated given the
+// latest stored configuration, which is empty when the name does not exist.
+func (mode generationMode) needsGeneration(current store.Configuration, generatorType string, parameters interface{}) (bool, error) {
+	if current == (store.Configuration{}) {
+		return true, nil
+	}
+
+	switch mode {
+	case overwriteMode:
+		return true, nil
+	case convergeMode:
+		matches, err := generationMatches(current, generatorType, parameters)
+		return !matches, err
+	default:
+		return false, nil
+	}
+}
+
+// generationMatches compares the type and parameters a configuration was
+// generated with against the requested ones. Values that were set directly or
+// generated before parameters were recorded never match.
+func generationMatches(configuration store.Configuration, generatorType string, parameters interface{}) (bool, error) {
+	if configuration.Type != generatorType {
+		return false, nil
+	}
+
+	var storedParameters interface{}
+	if configuration.Parameters != "" {
+		if err := json.Unmarshal([]byte(configuration.Parameters), &storedParameters); err != nil {
+			return false, errors.WrapErrorf(err, "Failed to parse stored parameters of '%s'", configuration.Name)
+		}
+	}
+
+	requested, err := normalizeParameters(parameters)
+	if err != nil {
+		return false, err
+	}
+
+	existing, err := normalizeParameters(storedParameters)
+	if err != nil {
+		return false, err
+	}
+
+	return reflect.DeepEqual(requested, existing), nil
+}
+
+// normalizeParameters round-trips parameters through JSON so that values
+// decoded from requests and from the store compare equal. Missing parameters
+// are equivalent to empty ones.
+func normalizeParameters(parameters interface{}) (interface{}, error) {
+	if parameters == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	bytes, err := json.Marshal(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	var normalized interface{}
+	err = json.Unmarshal(bytes, &normalized)
+
+	return normalized, err
+}
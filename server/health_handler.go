@@ -0,0 +1,78 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ReadinessCheck returns an error when a dependency of the config server is
+// not usable
+type ReadinessCheck func() error
+
+type checkResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type healthHandler struct{}
+
+// NewHealthHandler returns a handler reporting that the config server is
+// alive whenever it is able to respond
+func NewHealthHandler() http.Handler {
+	return healthHandler{}
+}
+
+func (handler healthHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" && req.Method != "HEAD" {
+		http.Error(resWriter, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	respondJSON(resWriter, checkResult{Status: "ok"}, http.StatusOK)
+}
+
+type readinessHandler struct {
+	checks map[string]ReadinessCheck
+}
+
+// NewReadinessHandler returns a handler running every check, responding
+// with 503 Service Unavailable if any of them fails
+func NewReadinessHandler(checks map[string]ReadinessCheck) http.Handler {
+	return readinessHandler{checks: checks}
+}
+
+func (handler readinessHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" && req.Method != "HEAD" {
+		http.Error(resWriter, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := http.StatusOK
+	response := struct {
+		Status string                 `json:"status"`
+		Checks map[string]checkResult `json:"checks"`
+	}{Status: "ok", Checks: map[string]checkResult{}}
+
+	for name, check := range handler.checks {
+		if err := check(); err != nil {
+			status = http.StatusServiceUnavailable
+			response.Status = "failed"
+			response.Checks[name] = checkResult{Status: "failed", Error: err.Error()}
+		} else {
+			response.Checks[name] = checkResult{Status: "ok"}
+		}
+	}
+
+	respondJSON(resWriter, response, status)
+}
+
+func respondJSON(resWriter http.ResponseWriter, response interface{}, status int) {
+	bytes, err := json.Marshal(response)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resWriter.Header().Set("Content-Type", "application/json")
+	respond(resWriter, string(bytes), status)
+}
@@ -0,0 +1,70 @@
+package server_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/cloudfoundry/config-server/server"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("HealthHandler", func() {
+	It("should respond that the server is alive", func() {
+		req, _ := http.NewRequest("GET", "/health", nil)
+		recorder := httptest.NewRecorder()
+		NewHealthHandler().ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(recorder.Body.String()).To(Equal(`{"status":"ok"}`))
+	})
+
+	It("should return 405 Method Not Allowed for methods other than GET and HEAD", func() {
+		req, _ := http.NewRequest("POST", "/health", nil)
+		recorder := httptest.NewRecorder()
+		NewHealthHandler().ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
+
+var _ = Describe("ReadinessHandler", func() {
+	ready := func(checks map[string]ReadinessCheck) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/ready", nil)
+		recorder := httptest.NewRecorder()
+		NewReadinessHandler(checks).ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	It("should respond with the result of every check when all succeed", func() {
+		recorder := ready(map[string]ReadinessCheck{
+			"store":                 func() error { return nil },
+			"jwt_verification_keys": func() error { return nil },
+		})
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"status":"ok","checks":{"store":{"status":"ok"},"jwt_verification_keys":{"status":"ok"}}}`))
+	})
+
+	It("should return 503 Service Unavailable with the errors of failed checks", func() {
+		recorder := ready(map[string]ReadinessCheck{
+			"store":                 func() error { return errors.New("connection refused") },
+			"jwt_verification_keys": func() error { return nil },
+		})
+
+		Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+		Expect(recorder.Body.String()).To(MatchJSON(`{"status":"failed","checks":{"store":{"status":"failed","error":"connection refused"},"jwt_verification_keys":{"status":"ok"}}}`))
+	})
+
+	It("should return 405 Method Not Allowed for methods other than GET and HEAD", func() {
+		req, _ := http.NewRequest("DELETE", "/ready", nil)
+		recorder := httptest.NewRecorder()
+		NewReadinessHandler(nil).ServeHTTP(recorder, req)
+
+		Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Authenticators an identity can be established by
+const (
+	AuthenticatorToken       = "token"
+	AuthenticatorCertificate = "certificate"
+	AuthenticatorAPIKey      = "api_key"
+)
+
+// Identity describes the caller a request was authenticated as. Name is the
+// user name for tokens issued to users, and the client ID otherwise.
+// Authenticator is the kind of credentials the caller was authenticated by.
+type Identity struct {
+	Name          string
+	ClientID      string
+	UserName      string
+	Scopes        []string
+	Authenticator string
+}
+
+// Actor returns the name of the caller qualified by its authenticator, e.g.
+// "certificate:worker", so that callers of different authenticators sharing a
+// name are told apart. Tokens are further qualified by the claim they are
+// named by, as in "token:user:admin", "token:client:director" and
+// "token:subject:<sub>", so that a user cannot take the name of a client. It
+// is empty for unauthenticated callers.
+func (i Identity) Actor() string {
+	if i.Name == "" || i.Authenticator == "" {
+		return ""
+	}
+	if i.Authenticator != AuthenticatorToken {
+		return i.Authenticator + ":" + i.Name
+	}
+
+	switch {
+	case i.UserName != "":
+		return "token:user:" + i.UserName
+	case i.ClientID != "":
+		return "token:client:" + i.ClientID
+	default:
+		return "token:subject:" + i.Name
+	}
+}
+
+// actorPrefixes are the prefixes of actors of every kind of caller
+var actorPrefixes = []string{"token:user:", "token:client:", "token:subject:", AuthenticatorCertificate + ":", AuthenticatorAPIKey + ":"}
+
+// IsActor returns whether actor is a name qualified as Actor qualifies it
+func IsActor(actor string) bool {
+	for _, prefix := range actorPrefixes {
+		if strings.HasPrefix(actor, prefix) && len(actor) > len(prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope returns whether the caller was granted scope
+func (i Identity) HasScope(scope string) bool {
+	for _, granted := range i.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type identityContextKey struct{}
+
+// WithIdentity returns a copy of req carrying identity
+func WithIdentity(req *http.Request, identity Identity) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), identityContextKey{}, identity))
+}
+
+// IdentityFromRequest returns the identity req was authenticated as, or an
+// empty Identity for unauthenticated requests
+func IdentityFromRequest(req *http.Request) Identity {
+	identity, _ := req.Context().Value(identityContextKey{}).(Identity)
+	return identity
+}
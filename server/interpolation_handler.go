@@ -0,0 +1,312 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cloudfoundry/config-server/store"
+	"gopkg.in/yaml.v2"
+)
+
+var placeholderRegexp = regexp.MustCompile(`\(\(([a-zA-Z0-9_\-\/]+)((?:\.[a-zA-Z0-9_\-]+)*)\)\)`)
+
+type interpolationHandler struct {
+	store      store.Store
+	authorizer Authorizer
+}
+
+type documentFormat int
+
+const (
+	jsonDocument documentFormat = iota
+	yamlDocument
+)
+
+func NewInterpolationHandler(store store.Store, authorizer Authorizer) (http.Handler, error) {
+	if store == nil {
+		return nil, errors.Error("Data store must be set")
+	}
+	if authorizer == nil {
+		return nil, errors.Error("Authorizer must be set")
+	}
+	return interpolationHandler{store: store, authorizer: authorizer}, nil
+}
+
+func (handler interpolationHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	if req.Method != "POST" {
+		http.Error(resWriter, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	format, err := readDocumentFormat(req)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	document, err := readDocument(req, format)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	names := placeholderNames(document)
+	recordAuditNames(req, names)
+	if len(names) == 0 {
+		if !authorizeUnnamedRequest(resWriter, req) {
+			return
+		}
+	} else if !authorizeRequest(handler.authorizer, resWriter, req, names, nil) {
+		return
+	}
+
+	values, missingNames, err := handler.fetchValues(names)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if len(missingNames) > 0 {
+		http.Error(resWriter, "Failed to find variables: "+strings.Join(missingNames, ", "), http.StatusNotFound)
+		return
+	}
+
+	interpolator := documentInterpolator{values: values}
+	result := interpolator.interpolate(document)
+	if len(interpolator.errs) > 0 {
+		http.Error(resWriter, strings.Join(interpolator.errs, "\n"), http.StatusBadRequest)
+		return
+	}
+
+	body, contentType, err := writeDocument(result, format)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resWriter.Header().Set("Content-Type", contentType)
+	respond(resWriter, body, http.StatusOK)
+}
+
+// placeholderNames returns the sorted names referenced by placeholders in
+// document
+func placeholderNames(document interface{}) []string {
+	found := map[string]bool{}
+	collectPlaceholderNames(document, found)
+
+	var names []string
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// fetchValues loads the latest value of every name, returning the names that
+// do not exist in the store.
+func (handler interpolationHandler) fetchValues(names []string) (map[string]interface{}, []string, error) {
+	values := map[string]interface{}{}
+	var missingNames []string
+
+	for _, name := range names {
+		configuration, err := handler.store.GetCurrentByName(name)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if configuration == (store.Configuration{}) {
+			missingNames = append(missingNames, name)
+			continue
+		}
+
+		value, err := storedValue(configuration)
+		if err != nil {
+			return nil, nil, err
+		}
+		values[name] = value
+	}
+
+	sort.Strings(missingNames)
+
+	return values, missingNames, nil
+}
+
+func collectPlaceholderNames(node interface{}, names map[string]bool) {
+	switch typedNode := node.(type) {
+	case string:
+		for _, match := range placeholderRegexp.FindAllStringSubmatch(typedNode, -1) {
+			names[match[1]] = true
+		}
+	case map[interface{}]interface{}:
+		for _, value := range typedNode {
+			collectPlaceholderNames(value, names)
+		}
+	case map[string]interface{}:
+		for _, value := range typedNode {
+			collectPlaceholderNames(value, names)
+		}
+	case []interface{}:
+		for _, value := range typedNode {
+			collectPlaceholderNames(value, names)
+		}
+	}
+}
+
+type documentInterpolator struct {
+	values map[string]interface{}
+	errs   []string
+}
+
+func (i *documentInterpolator) interpolate(node interface{}) interface{} {
+	switch typedNode := node.(type) {
+	case string:
+		return i.interpolateString(typedNode)
+	case map[interface{}]interface{}:
+		result := map[string]interface{}{}
+		for key, value := range typedNode {
+			result[fmt.Sprintf("%v", key)] = i.interpolate(value)
+		}
+		return result
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		for key, value := range typedNode {
+			result[key] = i.interpolate(value)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(typedNode))
+		for index, value := range typedNode {
+			result[index] = i.interpolate(value)
+		}
+		return result
+	default:
+		return node
+	}
+}
+
+func (i *documentInterpolator) interpolateString(str string) interface{} {
+	// A placeholder spanning the whole string is replaced by the value itself,
+	// which may be of any type.
+	if match := placeholderRegexp.FindStringSubmatch(str); match != nil && match[0] == str {
+		value, _ := i.resolve(match[1], match[2])
+		return value
+	}
+
+	return placeholderRegexp.ReplaceAllStringFunc(str, func(placeholder string) string {
+		match := placeholderRegexp.FindStringSubmatch(placeholder)
+
+		value, ok := i.resolve(match[1], match[2])
+		if !ok {
+			return placeholder
+		}
+
+		switch typedValue := value.(type) {
+		case string:
+			return typedValue
+		case float64:
+			return strconv.FormatFloat(typedValue, 'f', -1, 64)
+		case bool:
+			return strconv.FormatBool(typedValue)
+		default:
+			i.errs = append(i.errs, fmt.Sprintf("Variable '%s' is not a primitive value and cannot be interpolated into a string", strings.Trim(placeholder, "()")))
+			return placeholder
+		}
+	})
+}
+
+func (i *documentInterpolator) resolve(name string, subKeys string) (interface{}, bool) {
+	value := i.values[name]
+	if len(subKeys) == 0 {
+		return value, true
+	}
+
+	path := name
+	for _, subKey := range strings.Split(strings.TrimPrefix(subKeys, "."), ".") {
+		valueMap, ok := value.(map[string]interface{})
+		if !ok {
+			i.errs = append(i.errs, fmt.Sprintf("Variable '%s' is not a hash, cannot find sub key '%s'", path, subKey))
+			return nil, false
+		}
+
+		value, ok = valueMap[subKey]
+		if !ok {
+			i.errs = append(i.errs, fmt.Sprintf("Failed to find sub key '%s' in variable '%s'", subKey, path))
+			return nil, false
+		}
+		path = path + "." + subKey
+	}
+
+	return value, true
+}
+
+func storedValue(configuration store.Configuration) (interface{}, error) {
+	var stored map[string]interface{}
+	if err := json.Unmarshal([]byte(configuration.Value), &stored); err != nil {
+		return nil, errors.WrapErrorf(err, "Failed to parse stored value of '%s'", configuration.Name)
+	}
+
+	return stored["value"], nil
+}
+
+func readDocumentFormat(req *http.Request) (documentFormat, error) {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("content-type"))
+	if err == nil {
+		switch strings.ToLower(mediaType) {
+		case "application/json":
+			return jsonDocument, nil
+		case "application/x-yaml", "application/yaml", "text/yaml", "text/x-yaml":
+			return yamlDocument, nil
+		}
+	}
+
+	return jsonDocument, errors.Error("Unsupported Media Type - Accepts application/json or application/x-yaml only")
+}
+
+func readDocument(req *http.Request, format documentFormat) (interface{}, error) {
+	if req.Body == nil {
+		return nil, errors.Error("Request can't be empty")
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to read request body")
+	}
+
+	var document interface{}
+
+	if format == jsonDocument {
+		decoder := json.NewDecoder(strings.NewReader(string(body)))
+		decoder.UseNumber()
+		if err := decoder.Decode(&document); err != nil {
+			return nil, errors.Error("Request Body should be JSON string")
+		}
+	} else {
+		if err := yaml.Unmarshal(body, &document); err != nil {
+			return nil, errors.Error("Request Body should be YAML string")
+		}
+	}
+
+	return document, nil
+}
+
+func writeDocument(document interface{}, format documentFormat) (string, string, error) {
+	var bytes []byte
+	var err error
+
+	if format == jsonDocument {
+		bytes, err = json.Marshal(document)
+		return string(bytes), "application/json", err
+	}
+
+	bytes, err = yaml.Marshal(document)
+	return string(bytes), "application/x-yaml", err
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/dgrijalva/jwt-go"
+)
+
+const jwksTimeout = 10 * time.Second
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// jsonWebKey is an RSA key as served by UAA's token_keys endpoint, which
+// adds the PEM encoded key as value
+type jsonWebKey struct {
+	Kid   string `json:"kid"`
+	Kty   string `json:"kty"`
+	N     string `json:"n"`
+	E     string `json:"e"`
+	Value string `json:"value"`
+}
+
+// NewJWKSKeyLoader returns a KeyLoader fetching the JSON Web Key Set served
+// at url. Keys that are not RSA keys are ignored.
+func NewJWKSKeyLoader(url string, client *http.Client) KeyLoader {
+	return func() (map[string]*rsa.PublicKey, error) {
+		res, err := client.Get(url)
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to fetch JSON Web Key Set")
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			return nil, errors.Errorf("Failed to fetch JSON Web Key Set: unexpected response status %d", res.StatusCode)
+		}
+
+		var keySet jsonWebKeySet
+		if err := json.NewDecoder(res.Body).Decode(&keySet); err != nil {
+			return nil, errors.WrapError(err, "Failed to parse JSON Web Key Set")
+		}
+
+		keys := map[string]*rsa.PublicKey{}
+		for _, webKey := range keySet.Keys {
+			if webKey.Kty != "" && webKey.Kty != "RSA" {
+				continue
+			}
+
+			key, err := webKey.rsaPublicKey()
+			if err != nil {
+				return nil, errors.WrapErrorf(err, "Failed to parse JSON Web Key '%s'", webKey.Kid)
+			}
+
+			keys[webKey.Kid] = key
+		}
+
+		return keys, nil
+	}
+}
+
+// NewJWKSClient returns a client for fetching JSON Web Key Sets. When
+// caCertificatePath is set, servers must present a certificate signed by it.
+func NewJWKSClient(caCertificatePath string) (*http.Client, error) {
+	if caCertificatePath == "" {
+		return &http.Client{Timeout: jwksTimeout}, nil
+	}
+
+	bytes, err := ioutil.ReadFile(caCertificatePath)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to read token keys CA certificate")
+	}
+
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(bytes) {
+		return nil, errors.Error("Failed to parse token keys CA certificate")
+	}
+
+	return &http.Client{
+		Timeout:   jwksTimeout,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: rootCAs}},
+	}, nil
+}
+
+func (k jsonWebKey) rsaPublicKey() (*rsa.PublicKey, error) {
+	if k.N == "" || k.E == "" {
+		return jwt.ParseRSAPublicKeyFromPEM([]byte(k.Value))
+	}
+
+	n, err := decodeBase64URLInt(k.N)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := decodeBase64URLInt(k.E)
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.IsInt64() || e.Int64() > int64(^uint32(0)>>1) {
+		return nil, errors.Error("Exponent is too large")
+	}
+
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func decodeBase64URLInt(value string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, err
+	}
+
+	return new(big.Int).SetBytes(bytes), nil
+}
@@ -0,0 +1,96 @@
+package server_test
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/cloudfoundry/config-server/server"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JWKSKeyLoader", func() {
+	var (
+		tokenKeys *httptest.Server
+		response  string
+		status    int
+		keyA      *rsa.PublicKey
+		keyB      *rsa.PublicKey
+	)
+
+	base64URL := func(value *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(value.Bytes())
+	}
+
+	BeforeEach(func() {
+		_, keyA = generateRSAKeyPair()
+		_, keyB = generateRSAKeyPair()
+		status = http.StatusOK
+
+		keySet, err := json.Marshal(map[string]interface{}{
+			"keys": []map[string]string{
+				{"kid": "key-a", "kty": "RSA", "alg": "RS256", "n": base64URL(keyA.N), "e": base64URL(big.NewInt(int64(keyA.E)))},
+				{"kid": "key-b", "kty": "RSA", "value": string(publicKeyPEM(keyB))},
+				{"kid": "key-c", "kty": "EC", "crv": "P-256", "x": "abc", "y": "def"},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		response = string(keySet)
+
+		tokenKeys = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			Expect(req.URL.Path).To(Equal("/token_keys"))
+			w.WriteHeader(status)
+			w.Write([]byte(response))
+		}))
+	})
+
+	AfterEach(func() {
+		tokenKeys.Close()
+	})
+
+	It("loads RSA keys by their key ID", func() {
+		keys, err := NewJWKSKeyLoader(tokenKeys.URL+"/token_keys", http.DefaultClient)()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(keys).To(HaveLen(2))
+		Expect(keys["key-a"]).To(Equal(keyA))
+		Expect(keys["key-b"]).To(Equal(keyB))
+	})
+
+	It("returns an error when the response is not successful", func() {
+		status = http.StatusInternalServerError
+
+		_, err := NewJWKSKeyLoader(tokenKeys.URL+"/token_keys", http.DefaultClient)()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Failed to fetch JSON Web Key Set: unexpected response status 500"))
+	})
+
+	It("returns an error when the response cannot be parsed", func() {
+		response = "garbage"
+
+		_, err := NewJWKSKeyLoader(tokenKeys.URL+"/token_keys", http.DefaultClient)()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Failed to parse JSON Web Key Set"))
+	})
+
+	It("returns an error when a key cannot be parsed", func() {
+		response = `{"keys":[{"kid":"broken","kty":"RSA","n":"!!!","e":"AQAB"}]}`
+
+		_, err := NewJWKSKeyLoader(tokenKeys.URL+"/token_keys", http.DefaultClient)()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Failed to parse JSON Web Key 'broken'"))
+	})
+
+	Describe("NewJWKSClient", func() {
+		It("returns an error when CA certificate cannot be read", func() {
+			_, err := NewJWKSClient("/non/existent/ca.crt")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Failed to read token keys CA certificate"))
+		})
+	})
+})
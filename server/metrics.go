@@ -0,0 +1,292 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/config-server/store"
+)
+
+const (
+	MetricHTTPRequests                = "config_server_http_requests_total"
+	MetricHTTPRequestDuration         = "config_server_http_request_duration_seconds"
+	MetricGenerateDuration            = "config_server_generate_duration_seconds"
+	MetricStoreOperationDuration      = "config_server_store_operation_duration_seconds"
+	MetricStoreErrors                 = "config_server_store_errors_total"
+	MetricDBMaxOpenConnections        = "config_server_db_max_open_connections"
+	MetricDBOpenConnections           = "config_server_db_open_connections"
+	MetricDBInUseConnections          = "config_server_db_in_use_connections"
+	MetricDBIdleConnections           = "config_server_db_idle_connections"
+	MetricDBWaits                     = "config_server_db_waits_total"
+	MetricDBWaitDuration              = "config_server_db_wait_duration_seconds_total"
+	MetricCertificateEarliestNotAfter = "config_server_certificate_earliest_expiry_timestamp_seconds"
+)
+
+// latencyBuckets are the upper bounds, in seconds, of latency histograms
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+type metricFamily struct {
+	name       string
+	help       string
+	kind       string
+	labelNames []string
+	series     map[string]*metricSeries
+}
+
+// metricSeries is the value of a counter or gauge, or the sum of the
+// observations of a histogram, for one set of label values
+type metricSeries struct {
+	labelValues  []string
+	value        float64
+	count        uint64
+	bucketCounts []uint64
+}
+
+// Metrics keeps the counters, gauges and histograms of the config server
+// and writes them in the Prometheus text exposition format. Collectors are
+// run before metrics are written, to update gauges that are only looked up
+// when scraped.
+type Metrics struct {
+	mutex      *sync.Mutex
+	families   map[string]*metricFamily
+	collectors *[]func()
+}
+
+func NewMetrics() Metrics {
+	metrics := Metrics{
+		mutex:      &sync.Mutex{},
+		families:   map[string]*metricFamily{},
+		collectors: &[]func(){},
+	}
+
+	metrics.register(MetricHTTPRequests, "counter", "Requests served, by method and status.", "method", "status")
+	metrics.register(MetricHTTPRequestDuration, "histogram", "Time taken to serve requests, by method and status.", "method", "status")
+	metrics.register(MetricGenerateDuration, "histogram", "Time taken to generate values, by type.", "type")
+	metrics.register(MetricStoreOperationDuration, "histogram", "Time taken by store operations, by backend and operation.", "backend", "operation")
+	metrics.register(MetricStoreErrors, "counter", "Store operations that failed, by backend and operation.", "backend", "operation")
+	metrics.register(MetricDBMaxOpenConnections, "gauge", "Maximum number of open connections to the database.", "backend")
+	metrics.register(MetricDBOpenConnections, "gauge", "Established connections to the database, in use or idle.", "backend")
+	metrics.register(MetricDBInUseConnections, "gauge", "Connections to the database currently in use.", "backend")
+	metrics.register(MetricDBIdleConnections, "gauge", "Idle connections to the database.", "backend")
+	metrics.register(MetricDBWaits, "counter", "Times a database connection had to be waited for.", "backend")
+	metrics.register(MetricDBWaitDuration, "counter", "Time spent waiting for database connections.", "backend")
+	metrics.register(MetricCertificateEarliestNotAfter, "gauge", "Expiry time, in seconds since the epoch, of the stored certificate expiring first.")
+
+	return metrics
+}
+
+func (m Metrics) register(name, kind, help string, labelNames ...string) {
+	m.families[name] = &metricFamily{
+		name:       name,
+		help:       help,
+		kind:       kind,
+		labelNames: labelNames,
+		series:     map[string]*metricSeries{},
+	}
+}
+
+// Add increases a counter by value
+func (m Metrics) Add(name string, value float64, labelValues ...string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if series := m.series(name, labelValues); series != nil {
+		series.value += value
+	}
+}
+
+// Set sets a gauge, or a counter kept by another component, to value
+func (m Metrics) Set(name string, value float64, labelValues ...string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if series := m.series(name, labelValues); series != nil {
+		series.value = value
+	}
+}
+
+// Observe adds value to a histogram
+func (m Metrics) Observe(name string, value float64, labelValues ...string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	series := m.series(name, labelValues)
+	if series == nil {
+		return
+	}
+
+	series.value += value
+	series.count++
+	for i, bound := range latencyBuckets {
+		if value <= bound {
+			series.bucketCounts[i]++
+		}
+	}
+}
+
+// Reset removes every series of a metric
+func (m Metrics) Reset(name string) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if family, found := m.families[name]; found {
+		family.series = map[string]*metricSeries{}
+	}
+}
+
+// AddCollector registers collect to be run whenever metrics are written
+func (m Metrics) AddCollector(collect func()) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	*m.collectors = append(*m.collectors, collect)
+}
+
+// series returns the series of the named metric with labelValues, creating
+// it when needed. Unknown metrics and wrong numbers of label values are
+// ignored.
+func (m Metrics) series(name string, labelValues []string) *metricSeries {
+	family, found := m.families[name]
+	if !found || len(labelValues) != len(family.labelNames) {
+		return nil
+	}
+
+	key := strings.Join(labelValues, "\xff")
+	series, found := family.series[key]
+	if !found {
+		series = &metricSeries{labelValues: append([]string{}, labelValues...)}
+		if family.kind == "histogram" {
+			series.bucketCounts = make([]uint64, len(latencyBuckets))
+		}
+		family.series[key] = series
+	}
+
+	return series
+}
+
+// Write runs the collectors and writes every metric to w in the Prometheus
+// text exposition format
+func (m Metrics) Write(w io.Writer) error {
+	m.mutex.Lock()
+	collectors := append([]func(){}, *m.collectors...)
+	m.mutex.Unlock()
+
+	for _, collect := range collectors {
+		collect()
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var names []string
+	for name := range m.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, err := io.WriteString(w, m.families[name].format()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *metricFamily) format() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.kind)
+
+	var keys []string
+	for key := range f.series {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		series := f.series[key]
+		labels := formatLabels(f.labelNames, series.labelValues)
+
+		if f.kind != "histogram" {
+			fmt.Fprintf(&out, "%s%s %s\n", f.name, labels, formatValue(series.value))
+			continue
+		}
+
+		for i, bound := range latencyBuckets {
+			fmt.Fprintf(&out, "%s_bucket%s %d\n", f.name, formatLabels(f.labelNames, series.labelValues, "le", formatValue(bound)), series.bucketCounts[i])
+		}
+		fmt.Fprintf(&out, "%s_bucket%s %d\n", f.name, formatLabels(f.labelNames, series.labelValues, "le", "+Inf"), series.count)
+		fmt.Fprintf(&out, "%s_sum%s %s\n", f.name, labels, formatValue(series.value))
+		fmt.Fprintf(&out, "%s_count%s %d\n", f.name, labels, series.count)
+	}
+
+	return out.String()
+}
+
+// formatLabels formats the label names and values of a series, followed by
+// an optional extra name and value
+func formatLabels(names, values []string, extra ...string) string {
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+	var pairs []string
+	for i, name := range names {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, name, escaper.Replace(values[i])))
+	}
+	if len(extra) == 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, extra[0], extra[1]))
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
+
+// NewStoreObserver returns a store.OperationObserver recording the latency
+// and errors of store operations. Failed preconditions are expected
+// outcomes and are not counted as errors.
+func NewStoreObserver(metrics Metrics, backend string) store.OperationObserver {
+	return func(operation string, duration time.Duration, err error) {
+		metrics.Observe(MetricStoreOperationDuration, duration.Seconds(), backend, operation)
+		if err != nil && err != store.ErrPreconditionFailed {
+			metrics.Add(MetricStoreErrors, 1, backend, operation)
+		}
+	}
+}
+
+// NewDBStatsCollector returns a collector recording the statistics of the
+// connection pool of the database reporter keeps its data in
+func NewDBStatsCollector(metrics Metrics, backend string, reporter store.DBStatsReporter) func() {
+	return func() {
+		stats, err := reporter.DBStats()
+		if err != nil {
+			return
+		}
+
+		metrics.Set(MetricDBMaxOpenConnections, float64(stats.MaxOpenConnections), backend)
+		metrics.Set(MetricDBOpenConnections, float64(stats.OpenConnections), backend)
+		metrics.Set(MetricDBInUseConnections, float64(stats.InUse), backend)
+		metrics.Set(MetricDBIdleConnections, float64(stats.Idle), backend)
+		metrics.Set(MetricDBWaits, float64(stats.WaitCount), backend)
+		metrics.Set(MetricDBWaitDuration, stats.WaitDuration.Seconds(), backend)
+	}
+}
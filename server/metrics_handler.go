@@ -0,0 +1,82 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// metricsMethods are the methods requests are counted by. Others are counted
+// as "other", so that clients cannot add series at will.
+var metricsMethods = map[string]bool{"GET": true, "HEAD": true, "PUT": true, "POST": true, "DELETE": true}
+
+type metricsHandler struct {
+	metrics Metrics
+}
+
+// NewMetricsHandler returns a handler responding with metrics in the
+// Prometheus text exposition format
+func NewMetricsHandler(metrics Metrics) http.Handler {
+	return metricsHandler{metrics: metrics}
+}
+
+func (handler metricsHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" {
+		http.Error(resWriter, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var body bytes.Buffer
+	if err := handler.metrics.Write(&body); err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resWriter.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	respond(resWriter, body.String(), http.StatusOK)
+}
+
+type requestMetricsHandler struct {
+	metrics     Metrics
+	nextHandler http.Handler
+}
+
+// NewRequestMetricsHandler returns a handler counting and timing the
+// requests served by nextHandler by their method and response status
+func NewRequestMetricsHandler(metrics Metrics, nextHandler http.Handler) http.Handler {
+	return requestMetricsHandler{metrics: metrics, nextHandler: nextHandler}
+}
+
+func (handler requestMetricsHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	recorder := &statusResponseWriter{ResponseWriter: resWriter, status: http.StatusOK}
+	handler.nextHandler.ServeHTTP(recorder, req)
+
+	method := req.Method
+	if !metricsMethods[method] {
+		method = "other"
+	}
+	status := strconv.Itoa(recorder.status)
+
+	handler.metrics.Add(MetricHTTPRequests, 1, method, status)
+	handler.metrics.Observe(MetricHTTPRequestDuration, time.Since(start).Seconds(), method, status)
+}
+
+// statusResponseWriter records the status of the response
+type statusResponseWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Flush lets the watch stream through
+func (w *statusResponseWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
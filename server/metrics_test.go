@@ -0,0 +1,185 @@
+package server_test
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/cloudfoundry/config-server/server"
+	. "github.com/cloudfoundry/config-server/server/serverfakes"
+	"github.com/cloudfoundry/config-server/store"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type fakeDBStatsReporter struct {
+	stats sql.DBStats
+	err   error
+}
+
+func (r fakeDBStatsReporter) DBStats() (sql.DBStats, error) {
+	return r.stats, r.err
+}
+
+var _ = Describe("Metrics", func() {
+	var metrics Metrics
+
+	writtenMetrics := func() string {
+		var out bytes.Buffer
+		Expect(metrics.Write(&out)).To(Succeed())
+		return out.String()
+	}
+
+	BeforeEach(func() {
+		metrics = NewMetrics()
+	})
+
+	It("writes counters by their labels", func() {
+		metrics.Add(MetricHTTPRequests, 1, "GET", "200")
+		metrics.Add(MetricHTTPRequests, 1, "GET", "200")
+		metrics.Add(MetricHTTPRequests, 1, "PUT", "400")
+
+		Expect(writtenMetrics()).To(ContainSubstring(`# HELP config_server_http_requests_total Requests served, by method and status.
+# TYPE config_server_http_requests_total counter
+config_server_http_requests_total{method="GET",status="200"} 2
+config_server_http_requests_total{method="PUT",status="400"} 1
+`))
+	})
+
+	It("writes histograms with cumulative buckets", func() {
+		metrics.Observe(MetricGenerateDuration, 0.02, "password")
+		metrics.Observe(MetricGenerateDuration, 3, "password")
+
+		output := writtenMetrics()
+		Expect(output).To(ContainSubstring("# TYPE config_server_generate_duration_seconds histogram\n"))
+		Expect(output).To(ContainSubstring(`config_server_generate_duration_seconds_bucket{type="password",le="0.01"} 0` + "\n"))
+		Expect(output).To(ContainSubstring(`config_server_generate_duration_seconds_bucket{type="password",le="0.025"} 1` + "\n"))
+		Expect(output).To(ContainSubstring(`config_server_generate_duration_seconds_bucket{type="password",le="2.5"} 1` + "\n"))
+		Expect(output).To(ContainSubstring(`config_server_generate_duration_seconds_bucket{type="password",le="5"} 2` + "\n"))
+		Expect(output).To(ContainSubstring(`config_server_generate_duration_seconds_bucket{type="password",le="+Inf"} 2` + "\n"))
+		Expect(output).To(ContainSubstring(`config_server_generate_duration_seconds_sum{type="password"} 3.02` + "\n"))
+		Expect(output).To(ContainSubstring(`config_server_generate_duration_seconds_count{type="password"} 2` + "\n"))
+	})
+
+	It("escapes label values", func() {
+		metrics.Add(MetricStoreErrors, 1, "odd\"backend\\\n", "get")
+
+		Expect(writtenMetrics()).To(ContainSubstring(`config_server_store_errors_total{backend="odd\"backend\\\n",operation="get"} 1`))
+	})
+
+	It("ignores unknown metrics and wrong numbers of labels", func() {
+		metrics.Add("unknown_total", 1)
+		metrics.Add(MetricHTTPRequests, 1, "GET")
+
+		Expect(writtenMetrics()).ToNot(ContainSubstring("unknown_total"))
+		Expect(writtenMetrics()).ToNot(ContainSubstring("config_server_http_requests_total{"))
+	})
+
+	It("runs collectors before writing", func() {
+		collected := 0
+		metrics.AddCollector(func() {
+			collected++
+			metrics.Set(MetricDBOpenConnections, float64(collected), "postgres")
+		})
+
+		Expect(writtenMetrics()).To(ContainSubstring(`config_server_db_open_connections{backend="postgres"} 1` + "\n"))
+		Expect(writtenMetrics()).To(ContainSubstring(`config_server_db_open_connections{backend="postgres"} 2` + "\n"))
+	})
+
+	Describe("StoreObserver", func() {
+		It("records latency and errors other than failed preconditions", func() {
+			observe := NewStoreObserver(metrics, "postgres")
+			observe("put", 10*time.Millisecond, nil)
+			observe("put", 10*time.Millisecond, errors.New("Kaboom!"))
+			observe("put_if_latest", 10*time.Millisecond, store.ErrPreconditionFailed)
+
+			output := writtenMetrics()
+			Expect(output).To(ContainSubstring(`config_server_store_operation_duration_seconds_count{backend="postgres",operation="put"} 2` + "\n"))
+			Expect(output).To(ContainSubstring(`config_server_store_operation_duration_seconds_count{backend="postgres",operation="put_if_latest"} 1` + "\n"))
+			Expect(output).To(ContainSubstring(`config_server_store_errors_total{backend="postgres",operation="put"} 1` + "\n"))
+			Expect(output).ToNot(ContainSubstring(`config_server_store_errors_total{backend="postgres",operation="put_if_latest"}`))
+		})
+	})
+
+	Describe("DBStatsCollector", func() {
+		It("records the statistics of the connection pool", func() {
+			metrics.AddCollector(NewDBStatsCollector(metrics, "mysql", fakeDBStatsReporter{stats: sql.DBStats{
+				MaxOpenConnections: 10,
+				OpenConnections:    3,
+				InUse:              1,
+				Idle:               2,
+				WaitCount:          4,
+				WaitDuration:       1500 * time.Millisecond,
+			}}))
+
+			output := writtenMetrics()
+			Expect(output).To(ContainSubstring(`config_server_db_max_open_connections{backend="mysql"} 10` + "\n"))
+			Expect(output).To(ContainSubstring(`config_server_db_open_connections{backend="mysql"} 3` + "\n"))
+			Expect(output).To(ContainSubstring(`config_server_db_in_use_connections{backend="mysql"} 1` + "\n"))
+			Expect(output).To(ContainSubstring(`config_server_db_idle_connections{backend="mysql"} 2` + "\n"))
+			Expect(output).To(ContainSubstring(`config_server_db_waits_total{backend="mysql"} 4` + "\n"))
+			Expect(output).To(ContainSubstring(`config_server_db_wait_duration_seconds_total{backend="mysql"} 1.5` + "\n"))
+		})
+
+		It("records nothing when statistics are not available", func() {
+			metrics.AddCollector(NewDBStatsCollector(metrics, "mysql", fakeDBStatsReporter{err: errors.New("Database not initialized")}))
+
+			Expect(writtenMetrics()).ToNot(ContainSubstring(`{backend="mysql"}`))
+		})
+	})
+
+	Describe("MetricsHandler", func() {
+		It("responds with metrics in the text exposition format", func() {
+			metrics.Add(MetricHTTPRequests, 1, "GET", "200")
+
+			req, _ := http.NewRequest("GET", "/metrics", nil)
+			recorder := httptest.NewRecorder()
+			NewMetricsHandler(metrics).ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("text/plain; version=0.0.4"))
+			Expect(recorder.Body.String()).To(ContainSubstring(`config_server_http_requests_total{method="GET",status="200"} 1` + "\n"))
+		})
+
+		It("should return 405 Method Not Allowed for methods other than GET", func() {
+			req, _ := http.NewRequest("POST", "/metrics", nil)
+			recorder := httptest.NewRecorder()
+			NewMetricsHandler(metrics).ServeHTTP(recorder, req)
+
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+	})
+
+	Describe("RequestMetricsHandler", func() {
+		It("counts and times requests by method and status", func() {
+			nextHandler := &FakeHandler{}
+			nextHandler.ServeHTTPStub = func(resWriter http.ResponseWriter, req *http.Request) {
+				resWriter.WriteHeader(http.StatusNotFound)
+			}
+			handler := NewRequestMetricsHandler(metrics, nextHandler)
+
+			req, _ := http.NewRequest("GET", "/v1/data?name=missing", nil)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			req, _ = http.NewRequest("PROPFIND", "/v1/data?name=missing", nil)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			output := writtenMetrics()
+			Expect(output).To(ContainSubstring(`config_server_http_requests_total{method="GET",status="404"} 1` + "\n"))
+			Expect(output).To(ContainSubstring(`config_server_http_requests_total{method="other",status="404"} 1` + "\n"))
+			Expect(output).To(ContainSubstring(`config_server_http_request_duration_seconds_count{method="GET",status="404"} 1` + "\n"))
+		})
+
+		It("counts requests without explicit status as 200 OK", func() {
+			handler := NewRequestMetricsHandler(metrics, &FakeHandler{})
+
+			req, _ := http.NewRequest("GET", "/v1/data?name=x", nil)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			Expect(writtenMetrics()).To(ContainSubstring(`config_server_http_requests_total{method="GET",status="200"} 1` + "\n"))
+		})
+	})
+})
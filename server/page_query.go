@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+type pageQuery struct {
+	current bool
+	limit   int
+	offset  int
+}
+
+func readPageQuery(query url.Values) (pageQuery, error) {
+	var page pageQuery
+
+	if current := query.Get("current"); current != "" {
+		isCurrent, err := strconv.ParseBool(current)
+		if err != nil {
+			return page, errors.Error("Query parameter 'current' must be a boolean")
+		}
+		page.current = isCurrent
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		value, err := strconv.Atoi(limit)
+		if err != nil || value <= 0 {
+			return page, errors.Error("Query parameter 'limit' must be a positive integer")
+		}
+		page.limit = value
+	}
+
+	if offset := query.Get("offset"); offset != "" {
+		value, err := strconv.Atoi(offset)
+		if err != nil || value < 0 {
+			return page, errors.Error("Query parameter 'offset' must be a non-negative integer")
+		}
+		if page.limit == 0 {
+			return page, errors.Error("Query parameter 'offset' requires 'limit'")
+		}
+		page.offset = value
+	}
+
+	if page.current && page.limit > 0 {
+		return page, errors.Error("Query parameter 'current' cannot be combined with 'limit' or 'offset'")
+	}
+
+	return page, nil
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"github.com/cloudfoundry/config-server/store"
+)
+
+type permissionAuthorizer struct {
+	store store.PermissionStore
+}
+
+// NewPermissionAuthorizer returns an Authorizer allowing access to names
+// covered by the permissions granted to the caller's actor through the
+// permissions API. A permission for a path covers every name and path under
+// it.
+func NewPermissionAuthorizer(store store.PermissionStore) Authorizer {
+	return permissionAuthorizer{store: store}
+}
+
+func (a permissionAuthorizer) Authorize(identity Identity, access AccessRequest) (bool, error) {
+	if len(access.Names) == 0 && len(access.Paths) == 0 {
+		return false, nil
+	}
+
+	actor := identity.Actor()
+	if actor == "" {
+		return false, nil
+	}
+
+	permissions, err := a.store.GetPermissions(actor)
+	if err != nil {
+		return false, err
+	}
+
+	for _, name := range access.Names {
+		if !anyPermissionCovers(permissions, access.Operation, name, false) {
+			return false, nil
+		}
+	}
+
+	for _, path := range access.Paths {
+		if !anyPermissionCovers(permissions, access.Operation, path, true) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func anyPermissionCovers(permissions []store.Permission, operation string, name string, isPath bool) bool {
+	for _, permission := range permissions {
+		if !containsString(permission.Operations, operation) {
+			continue
+		}
+
+		if permission.Path != "" && pathCovers(permission.Path, name) {
+			return true
+		}
+
+		if !isPath && permission.Name != "" && permission.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package server_test
+
+import (
+	"errors"
+
+	. "github.com/cloudfoundry/config-server/server"
+	"github.com/cloudfoundry/config-server/store"
+	. "github.com/cloudfoundry/config-server/store/storefakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PermissionAuthorizer", func() {
+	var (
+		memoryStore store.MemoryStore
+		authorizer  Authorizer
+	)
+
+	director := Identity{Name: "director", ClientID: "director", Authenticator: AuthenticatorToken}
+
+	BeforeEach(func() {
+		memoryStore = store.NewMemoryStore()
+		authorizer = NewPermissionAuthorizer(memoryStore)
+
+		memoryStore.GrantPermission(store.Permission{Actor: "token:client:director", Name: "/cf/password", Operations: []string{"read", "write"}})
+		memoryStore.GrantPermission(store.Permission{Actor: "token:client:director", Path: "/shared/", Operations: []string{"read"}})
+		memoryStore.GrantPermission(store.Permission{Actor: "token:client:ci", Path: "/", Operations: []string{"read"}})
+		memoryStore.GrantPermission(store.Permission{Actor: "api_key:director", Path: "/", Operations: []string{"delete"}})
+	})
+
+	It("allows names granted to the caller", func() {
+		Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationWrite, Names: []string{"/cf/password"}})).To(BeTrue())
+		Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationDelete, Names: []string{"/cf/password"}})).To(BeFalse())
+		Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationRead, Names: []string{"/cf/other"}})).To(BeFalse())
+	})
+
+	It("allows names and paths under paths granted to the caller", func() {
+		Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationRead, Names: []string{"/shared/nested/key"}})).To(BeTrue())
+		Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationRead, Paths: []string{"/shared/nested/"}})).To(BeTrue())
+		Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationRead, Paths: []string{"/"}})).To(BeFalse())
+	})
+
+	It("only allows names under a path at a segment boundary", func() {
+		memoryStore.GrantPermission(store.Permission{Actor: "token:client:director", Path: "/team", Operations: []string{"read"}})
+
+		Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationRead, Names: []string{"/team"}})).To(BeTrue())
+		Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationRead, Names: []string{"/team/key"}})).To(BeTrue())
+		Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationRead, Names: []string{"/team-b/key"}})).To(BeFalse())
+		Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationRead, Names: []string{"/sharedkey"}})).To(BeFalse())
+	})
+
+	It("does not allow names granted to callers of other authenticators with the same name", func() {
+		Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationDelete, Names: []string{"/cf/password"}})).To(BeFalse())
+		Expect(authorizer.Authorize(Identity{Name: "director", Authenticator: AuthenticatorCertificate}, AccessRequest{Operation: OperationRead, Names: []string{"/cf/password"}})).To(BeFalse())
+		Expect(authorizer.Authorize(Identity{Name: "director", Authenticator: AuthenticatorAPIKey}, AccessRequest{Operation: OperationDelete, Names: []string{"/cf/password"}})).To(BeTrue())
+	})
+
+	It("does not allow names granted to a client to users of the same name", func() {
+		user := Identity{Name: "director", UserName: "director", ClientID: "cf", Authenticator: AuthenticatorToken}
+
+		Expect(authorizer.Authorize(user, AccessRequest{Operation: OperationRead, Names: []string{"/cf/password"}})).To(BeFalse())
+
+		memoryStore.GrantPermission(store.Permission{Actor: "token:user:director", Name: "/cf/password", Operations: []string{"read"}})
+		Expect(authorizer.Authorize(user, AccessRequest{Operation: OperationRead, Names: []string{"/cf/password"}})).To(BeTrue())
+	})
+
+	It("denies requests that name nothing", func() {
+		Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationRead})).To(BeFalse())
+	})
+
+	It("does not allow paths by permissions for a name", func() {
+		Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationRead, Paths: []string{"/cf/password"}})).To(BeFalse())
+	})
+
+	It("denies callers without identity", func() {
+		Expect(authorizer.Authorize(Identity{}, AccessRequest{Operation: OperationRead, Names: []string{"/cf/password"}})).To(BeFalse())
+	})
+
+	It("returns an error when store fails", func() {
+		mockStore := &FakePermissionStore{}
+		mockStore.GetPermissionsReturns(nil, errors.New("Kaboom!"))
+
+		_, err := NewPermissionAuthorizer(mockStore).Authorize(director, AccessRequest{Operation: OperationRead, Names: []string{"/cf/password"}})
+		Expect(err).To(HaveOccurred())
+	})
+
+	Describe("combined with policies", func() {
+		BeforeEach(func() {
+			policyAuthorizer, err := NewPolicyAuthorizer(nil)
+			Expect(err).ToNot(HaveOccurred())
+			authorizer = NewAnyAuthorizers(policyAuthorizer, authorizer)
+		})
+
+		It("allows each name by any authorizer", func() {
+			Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationRead, Names: []string{"/cf/password", "/shared/key"}})).To(BeTrue())
+			Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationRead, Names: []string{"/cf/password", "/diego/key"}})).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cloudfoundry/config-server/store"
+)
+
+type permissionsHandler struct {
+	store      store.PermissionStore
+	authorizer Authorizer
+}
+
+// NewPermissionsHandler returns a handler managing permissions for callers
+// authorizer allows to administer the config server.
+func NewPermissionsHandler(store store.PermissionStore, authorizer Authorizer) (http.Handler, error) {
+	if store == nil {
+		return nil, errors.Error("Permission store must be set")
+	}
+	if authorizer == nil {
+		return nil, errors.Error("Authorizer must be set")
+	}
+	return permissionsHandler{store: store, authorizer: authorizer}, nil
+}
+
+func (handler permissionsHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	if !authorizeRequest(handler.authorizer, resWriter, req, nil, nil) {
+		return
+	}
+
+	switch req.Method {
+	case "GET":
+		handler.handleList(resWriter, req)
+	case "POST":
+		handler.handleGrant(resWriter, req)
+	case "DELETE":
+		handler.handleRevoke(resWriter, req)
+	default:
+		http.Error(resWriter, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	}
+}
+
+func (handler permissionsHandler) handleList(resWriter http.ResponseWriter, req *http.Request) {
+	permissions, err := handler.store.GetPermissions(req.URL.Query().Get("actor"))
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(map[string]interface{}{"permissions": permissions})
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(resWriter, string(bytes), http.StatusOK)
+}
+
+func (handler permissionsHandler) handleGrant(resWriter http.ResponseWriter, req *http.Request) {
+	if contentTypeErr := validateRequestContentType(req); contentTypeErr != nil {
+		http.Error(resWriter, contentTypeErr.Error(), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	permission, err := readGrantRequest(req)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	permission.ID, err = handler.store.GrantPermission(permission)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(permission)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(resWriter, string(bytes), http.StatusOK)
+}
+
+func (handler permissionsHandler) handleRevoke(resWriter http.ResponseWriter, req *http.Request) {
+	id, err := extractIDFromURLPath(req.URL.Path)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	revoked, err := handler.store.RevokePermission(id)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if revoked == 0 {
+		respond(resWriter, "", http.StatusNotFound)
+	} else {
+		respond(resWriter, "", http.StatusNoContent)
+	}
+}
+
+func readGrantRequest(req *http.Request) (store.Permission, error) {
+	var permission store.Permission
+
+	if req.Body == nil {
+		return permission, errors.Error("Request can't be empty")
+	}
+
+	if err := json.NewDecoder(req.Body).Decode(&permission); err != nil {
+		return permission, errors.Error("Request Body should be JSON string")
+	}
+	permission.ID = ""
+
+	if permission.Actor == "" {
+		return permission, errors.Error("JSON request body should contain the key 'actor'")
+	}
+
+	if !IsActor(permission.Actor) {
+		return permission, errors.Error("Actor must be a name prefixed with its authenticator, one of 'token:user:', 'token:client:', 'token:subject:', 'certificate:' and 'api_key:'")
+	}
+
+	if (permission.Name == "") == (permission.Path == "") {
+		return permission, errors.Error("JSON request body should contain exactly one of the keys 'name' and 'path'")
+	}
+
+	if permission.Name != "" {
+		if isNameValid, nameError := isValidName(permission.Name); isNameValid == false {
+			return permission, nameError
+		}
+	} else if isPathValid, pathError := isValidPath(permission.Path); isPathValid == false {
+		return permission, pathError
+	}
+
+	if len(permission.Operations) == 0 {
+		return permission, errors.Error("JSON request body should contain the key 'operations'")
+	}
+
+	for _, operation := range permission.Operations {
+		if !isOperation(operation) {
+			return permission, errors.Error(fmt.Sprintf("Unknown operation '%s'", operation))
+		}
+	}
+
+	return permission, nil
+}
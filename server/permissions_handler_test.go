@@ -0,0 +1,160 @@
+package server_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	. "github.com/cloudfoundry/config-server/server"
+	"github.com/cloudfoundry/config-server/store"
+	. "github.com/cloudfoundry/config-server/store/storefakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PermissionsHandler", func() {
+
+	Describe("Given a nil store", func() {
+		It("should return an error", func() {
+			_, err := NewPermissionsHandler(nil, allowingAuthorizer())
+			Expect(err.Error()).To(Equal("Permission store must be set"))
+		})
+	})
+
+	Describe("Given a handler with store", func() {
+		var permissionsHandler http.Handler
+		var memoryStore store.MemoryStore
+
+		serve := func(method, url, body string) *httptest.ResponseRecorder {
+			req, _ := http.NewRequest(method, url, strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			recorder := httptest.NewRecorder()
+			permissionsHandler.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		BeforeEach(func() {
+			memoryStore = store.NewMemoryStore()
+			permissionsHandler, _ = NewPermissionsHandler(memoryStore, allowingAuthorizer())
+		})
+
+		It("should return 405 Method Not Allowed for methods other than GET, POST and DELETE", func() {
+			recorder := serve("PUT", "/v1/permissions", `{}`)
+
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+
+		Describe("POST", func() {
+			It("grants the permission and responds with it", func() {
+				recorder := serve("POST", "/v1/permissions", `{"actor":"token:client:director","path":"/cf/","operations":["read","write"]}`)
+
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(recorder.Body.String()).To(Equal(`{"id":"1","actor":"token:client:director","path":"/cf/","operations":["read","write"]}`))
+
+				permissions, _ := memoryStore.GetPermissions("token:client:director")
+				Expect(permissions).To(Equal([]store.Permission{{ID: "1", Actor: "token:client:director", Path: "/cf/", Operations: []string{"read", "write"}}}))
+			})
+
+			It("should return 415 Unsupported Media Type for content types other than JSON", func() {
+				req, _ := http.NewRequest("POST", "/v1/permissions", strings.NewReader(`{}`))
+				recorder := httptest.NewRecorder()
+				permissionsHandler.ServeHTTP(recorder, req)
+
+				Expect(recorder.Code).To(Equal(http.StatusUnsupportedMediaType))
+			})
+
+			It("should return 400 Bad Request for invalid permissions", func() {
+				for body, message := range map[string]string{
+					`garbage`: "Request Body should be JSON string",
+					`{"name":"/cf/password","operations":["read"]}`:                                               "JSON request body should contain the key 'actor'",
+					`{"actor":"director","name":"/cf/password","operations":["read"]}`:                            "Actor must be a name prefixed with its authenticator",
+					`{"actor":"token:","name":"/cf/password","operations":["read"]}`:                              "Actor must be a name prefixed with its authenticator",
+					`{"actor":"token:director","name":"/cf/password","operations":["read"]}`:                      "Actor must be a name prefixed with its authenticator",
+					`{"actor":"token:client:","name":"/cf/password","operations":["read"]}`:                       "Actor must be a name prefixed with its authenticator",
+					`{"actor":"token:client:director","operations":["read"]}`:                                     "JSON request body should contain exactly one of the keys 'name' and 'path'",
+					`{"actor":"token:client:director","name":"/cf/password","path":"/cf/","operations":["read"]}`: "JSON request body should contain exactly one of the keys 'name' and 'path'",
+					`{"actor":"token:client:director","name":"/cf/pass word","operations":["read"]}`:              "Name must consist of alphanumeric, underscores, dashes, and forward slashes",
+					`{"actor":"token:client:director","name":"/cf/password"}`:                                     "JSON request body should contain the key 'operations'",
+					`{"actor":"token:client:director","name":"/cf/password","operations":["admin"]}`:              "Unknown operation 'admin'",
+				} {
+					recorder := serve("POST", "/v1/permissions", body)
+
+					Expect(recorder.Code).To(Equal(http.StatusBadRequest), body)
+					Expect(recorder.Body.String()).To(ContainSubstring(message), body)
+				}
+
+				permissions, _ := memoryStore.GetPermissions("")
+				Expect(permissions).To(BeEmpty())
+			})
+		})
+
+		Describe("GET", func() {
+			BeforeEach(func() {
+				memoryStore.GrantPermission(store.Permission{Actor: "token:client:director", Name: "/cf/password", Operations: []string{"read"}})
+				memoryStore.GrantPermission(store.Permission{Actor: "token:client:ci", Path: "/cf/", Operations: []string{"write"}})
+			})
+
+			It("lists every permission", func() {
+				recorder := serve("GET", "/v1/permissions", "")
+
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(recorder.Body.String()).To(Equal(`{"permissions":[{"id":"1","actor":"token:client:director","name":"/cf/password","operations":["read"]},{"id":"2","actor":"token:client:ci","path":"/cf/","operations":["write"]}]}`))
+			})
+
+			It("lists permissions of an actor", func() {
+				recorder := serve("GET", "/v1/permissions?actor=token:client:ci", "")
+
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(recorder.Body.String()).To(Equal(`{"permissions":[{"id":"2","actor":"token:client:ci","path":"/cf/","operations":["write"]}]}`))
+			})
+
+			It("lists no permissions of unknown actors", func() {
+				recorder := serve("GET", "/v1/permissions?actor=nobody", "")
+
+				Expect(recorder.Code).To(Equal(http.StatusOK))
+				Expect(recorder.Body.String()).To(Equal(`{"permissions":[]}`))
+			})
+		})
+
+		Describe("DELETE", func() {
+			It("revokes the permission", func() {
+				id, _ := memoryStore.GrantPermission(store.Permission{Actor: "token:client:director", Name: "/cf/password", Operations: []string{"read"}})
+
+				recorder := serve("DELETE", "/v1/permissions/"+id, "")
+				Expect(recorder.Code).To(Equal(http.StatusNoContent))
+
+				permissions, _ := memoryStore.GetPermissions("")
+				Expect(permissions).To(BeEmpty())
+			})
+
+			It("should return 404 Not Found when permission does not exist", func() {
+				recorder := serve("DELETE", "/v1/permissions/42", "")
+
+				Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			})
+
+			It("should return 400 Bad Request without id", func() {
+				recorder := serve("DELETE", "/v1/permissions", "")
+
+				Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			})
+		})
+
+		Context("when store errors", func() {
+			It("should return 500 Internal Server Error", func() {
+				mockStore := &FakePermissionStore{}
+				mockStore.GetPermissionsReturns(nil, errors.New("Kaboom!"))
+				mockStore.GrantPermissionReturns("", errors.New("Kaboom!"))
+				mockStore.RevokePermissionReturns(0, errors.New("Kaboom!"))
+				permissionsHandler, _ = NewPermissionsHandler(mockStore, allowingAuthorizer())
+
+				Expect(serve("GET", "/v1/permissions", "").Code).To(Equal(http.StatusInternalServerError))
+				Expect(serve("POST", "/v1/permissions", `{"actor":"token:client:director","name":"/cf/password","operations":["read"]}`).Code).To(Equal(http.StatusInternalServerError))
+				Expect(serve("DELETE", "/v1/permissions/1", "").Code).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+})
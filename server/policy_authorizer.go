@@ -0,0 +1,139 @@
+package server
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cloudfoundry/config-server/config"
+)
+
+type compiledPolicy struct {
+	policy     config.Policy
+	patterns   []*regexp.Regexp
+	recursive  []bool
+	operations map[string]bool
+}
+
+type policyAuthorizer struct {
+	policies []compiledPolicy
+}
+
+// NewPolicyAuthorizer returns an Authorizer allowing access to names matched
+// by the policies of the caller. In name patterns '*' matches within one
+// segment of a name and '**' matches across segments. Paths are only allowed
+// by patterns ending with '**', which match every name under them.
+func NewPolicyAuthorizer(policies []config.Policy) (Authorizer, error) {
+	var compiled []compiledPolicy
+
+	for i, policy := range policies {
+		c := compiledPolicy{policy: policy, operations: map[string]bool{}}
+
+		for _, operation := range policy.Operations {
+			if !isOperation(operation) {
+				return nil, errors.Errorf("Policy %d has unknown operation '%s'", i, operation)
+			}
+			c.operations[operation] = true
+		}
+
+		for _, name := range policy.Names {
+			pattern, err := compileNamePattern(name)
+			if err != nil {
+				return nil, errors.WrapErrorf(err, "Policy %d has invalid name pattern '%s'", i, name)
+			}
+			c.patterns = append(c.patterns, pattern)
+			c.recursive = append(c.recursive, strings.HasSuffix(name, "**"))
+		}
+
+		compiled = append(compiled, c)
+	}
+
+	return policyAuthorizer{policies: compiled}, nil
+}
+
+func (a policyAuthorizer) Authorize(identity Identity, access AccessRequest) (bool, error) {
+	// Policies grant access to names, so they never cover a request that
+	// does not name what it accesses
+	if len(access.Names) == 0 && len(access.Paths) == 0 {
+		return false, nil
+	}
+
+	for _, name := range access.Names {
+		if !a.allows(identity, access.Operation, name, false) {
+			return false, nil
+		}
+	}
+
+	for _, path := range access.Paths {
+		if !a.allows(identity, access.Operation, path, true) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (a policyAuthorizer) allows(identity Identity, operation string, name string, isPath bool) bool {
+	for _, policy := range a.policies {
+		if !policy.appliesTo(identity) || !policy.operations[operation] {
+			continue
+		}
+
+		for i, pattern := range policy.patterns {
+			if isPath && !policy.recursive[i] {
+				continue
+			}
+			if pattern.MatchString(name) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// appliesTo returns whether the policy covers the caller. Clients and users
+// are claims of tokens, so certificates and API keys whose identity happens
+// to equal a client ID are not covered by policies for that client.
+func (p compiledPolicy) appliesTo(identity Identity) bool {
+	isToken := identity.Authenticator == AuthenticatorToken
+	if p.policy.Client != "" && (!isToken || p.policy.Client != identity.ClientID) {
+		return false
+	}
+	if p.policy.User != "" && (!isToken || p.policy.User != identity.UserName) {
+		return false
+	}
+	if p.policy.Group != "" && !identity.HasScope(p.policy.Group) {
+		return false
+	}
+	return true
+}
+
+func compileNamePattern(pattern string) (*regexp.Regexp, error) {
+	var expr bytes.Buffer
+	expr.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '*' {
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		} else if i+1 < len(pattern) && pattern[i+1] == '*' {
+			expr.WriteString(".*")
+			i++
+		} else {
+			expr.WriteString("[^/]*")
+		}
+	}
+
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+func isOperation(operation string) bool {
+	switch operation {
+	case OperationRead, OperationWrite, OperationGenerate, OperationDelete:
+		return true
+	default:
+		return false
+	}
+}
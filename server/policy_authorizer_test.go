@@ -0,0 +1,87 @@
+package server_test
+
+import (
+	"github.com/cloudfoundry/config-server/config"
+	. "github.com/cloudfoundry/config-server/server"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PolicyAuthorizer", func() {
+	var authorizer Authorizer
+
+	director := Identity{Name: "director", ClientID: "director", Authenticator: AuthenticatorToken}
+	operator := Identity{Name: "admin", ClientID: "cf", UserName: "admin", Scopes: []string{"operators"}, Authenticator: AuthenticatorToken}
+
+	read := func(names ...string) AccessRequest {
+		return AccessRequest{Operation: OperationRead, Names: names}
+	}
+
+	BeforeEach(func() {
+		var err error
+		authorizer, err = NewPolicyAuthorizer([]config.Policy{
+			{Client: "director", Names: []string{"/cf/*", "/shared/**"}, Operations: []string{"read", "write"}},
+			{Group: "operators", Names: []string{"**"}, Operations: []string{"read"}},
+			{Client: "cf", User: "admin", Names: []string{"/cf/**"}, Operations: []string{"delete"}},
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("matches '*' within one segment of a name", func() {
+		Expect(authorizer.Authorize(director, read("/cf/password"))).To(BeTrue())
+		Expect(authorizer.Authorize(director, read("/cf/nested/password"))).To(BeFalse())
+		Expect(authorizer.Authorize(director, read("/cfx/password"))).To(BeFalse())
+	})
+
+	It("matches '**' across segments of a name", func() {
+		Expect(authorizer.Authorize(director, read("/shared/nested/password"))).To(BeTrue())
+		Expect(authorizer.Authorize(operator, read("/any/name"))).To(BeTrue())
+	})
+
+	It("requires every name to be allowed", func() {
+		Expect(authorizer.Authorize(director, read("/cf/password", "/shared/key"))).To(BeTrue())
+		Expect(authorizer.Authorize(director, read("/cf/password", "/diego/key"))).To(BeFalse())
+	})
+
+	It("requires the operation to be allowed by a matching policy", func() {
+		Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationWrite, Names: []string{"/cf/password"}})).To(BeTrue())
+		Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationDelete, Names: []string{"/cf/password"}})).To(BeFalse())
+		Expect(authorizer.Authorize(operator, AccessRequest{Operation: OperationWrite, Names: []string{"/cf/password"}})).To(BeFalse())
+	})
+
+	It("applies policies only to callers matching all of their client, user and group", func() {
+		deleteCF := AccessRequest{Operation: OperationDelete, Names: []string{"/cf/password"}}
+
+		Expect(authorizer.Authorize(operator, deleteCF)).To(BeTrue())
+		Expect(authorizer.Authorize(Identity{ClientID: "cf", UserName: "someone", Authenticator: AuthenticatorToken}, deleteCF)).To(BeFalse())
+		Expect(authorizer.Authorize(Identity{ClientID: "other", UserName: "admin", Authenticator: AuthenticatorToken}, deleteCF)).To(BeFalse())
+	})
+
+	It("applies client and user policies only to tokens", func() {
+		for _, authenticator := range []string{AuthenticatorCertificate, AuthenticatorAPIKey} {
+			Expect(authorizer.Authorize(Identity{Name: "director", ClientID: "director", Authenticator: authenticator}, read("/cf/password"))).To(BeFalse(), authenticator)
+		}
+	})
+
+	It("allows paths only by patterns ending with '**'", func() {
+		Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationRead, Paths: []string{"/shared/"}})).To(BeTrue())
+		Expect(authorizer.Authorize(director, AccessRequest{Operation: OperationRead, Paths: []string{"/cf/"}})).To(BeFalse())
+	})
+
+	It("denies callers without policies", func() {
+		Expect(authorizer.Authorize(Identity{Name: "someone"}, read("/cf/password"))).To(BeFalse())
+	})
+
+	It("denies requests that name nothing", func() {
+		Expect(authorizer.Authorize(operator, AccessRequest{Operation: OperationRead})).To(BeFalse())
+	})
+
+	It("returns an error for unknown operations", func() {
+		_, err := NewPolicyAuthorizer([]config.Policy{
+			{Client: "director", Names: []string{"**"}, Operations: []string{"read", "admin"}},
+		})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Policy 0 has unknown operation 'admin'"))
+	})
+})
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"net/http"
+)
+
+// authorizeRequest reports whether the caller of req may perform the
+// operation req requires on the names and paths a handler decoded from it,
+// answering the request when it may not. Requests that do not name what they
+// access are denied, except for administrative ones.
+func authorizeRequest(authorizer Authorizer, resWriter http.ResponseWriter, req *http.Request, names []string, paths []string) bool {
+	access := AccessRequest{Operation: requiredOperation(req), Names: names, Paths: paths}
+
+	if access.Operation == "" {
+		http.Error(resWriter, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return false
+	}
+
+	if access.Operation != OperationAdmin && len(access.Names) == 0 && len(access.Paths) == 0 {
+		http.Error(resWriter, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return false
+	}
+
+	allowed, err := authorizer.Authorize(IdentityFromRequest(req), access)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return false
+	}
+
+	if !allowed {
+		http.Error(resWriter, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
+// authorizeUnnamedRequest reports whether the caller of req may perform the
+// operation req requires without accessing any name, such as interpolating a
+// document without placeholders, answering the request when it may not. As
+// nothing stored is disclosed, the scope of the operation is enough.
+func authorizeUnnamedRequest(resWriter http.ResponseWriter, req *http.Request) bool {
+	access := AccessRequest{Operation: requiredOperation(req)}
+
+	if access.Operation == "" {
+		http.Error(resWriter, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return false
+	}
+
+	allowed, _ := NewScopeAuthorizer().Authorize(IdentityFromRequest(req), access)
+	if !allowed {
+		http.Error(resWriter, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
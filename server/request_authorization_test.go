@@ -0,0 +1,256 @@
+package server_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry/config-server/config"
+	. "github.com/cloudfoundry/config-server/server"
+	"github.com/cloudfoundry/config-server/store"
+	. "github.com/cloudfoundry/config-server/store/storefakes"
+	. "github.com/cloudfoundry/config-server/types/typesfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Request authorization", func() {
+	var (
+		dataStore        store.Store
+		memoryStore      store.MemoryStore
+		authorizer       Authorizer
+		generatorFactory *FakeValueGeneratorFactory
+	)
+
+	serveAs := func(identity Identity, method, url, body string) *httptest.ResponseRecorder {
+		requestHandler, err := NewRequestHandler(dataStore, generatorFactory, authorizer)
+		Expect(err).ToNot(HaveOccurred())
+		interpolationHandler, err := NewInterpolationHandler(dataStore, authorizer)
+		Expect(err).ToNot(HaveOccurred())
+		undeleteHandler, err := NewUndeleteHandler(dataStore, time.Hour, authorizer)
+		Expect(err).ToNot(HaveOccurred())
+
+		mux := http.NewServeMux()
+		mux.Handle("/v1/data", requestHandler)
+		mux.Handle("/v1/data/", requestHandler)
+		mux.Handle("/v1/interpolate", interpolationHandler)
+		mux.Handle("/v1/undelete", undeleteHandler)
+		mux.Handle("/v1/watch", NewWatchHandler(NewEventBroker(), authorizer))
+
+		req, _ := http.NewRequest(method, url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req = WithIdentity(req, identity)
+
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	serve := func(method, url, body string, scopes ...string) *httptest.ResponseRecorder {
+		return serveAs(Identity{Name: "someone", Scopes: scopes}, method, url, body)
+	}
+
+	BeforeEach(func() {
+		memoryStore = store.NewMemoryStore()
+		dataStore = memoryStore
+		authorizer = NewScopeAuthorizer()
+
+		generator := &FakeValueGenerator{}
+		generator.GenerateReturns("generated", nil)
+		generatorFactory = &FakeValueGeneratorFactory{}
+		generatorFactory.GetGeneratorReturns(generator, nil)
+
+		memoryStore.Put("/cf/password", `{"value":"secret"}`, store.Metadata{})
+	})
+
+	It("requires the scope of the operation a request performs", func() {
+		for _, request := range []struct{ method, url, body, scope string }{
+			{"GET", "/v1/data?name=/cf/password", "", "config_server.read"},
+			{"GET", "/v1/data?path=/cf/", "", "config_server.read"},
+			{"GET", "/v1/data/0", "", "config_server.read"},
+			{"POST", "/v1/interpolate", `{"a":"((/cf/password))"}`, "config_server.read"},
+			{"PUT", "/v1/data", `{"name":"/cf/password","value":"other"}`, "config_server.write"},
+			{"POST", "/v1/data/0/restore", "", "config_server.write"},
+			{"POST", "/v1/undelete?name=/cf/password", "", "config_server.write"},
+			{"POST", "/v1/data", `{"name":"/cf/password","type":"password"}`, "config_server.generate"},
+			{"DELETE", "/v1/data/0", "", "config_server.delete"},
+			{"DELETE", "/v1/data?name=/cf/password", "", "config_server.delete"},
+		} {
+			for _, scope := range []string{"config_server.read", "config_server.write", "config_server.generate", "config_server.delete"} {
+				recorder := serve(request.method, request.url, request.body, scope)
+
+				if scope == request.scope {
+					Expect(recorder.Code).ToNot(Equal(http.StatusForbidden), "%s %s with %s", request.method, request.url, scope)
+				} else {
+					Expect(recorder.Code).To(Equal(http.StatusForbidden), "%s %s with %s", request.method, request.url, scope)
+				}
+			}
+		}
+	})
+
+	It("allows every operation with the admin scope", func() {
+		Expect(serve("GET", "/v1/data?name=/cf/password", "", AdminScope).Code).To(Equal(http.StatusOK))
+		Expect(serve("PUT", "/v1/data", `{"name":"/cf/password","value":"other"}`, AdminScope).Code).To(Equal(http.StatusOK))
+		Expect(serve("POST", "/v1/data", `{"name":"/cf/other","type":"password"}`, AdminScope).Code).To(Equal(http.StatusCreated))
+		Expect(serve("DELETE", "/v1/data?name=/cf/password", "", AdminScope).Code).To(Equal(http.StatusNoContent))
+	})
+
+	It("requires the admin scope to manage permissions", func() {
+		permissionsHandler, err := NewPermissionsHandler(memoryStore, NewScopeAuthorizer())
+		Expect(err).ToNot(HaveOccurred())
+
+		servePermissions := func(method string, scope string) int {
+			req, _ := http.NewRequest(method, "/v1/permissions/1", strings.NewReader(`{"actor":"token:client:ci","name":"/cf/password","operations":["read"]}`))
+			req.Header.Set("Content-Type", "application/json")
+			req = WithIdentity(req, Identity{Name: "someone", Scopes: []string{scope}})
+
+			recorder := httptest.NewRecorder()
+			permissionsHandler.ServeHTTP(recorder, req)
+			return recorder.Code
+		}
+
+		for _, method := range []string{"GET", "POST", "DELETE"} {
+			for _, scope := range []string{"config_server.read", "config_server.write", "config_server.generate", "config_server.delete"} {
+				Expect(servePermissions(method, scope)).To(Equal(http.StatusForbidden))
+			}
+			Expect(servePermissions(method, AdminScope)).ToNot(Equal(http.StatusForbidden))
+		}
+	})
+
+	It("should return 403 Forbidden without identity", func() {
+		Expect(serveAs(Identity{}, "GET", "/v1/data/0", "").Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("should return 405 Method Not Allowed for methods without a required operation", func() {
+		for _, url := range []string{"/v1/data", "/v1/data/0"} {
+			Expect(serve("PATCH", url, "", AdminScope).Code).To(Equal(http.StatusMethodNotAllowed), url)
+		}
+	})
+
+	It("should return 403 Forbidden when a request names nothing", func() {
+		fakeAuthorizer := allowingAuthorizer()
+		authorizer = fakeAuthorizer
+
+		Expect(serve("POST", "/v1/interpolate", `{"a":"b"}`).Code).To(Equal(http.StatusForbidden))
+		Expect(serve("POST", "/v1/interpolate", `{"a":"b"}`, "config_server.write").Code).To(Equal(http.StatusForbidden))
+		Expect(fakeAuthorizer.AuthorizeCallCount()).To(Equal(0))
+	})
+
+	It("allows interpolating documents without placeholders with the read or admin scope", func() {
+		fakeAuthorizer := allowingAuthorizer()
+		authorizer = fakeAuthorizer
+
+		for _, scope := range []string{"config_server.read", AdminScope} {
+			recorder := serve("POST", "/v1/interpolate", `{"a":"plain"}`, scope)
+			Expect(recorder.Code).To(Equal(http.StatusOK), scope)
+			Expect(recorder.Body.String()).To(MatchJSON(`{"a":"plain"}`))
+		}
+		Expect(fakeAuthorizer.AuthorizeCallCount()).To(Equal(0))
+	})
+
+	It("authorizes the names a handler decoded with the operation of the request", func() {
+		fakeAuthorizer := allowingAuthorizer()
+		authorizer = fakeAuthorizer
+
+		serve("POST", "/v1/data", `{"variables":[
+			{"name":"/cf/cert","type":"certificate","options":{"ca":"/cf/ca"}},
+			{"name":"/cf/ca","type":"certificate","options":{"is_ca":true}}
+		]}`)
+
+		identity, access := fakeAuthorizer.AuthorizeArgsForCall(0)
+		Expect(identity.Name).To(Equal("someone"))
+		Expect(access).To(Equal(AccessRequest{Operation: OperationGenerate, Names: []string{"/cf/cert", "/cf/ca", "/cf/ca"}}))
+	})
+
+	It("lists only names under a permitted path, matching whole segments", func() {
+		memoryStore.Put("/team/secret", `{"value":"team"}`, store.Metadata{})
+		memoryStore.Put("/team-b/secret", `{"value":"other"}`, store.Metadata{})
+		memoryStore.GrantPermission(store.Permission{Actor: "token:client:ci", Path: "/team", Operations: []string{"read"}})
+		authorizer = NewAnyAuthorizers(NewScopeAuthorizer(), NewPermissionAuthorizer(memoryStore))
+
+		recorder := serveAs(Identity{Name: "ci", ClientID: "ci", Authenticator: AuthenticatorToken}, "GET", "/v1/data?path=/team", "")
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(ContainSubstring(`"/team/secret"`))
+		Expect(recorder.Body.String()).ToNot(ContainSubstring("team-b"))
+	})
+
+	Context("with access policies", func() {
+		director := Identity{Name: "director", ClientID: "director", Scopes: []string{AdminScope}, Authenticator: AuthenticatorToken}
+
+		BeforeEach(func() {
+			policyAuthorizer, err := NewPolicyAuthorizer([]config.Policy{
+				{Client: "director", Names: []string{"/cf/**"}, Operations: []string{"read", "write", "delete"}},
+				{Client: "director", Names: []string{"/cf/certs/**"}, Operations: []string{"generate"}},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			authorizer = NewAllAuthorizers(NewScopeAuthorizer(), policyAuthorizer)
+
+			memoryStore.Put("/diego/password", `{"value":"secret"}`, store.Metadata{})
+		})
+
+		It("allows names matching a policy of the caller", func() {
+			Expect(serveAs(director, "GET", "/v1/data?name=/cf/password", "").Code).To(Equal(http.StatusOK))
+			Expect(serveAs(director, "PUT", "/v1/data", `{"name":"/cf/password","value":"secret"}`).Code).To(Equal(http.StatusOK))
+			Expect(serveAs(director, "GET", "/v1/data?path=/cf/", "").Code).To(Equal(http.StatusOK))
+		})
+
+		It("should return 403 Forbidden for names not matching a policy of the caller", func() {
+			Expect(serveAs(director, "GET", "/v1/data?name=/diego/password", "").Code).To(Equal(http.StatusForbidden))
+			Expect(serveAs(director, "GET", "/v1/data?path=/", "").Code).To(Equal(http.StatusForbidden))
+			Expect(serveAs(director, "GET", "/v1/watch?path=/", "").Code).To(Equal(http.StatusForbidden))
+			Expect(serveAs(director, "POST", "/v1/data", `{"name":"/cf/password","type":"password"}`).Code).To(Equal(http.StatusForbidden))
+			Expect(serveAs(director, "POST", "/v1/interpolate", `{"a":"((/cf/password))","b":"((/diego/password))"}`).Code).To(Equal(http.StatusForbidden))
+		})
+
+		It("checks the names of versions addressed by ID", func() {
+			Expect(serveAs(director, "GET", "/v1/data/0", "").Code).To(Equal(http.StatusOK))
+			Expect(serveAs(director, "DELETE", "/v1/data/1", "").Code).To(Equal(http.StatusForbidden))
+			Expect(serveAs(director, "POST", "/v1/data/1/restore", "").Code).To(Equal(http.StatusForbidden))
+
+			Expect(memoryStore.GetByName("/diego/password")).To(HaveLen(1))
+		})
+
+		It("checks placeholders as they are parsed from the document", func() {
+			recorder := serveAs(director, "POST", "/v1/interpolate", `{"a":"((/diego/password))"}`)
+
+			Expect(recorder.Code).To(Equal(http.StatusForbidden))
+			Expect(recorder.Body.String()).ToNot(ContainSubstring("secret"))
+		})
+
+		It("checks the CAs certificates are signed with", func() {
+			Expect(serveAs(director, "POST", "/v1/data", `{"name":"/cf/certs/a","type":"certificate","parameters":{"ca":"/diego/ca"}}`).Code).To(Equal(http.StatusForbidden))
+			Expect(serveAs(director, "POST", "/v1/data", `{"variables":[{"name":"/cf/certs/a","type":"certificate","options":{"ca":"/diego/ca"}}]}`).Code).To(Equal(http.StatusForbidden))
+			Expect(serveAs(director, "POST", "/v1/data", `{"name":"/cf/certs/a","type":"certificate","parameters":{"ca":5}}`).Code).To(Equal(http.StatusBadRequest))
+
+			Expect(serveAs(director, "POST", "/v1/data", `{"name":"/cf/certs/a","type":"certificate","parameters":{"ca":"/cf/certs/ca"}}`).Code).To(Equal(http.StatusCreated))
+		})
+
+		It("does not serve requests whose names cannot be decoded", func() {
+			recorder := serveAs(director, "POST", "/v1/data", `{"name":"/diego/other","type":"password","variables":5}`)
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(memoryStore.GetByName("/diego/other")).To(BeEmpty())
+		})
+
+		It("should return 500 Internal Server Error when authorizer fails", func() {
+			mockStore := &FakePermissionStore{}
+			mockStore.GetPermissionsReturns(nil, errors.New("Kaboom!"))
+			authorizer = NewAnyAuthorizers(NewPermissionAuthorizer(mockStore))
+
+			Expect(serveAs(director, "GET", "/v1/data?name=/cf/password", "").Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	It("should return 500 Internal Server Error when the store fails to find a version", func() {
+		mockStore := &FakeStore{}
+		mockStore.GetByIDReturns(store.Configuration{}, errors.New("Kaboom!"))
+		dataStore = mockStore
+
+		Expect(serve("GET", "/v1/data/1", "", AdminScope).Code).To(Equal(http.StatusInternalServerError))
+	})
+})
@@ -5,6 +5,8 @@ import (
 	"github.com/cloudfoundry/config-server/store"
 	"github.com/cloudfoundry/config-server/types"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 
 	"fmt"
@@ -15,15 +17,20 @@ import (
 type requestHandler struct {
 	store                 store.Store
 	valueGeneratorFactory types.ValueGeneratorFactory
+	authorizer            Authorizer
 }
 
-func NewRequestHandler(store store.Store, valueGeneratorFactory types.ValueGeneratorFactory) (http.Handler, error) {
+func NewRequestHandler(store store.Store, valueGeneratorFactory types.ValueGeneratorFactory, authorizer Authorizer) (http.Handler, error) {
 	if store == nil {
 		return nil, errors.Error("Data store must be set")
 	}
+	if authorizer == nil {
+		return nil, errors.Error("Authorizer must be set")
+	}
 	return requestHandler{
-		store: store,
+		store:                 store,
 		valueGeneratorFactory: valueGeneratorFactory,
+		authorizer:            authorizer,
 	}, nil
 }
 
@@ -34,7 +41,11 @@ func (handler requestHandler) ServeHTTP(resWriter http.ResponseWriter, req *http
 	case "PUT":
 		handler.handlePut(resWriter, req)
 	case "POST":
-		handler.handlePost(resWriter, req)
+		if id, isRestore := extractRestoreIDFromURLPath(req.URL.Path); isRestore {
+			handler.handleRestore(id, resWriter, req)
+		} else {
+			handler.handlePost(resWriter, req)
+		}
 	case "DELETE":
 		handler.handleDelete(resWriter, req)
 	default:
@@ -45,18 +56,23 @@ func (handler requestHandler) ServeHTTP(resWriter http.ResponseWriter, req *http
 func (handler requestHandler) handleGet(resWriter http.ResponseWriter, req *http.Request) {
 	id, idErr := extractIDFromURLPath(req.URL.Path)
 	if idErr == nil {
-		handler.handleGetByID(id, resWriter)
+		handler.handleGetByID(id, resWriter, req)
 	} else {
-		name := req.URL.Query().Get("name")
-		if len(name) == 0 {
+		query := req.URL.Query()
+		name := query.Get("name")
+		path := query.Get("path")
+
+		if len(path) != 0 {
+			handler.handleGetByPath(path, query, resWriter, req)
+		} else if len(name) == 0 {
 			http.Error(resWriter, idErr.Error(), http.StatusBadRequest)
 		} else {
-			handler.handleGetByName(name, resWriter)
+			handler.handleGetByName(name, query, resWriter, req)
 		}
 	}
 }
 
-func (handler requestHandler) handleGetByID(id string, resWriter http.ResponseWriter) {
+func (handler requestHandler) handleGetByID(id string, resWriter http.ResponseWriter, req *http.Request) {
 
 	value, err := handler.store.GetByID(id)
 
@@ -69,20 +85,49 @@ func (handler requestHandler) handleGetByID(id string, resWriter http.ResponseWr
 
 	if value == emptyValue {
 		http.Error(resWriter, http.StatusText(http.StatusNotFound), http.StatusNotFound)
-	} else {
-		result, _ := value.StringifiedJSON()
-		respond(resWriter, result, http.StatusOK)
+		return
+	}
+
+	if !authorizeRequest(handler.authorizer, resWriter, req, []string{value.Name}, nil) {
+		return
 	}
+
+	result, _ := value.StringifiedJSON()
+	respond(resWriter, result, http.StatusOK)
 }
 
-func (handler requestHandler) handleGetByName(name string, resWriter http.ResponseWriter) {
+func (handler requestHandler) handleGetByName(name string, query url.Values, resWriter http.ResponseWriter, req *http.Request) {
 
 	if isNameValid, nameError := isValidName(name); isNameValid == false {
 		http.Error(resWriter, nameError.Error(), http.StatusBadRequest)
 		return
 	}
 
-	values, err := handler.store.GetByName(name)
+	if !authorizeRequest(handler.authorizer, resWriter, req, []string{name}, nil) {
+		return
+	}
+
+	page, err := readPageQuery(query)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var values store.Configurations
+
+	switch {
+	case page.current:
+		var value store.Configuration
+		value, err = handler.store.GetCurrentByName(name)
+		if err == nil && value != (store.Configuration{}) {
+			values = store.Configurations{value}
+		}
+	case page.limit > 0:
+		values, err = handler.store.GetPageByName(name, page.limit, page.offset)
+	default:
+		values, err = handler.store.GetByName(name)
+	}
+
 	if err != nil {
 		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
 		return
@@ -98,23 +143,91 @@ func (handler requestHandler) handleGetByName(name string, resWriter http.Respon
 	}
 }
 
+func (handler requestHandler) handleGetByPath(path string, query url.Values, resWriter http.ResponseWriter, req *http.Request) {
+
+	if isPathValid, pathError := isValidPath(path); isPathValid == false {
+		http.Error(resWriter, pathError.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !authorizeRequest(handler.authorizer, resWriter, req, nil, []string{path}) {
+		return
+	}
+
+	includeValues := false
+	if values := query.Get("values"); values != "" {
+		var err error
+		includeValues, err = strconv.ParseBool(values)
+		if err != nil {
+			http.Error(resWriter, "Query parameter 'values' must be a boolean", http.StatusBadRequest)
+			return
+		}
+	}
+
+	listed, err := handler.store.GetByPath(path)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// Only names covered by the path were authorized, whatever the store
+	// returns
+	var configurations store.Configurations
+	for _, configuration := range listed {
+		if pathCovers(path, configuration.Name) {
+			configurations = append(configurations, configuration)
+		}
+	}
+
+	var result string
+	if includeValues {
+		result, err = configurations.StringifiedJSON()
+	} else {
+		result, err = stringifiedNames(configurations)
+	}
+
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(resWriter, result, http.StatusOK)
+}
+
 func (handler requestHandler) handlePut(resWriter http.ResponseWriter, req *http.Request) {
 	if contentTypeErr := validateRequestContentType(req); contentTypeErr != nil {
 		http.Error(resWriter, contentTypeErr.Error(), http.StatusUnsupportedMediaType)
 		return
 	}
 
-	name, value, err := readPutRequest(req)
+	jsonMap, err := readJSONBody(req)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	name, value, err := readPutRequest(jsonMap)
 
 	if err != nil {
 		http.Error(resWriter, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	configuration, err := handler.saveToStore(name, value)
+	if !authorizeRequest(handler.authorizer, resWriter, req, []string{name}, nil) {
+		return
+	}
 
+	expectedID, err := readExpectedID(req, jsonMap)
 	if err != nil {
-		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		http.Error(resWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	metadata := store.Metadata{CreatedBy: IdentityFromRequest(req).Name, Type: "json"}
+	configuration, err := handler.saveToStore(name, map[string]interface{}{"value": value}, expectedID, metadata)
+
+	if err != nil {
+		http.Error(resWriter, err.Error(), writeErrorStatus(err))
 		return
 	}
 	result, _ := configuration.StringifiedJSON()
@@ -127,21 +240,70 @@ func (handler requestHandler) handlePost(resWriter http.ResponseWriter, req *htt
 		return
 	}
 
-	name, generatorType, parameters, err := readPostRequest(req)
+	jsonMap, err := readJSONBody(req)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if _, isBulk := jsonMap["variables"]; isBulk {
+		handler.handleBulkPost(resWriter, req, jsonMap)
+		return
+	}
+
+	name, generatorType, parameters, err := readPostRequest(jsonMap)
+
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	caName, err := readCAName(generatorType, parameters)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	names := []string{name}
+	if caName != "" {
+		names = append(names, caName)
+	}
 
+	if !authorizeRequest(handler.authorizer, resWriter, req, names, nil) {
+		return
+	}
+
+	mode, err := readGenerationMode(jsonMap)
 	if err != nil {
 		http.Error(resWriter, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	values, err := handler.store.GetByName(name)
+	expectedID, err := readExpectedID(req, jsonMap)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	value, err := handler.store.GetCurrentByName(name)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if expectedID != "" && value.ID != expectedID {
+		http.Error(resWriter, store.ErrPreconditionFailed.Error(), http.StatusPreconditionFailed)
+		return
+	}
+
+	generate, err := mode.needsGeneration(value, generatorType, parameters)
 	if err != nil {
 		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	if len(values) != 0 {
-		result, err := values[0].StringifiedJSON()
+	if !generate {
+		result, err := value.StringifiedJSON()
 		if err != nil {
 			http.Error(resWriter, err.Error(), http.StatusInternalServerError)
 		} else {
@@ -155,30 +317,159 @@ func (handler requestHandler) handlePost(resWriter http.ResponseWriter, req *htt
 			return
 		}
 
-		generatedValue, err := generator.Generate(parameters)
+		configuration, err := handler.generateValue(name, generatorType, generator, parameters, expectedID, IdentityFromRequest(req).Name)
+		if err != nil {
+			http.Error(resWriter, err.Error(), writeErrorStatus(err))
+			return
+		}
+
+		result, _ := configuration.StringifiedJSON()
+		respond(resWriter, result, http.StatusCreated)
+	}
+}
+
+func (handler requestHandler) handleBulkPost(resWriter http.ResponseWriter, req *http.Request, jsonMap map[string]interface{}) {
+	definitions, err := readVariableDefinitions(jsonMap)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var names []string
+	for _, definition := range definitions {
+		names = append(names, definition.Name)
+		if caName := definition.caName(); caName != "" {
+			names = append(names, caName)
+		}
+	}
+
+	if !authorizeRequest(handler.authorizer, resWriter, req, names, nil) {
+		return
+	}
+
+	generators := map[string]types.ValueGenerator{}
+	for _, definition := range definitions {
+		generator, err := handler.valueGeneratorFactory.GetGenerator(definition.Type)
+		if err != nil {
+			http.Error(resWriter, err.Error(), http.StatusBadRequest)
+			return
+		}
+		generators[definition.Name] = generator
+	}
+
+	orderedDefinitions, err := orderVariableDefinitions(definitions)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	configurations := map[string]store.Configuration{}
+	status := http.StatusOK
+
+	for _, definition := range orderedDefinitions {
+		value, err := handler.store.GetCurrentByName(definition.Name)
 		if err != nil {
 			http.Error(resWriter, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		configuration, err := handler.saveToStore(name, generatedValue)
+		generate, err := definition.Mode.needsGeneration(value, definition.Type, definition.Options)
 		if err != nil {
 			http.Error(resWriter, err.Error(), http.StatusInternalServerError)
 			return
 		}
 
-		result, _ := configuration.StringifiedJSON()
-		respond(resWriter, result, http.StatusCreated)
+		if !generate {
+			configurations[definition.Name] = value
+			continue
+		}
+
+		configuration, err := handler.generateValue(definition.Name, definition.Type, generators[definition.Name], definition.Options, "", IdentityFromRequest(req).Name)
+		if err != nil {
+			http.Error(resWriter, fmt.Sprintf("Failed to generate value for '%s': %s", definition.Name, err.Error()), writeErrorStatus(err))
+			return
+		}
+
+		configurations[definition.Name] = configuration
+		status = http.StatusCreated
+	}
+
+	var results store.Configurations
+	for _, definition := range definitions {
+		results = append(results, configurations[definition.Name])
 	}
+
+	result, err := results.StringifiedJSON()
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(resWriter, result, status)
+}
+
+// handleRestore appends a copy of the version with the given id as the latest
+// version of its name, recording the id it was restored from.
+func (handler requestHandler) handleRestore(id string, resWriter http.ResponseWriter, req *http.Request) {
+	expectedID, err := readExpectedID(req, nil)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	previous, err := handler.store.GetByID(id)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if previous == (store.Configuration{}) {
+		http.Error(resWriter, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	if !authorizeRequest(handler.authorizer, resWriter, req, []string{previous.Name}, nil) {
+		return
+	}
+
+	var configValue map[string]interface{}
+	if err := json.Unmarshal([]byte(previous.Value), &configValue); err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	metadata := store.Metadata{
+		CreatedBy:    IdentityFromRequest(req).Name,
+		Type:         previous.Type,
+		Parameters:   previous.Parameters,
+		RestoredFrom: previous.ID,
+	}
+	configuration, err := handler.saveToStore(previous.Name, configValue, expectedID, metadata)
+	if err != nil {
+		http.Error(resWriter, err.Error(), writeErrorStatus(err))
+		return
+	}
+
+	result, _ := configuration.StringifiedJSON()
+	respond(resWriter, result, http.StatusCreated)
 }
 
 func (handler requestHandler) handleDelete(resWriter http.ResponseWriter, req *http.Request) {
+	if id, idErr := extractIDFromURLPath(req.URL.Path); idErr == nil {
+		handler.handleDeleteByID(id, resWriter, req)
+		return
+	}
+
 	name := req.URL.Query().Get("name")
 	if isNameValid, nameError := isValidName(name); isNameValid == false {
 		http.Error(resWriter, nameError.Error(), http.StatusBadRequest)
 		return
 	}
 
+	if !authorizeRequest(handler.authorizer, resWriter, req, []string{name}, nil) {
+		return
+	}
+
 	deleted, err := handler.store.Delete(name)
 
 	if err == nil {
@@ -192,17 +483,74 @@ func (handler requestHandler) handleDelete(resWriter http.ResponseWriter, req *h
 	}
 }
 
-func (handler requestHandler) saveToStore(name string, value interface{}) (store.Configuration, error) {
-	configValue := make(map[string]interface{})
-	configValue["value"] = value
+// handleDeleteByID removes a single version. Versions of deleted names cannot
+// be addressed by ID; they are removed once the retention period is over.
+func (handler requestHandler) handleDeleteByID(id string, resWriter http.ResponseWriter, req *http.Request) {
+	configuration, err := handler.store.GetByID(id)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
+	if configuration == (store.Configuration{}) {
+		http.Error(resWriter, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	if !authorizeRequest(handler.authorizer, resWriter, req, []string{configuration.Name}, nil) {
+		return
+	}
+
+	deleted, err := handler.store.DeleteByID(id)
+
+	if err == nil {
+		if deleted == 0 {
+			respond(resWriter, "", http.StatusNotFound)
+		} else {
+			respond(resWriter, "", http.StatusNoContent)
+		}
+	} else {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (handler requestHandler) generateValue(name string, generatorType string, generator types.ValueGenerator, parameters interface{}, expectedID string, createdBy string) (store.Configuration, error) {
+	generatedValue, err := generator.Generate(parameters)
+	if err != nil {
+		return store.Configuration{}, err
+	}
+
+	metadata := store.Metadata{CreatedBy: createdBy, Type: generatorType}
+	if parameters != nil {
+		bytes, err := json.Marshal(parameters)
+		if err != nil {
+			return store.Configuration{}, err
+		}
+		metadata.Parameters = string(bytes)
+	}
+
+	configValue := map[string]interface{}{
+		"value": generatedValue,
+	}
+
+	return handler.saveToStore(name, configValue, expectedID, metadata)
+}
+
+// saveToStore appends a new version under name. When expectedID is set the
+// version is only appended if the latest version has that ID.
+func (handler requestHandler) saveToStore(name string, configValue map[string]interface{}, expectedID string, metadata store.Metadata) (store.Configuration, error) {
 	bytes, err := json.Marshal(&configValue)
 
 	if err != nil {
 		return store.Configuration{}, err
 	}
 
-	id, err := handler.store.Put(name, string(bytes))
+	var id string
+	if expectedID == "" {
+		id, err = handler.store.Put(name, string(bytes), metadata)
+	} else {
+		id, err = handler.store.PutIfLatest(name, string(bytes), expectedID, metadata)
+	}
 	if err != nil {
 		return store.Configuration{}, err
 	}
@@ -211,6 +559,36 @@ func (handler requestHandler) saveToStore(name string, value interface{}) (store
 	return configuration, err
 }
 
+// writeErrorStatus returns the status of the response to a write that failed
+// with err
+func writeErrorStatus(err error) int {
+	switch err {
+	case store.ErrPreconditionFailed:
+		return http.StatusPreconditionFailed
+	case store.ErrNameReserved:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// stringifiedNames lists configurations without their values
+func stringifiedNames(configurations store.Configurations) (string, error) {
+	type nameEntry struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+
+	entries := []nameEntry{}
+	for _, configuration := range configurations {
+		entries = append(entries, nameEntry{ID: configuration.ID, Name: configuration.Name})
+	}
+
+	bytes, err := json.Marshal(map[string]interface{}{"data": entries})
+
+	return string(bytes), err
+}
+
 func respond(res http.ResponseWriter, message string, status int) {
 	res.WriteHeader(status)
 
@@ -220,12 +598,7 @@ func respond(res http.ResponseWriter, message string, status int) {
 	}
 }
 
-func readPutRequest(req *http.Request) (string, interface{}, error) {
-
-	jsonMap, err := readJSONBody(req)
-	if err != nil {
-		return "", nil, err
-	}
+func readPutRequest(jsonMap map[string]interface{}) (string, interface{}, error) {
 
 	name, err := getStringValueFromJSONBody(jsonMap, "name")
 	if err != nil {
@@ -244,12 +617,7 @@ func readPutRequest(req *http.Request) (string, interface{}, error) {
 	return name, value, nil
 }
 
-func readPostRequest(req *http.Request) (string, string, interface{}, error) {
-
-	jsonMap, err := readJSONBody(req)
-	if err != nil {
-		return "", "", nil, err
-	}
+func readPostRequest(jsonMap map[string]interface{}) (string, string, interface{}, error) {
 
 	name, err := getStringValueFromJSONBody(jsonMap, "name")
 	if err != nil {
@@ -264,6 +632,34 @@ func readPostRequest(req *http.Request) (string, string, interface{}, error) {
 	return name, generatorType, jsonMap["parameters"], nil
 }
 
+// readExpectedID returns the ID the latest version of a name must have for a
+// write to succeed, taken from the If-Match header or the `expected_id` key.
+func readExpectedID(req *http.Request, jsonMap map[string]interface{}) (string, error) {
+	headerID := strings.Trim(req.Header.Get("If-Match"), `"`)
+	if headerID == "*" {
+		return "", errors.Error("If-Match header must be the id of a version, '*' is not supported")
+	}
+
+	var bodyID string
+	if _, keyExists := jsonMap["expected_id"]; keyExists {
+		var err error
+		bodyID, err = getStringValueFromJSONBody(jsonMap, "expected_id")
+		if err != nil {
+			return "", err
+		}
+	}
+
+	if headerID != "" && bodyID != "" && headerID != bodyID {
+		return "", errors.Error("If-Match header and JSON request body key 'expected_id' must match")
+	}
+
+	if headerID != "" {
+		return headerID, nil
+	}
+
+	return bodyID, nil
+}
+
 func getStringValueFromJSONBody(jsonMap map[string]interface{}, keyName string) (string, error) {
 
 	value, keyExists := jsonMap[keyName]
@@ -310,8 +706,19 @@ func extractIDFromURLPath(path string) (string, error) {
 	return id, nil
 }
 
+var validNameToken = regexp.MustCompile(`^[a-zA-Z0-9_\-\/]+$`)
+
+func extractRestoreIDFromURLPath(path string) (string, bool) {
+	paths := strings.Split(strings.Trim(path, "/"), "/")
+
+	if len(paths) != 4 || paths[3] != "restore" || len(paths[2]) == 0 {
+		return "", false
+	}
+
+	return paths[2], true
+}
+
 func isValidName(name string) (bool, error) {
-	var validNameToken = regexp.MustCompile(`^[a-zA-Z0-9_\-\/]+$`)
 	if !validNameToken.MatchString(name) {
 		return false, errors.Error("Name must consist of alphanumeric, underscores, dashes, and forward slashes")
 	}
@@ -319,6 +726,23 @@ func isValidName(name string) (bool, error) {
 	return true, nil
 }
 
+func isValidPath(path string) (bool, error) {
+	if !validNameToken.MatchString(path) {
+		return false, errors.Error("Path must consist of alphanumeric, underscores, dashes, and forward slashes")
+	}
+
+	return true, nil
+}
+
+// pathCovers returns whether name is path or is under it, matching whole
+// segments so that "/cf" and "/cf/" cover "/cf/password" but not
+// "/cf-other/password". Listing, watching and permissions for a path all
+// cover names with it.
+func pathCovers(path string, name string) bool {
+	path = strings.TrimSuffix(path, "/")
+	return name == path || strings.HasPrefix(name, path+"/")
+}
+
 func validateRequestContentType(req *http.Request) error {
 	if !strings.EqualFold(req.Header.Get("content-type"), "application/json") {
 		return errors.Error("Unsupported Media Type - Accepts application/json only")
@@ -2,8 +2,8 @@ package server_test
 
 import (
 	"errors"
+	"fmt"
 	. "github.com/cloudfoundry/config-server/server"
-	. "github.com/cloudfoundry/config-server/server/serverfakes"
 	. "github.com/cloudfoundry/config-server/store/storefakes"
 	. "github.com/cloudfoundry/config-server/types/typesfakes"
 	"net/http"
@@ -17,6 +17,7 @@ import (
 	"github.com/cloudfoundry/config-server/store"
 	"github.com/cloudfoundry/config-server/types"
 	"io"
+	"regexp"
 )
 
 type BadMockStore struct{}
@@ -44,6 +45,14 @@ func generateHTTPRequest(method, urlStr string, body io.Reader) (*http.Request,
 	return req, nil
 }
 
+var createdAtPattern = regexp.MustCompile(`"created_at":"[^"]*",`)
+
+// withoutCreatedAt strips creation timestamps from a response body so it can
+// be compared with a literal
+func withoutCreatedAt(body string) string {
+	return createdAtPattern.ReplaceAllString(body, "")
+}
+
 func stringInSlice(a string, list []string) bool {
 	for _, b := range list {
 		if b == a {
@@ -59,7 +68,7 @@ var _ = Describe("RequestHandlerConcrete", func() {
 
 		Context("creating the requestHandler", func() {
 			It("should return an error", func() {
-				_, err := NewRequestHandler(nil, types.NewValueGeneratorConcrete(&FakeCertsLoader{}))
+				_, err := NewRequestHandler(nil, types.NewValueGeneratorConcrete(&FakeCertsLoader{}), allowingAuthorizer())
 				Expect(err.Error()).To(Equal("Data store must be set"))
 			})
 		})
@@ -67,17 +76,15 @@ var _ = Describe("RequestHandlerConcrete", func() {
 
 	Describe("Given a server with store", func() {
 		var requestHandler http.Handler
-		var mockTokenValidator *FakeTokenValidator
 		var mockStore *FakeStore
 		var mockValueGeneratorFactory *FakeValueGeneratorFactory
 		var mockValueGenerator *FakeValueGenerator
 
 		BeforeEach(func() {
-			mockTokenValidator = &FakeTokenValidator{}
 			mockStore = &FakeStore{}
 			mockValueGeneratorFactory = &FakeValueGeneratorFactory{}
 			mockValueGenerator = &FakeValueGenerator{}
-			requestHandler, _ = NewRequestHandler(mockStore, mockValueGeneratorFactory)
+			requestHandler, _ = NewRequestHandler(mockStore, mockValueGeneratorFactory, allowingAuthorizer())
 		})
 
 		Context("when URL path is invalid", func() {
@@ -179,6 +186,151 @@ var _ = Describe("RequestHandlerConcrete", func() {
 					})
 				})
 
+				Describe("/v1/data/<id>/restore", func() {
+					Describe("POST", func() {
+						var memoryStore store.MemoryStore
+
+						restore := func(id string) *httptest.ResponseRecorder {
+							req, _ := http.NewRequest("POST", "/v1/data/"+id+"/restore", nil)
+							recorder := httptest.NewRecorder()
+							requestHandler.ServeHTTP(recorder, req)
+							return recorder
+						}
+
+						BeforeEach(func() {
+							memoryStore = store.NewMemoryStore()
+							requestHandler, _ = NewRequestHandler(memoryStore, mockValueGeneratorFactory, allowingAuthorizer())
+
+							memoryStore.Put("bla", `{"value":"good"}`, store.Metadata{Type: "password", Parameters: `{"length":20}`})
+							memoryStore.Put("bla", `{"value":"bad"}`, store.Metadata{})
+						})
+
+						It("appends the previous value as the latest version", func() {
+							recorder := restore("0")
+
+							Expect(recorder.Code).To(Equal(http.StatusCreated))
+							Expect(withoutCreatedAt(recorder.Body.String())).To(Equal(`{"id":"2","name":"bla","restored_from":"0","type":"password","value":"good"}`))
+
+							configuration, _ := memoryStore.GetCurrentByName("bla")
+							Expect(configuration.Value).To(MatchJSON(`{"value":"good"}`))
+							Expect(configuration.RestoredFrom).To(Equal("0"))
+							Expect(configuration.Type).To(Equal("password"))
+							Expect(configuration.Parameters).To(MatchJSON(`{"length":20}`))
+
+							values, _ := memoryStore.GetByName("bla")
+							Expect(len(values)).To(Equal(3))
+						})
+
+						It("should return 404 Not Found when id does not exist", func() {
+							recorder := restore("42")
+
+							Expect(recorder.Code).To(Equal(http.StatusNotFound))
+						})
+
+						It("should return 412 Precondition Failed when If-Match does not match the latest id", func() {
+							req, _ := http.NewRequest("POST", "/v1/data/0/restore", nil)
+							req.Header.Set("If-Match", "0")
+							recorder := httptest.NewRecorder()
+							requestHandler.ServeHTTP(recorder, req)
+
+							Expect(recorder.Code).To(Equal(http.StatusPreconditionFailed))
+						})
+
+						It("returns 500 Internal Server Error when store errors", func() {
+							mockStore.GetByIDReturns(store.Configuration{}, errors.New("Kaboom!"))
+							requestHandler, _ = NewRequestHandler(mockStore, mockValueGeneratorFactory, allowingAuthorizer())
+
+							recorder := restore("0")
+
+							Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+						})
+					})
+				})
+
+				Describe("/v1/data?path=<path>", func() {
+					Describe("GET", func() {
+						BeforeEach(func() {
+							mockStore.GetByPathReturns(store.Configurations{
+								{ID: "1", Name: "/deployments/cf/a", Value: `{"value":"a"}`},
+								{ID: "4", Name: "/deployments/cf/b", Value: `{"value":{"certificate":"b"}}`},
+							}, nil)
+						})
+
+						It("returns the names under the path", func() {
+							getReq, _ := generateHTTPRequest("GET", "/v1/data?path=%2Fdeployments%2Fcf%2F", nil)
+							getRecorder := httptest.NewRecorder()
+							requestHandler.ServeHTTP(getRecorder, getReq)
+
+							Expect(getRecorder.Code).To(Equal(http.StatusOK))
+							Expect(getRecorder.Body.String()).To(Equal(`{"data":[{"id":"1","name":"/deployments/cf/a"},{"id":"4","name":"/deployments/cf/b"}]}`))
+							Expect(mockStore.GetByPathArgsForCall(0)).To(Equal("/deployments/cf/"))
+						})
+
+						It("omits names the store returns that are not under the path", func() {
+							mockStore.GetByPathReturns(store.Configurations{
+								{ID: "1", Name: "/team", Value: `{"value":"a"}`},
+								{ID: "2", Name: "/team-b/secret", Value: `{"value":"b"}`},
+								{ID: "3", Name: "/team/secret", Value: `{"value":"c"}`},
+							}, nil)
+
+							getReq, _ := generateHTTPRequest("GET", "/v1/data?path=%2Fteam", nil)
+							getRecorder := httptest.NewRecorder()
+							requestHandler.ServeHTTP(getRecorder, getReq)
+
+							Expect(getRecorder.Code).To(Equal(http.StatusOK))
+							Expect(getRecorder.Body.String()).To(Equal(`{"data":[{"id":"1","name":"/team"},{"id":"3","name":"/team/secret"}]}`))
+						})
+
+						It("returns the latest values when values=true", func() {
+							getReq, _ := generateHTTPRequest("GET", "/v1/data?path=%2Fdeployments%2Fcf%2F&values=true", nil)
+							getRecorder := httptest.NewRecorder()
+							requestHandler.ServeHTTP(getRecorder, getReq)
+
+							Expect(getRecorder.Code).To(Equal(http.StatusOK))
+							Expect(getRecorder.Body.String()).To(Equal(`{"data":[{"id":"1","name":"/deployments/cf/a","value":"a"}, {"id":"4","name":"/deployments/cf/b","value":{"certificate":"b"}}]}`))
+						})
+
+						It("returns an empty list when nothing exists under the path", func() {
+							mockStore.GetByPathReturns(nil, nil)
+
+							getReq, _ := generateHTTPRequest("GET", "/v1/data?path=%2Fdeployments%2Fdiego%2F", nil)
+							getRecorder := httptest.NewRecorder()
+							requestHandler.ServeHTTP(getRecorder, getReq)
+
+							Expect(getRecorder.Code).To(Equal(http.StatusOK))
+							Expect(getRecorder.Body.String()).To(Equal(`{"data":[]}`))
+						})
+
+						It("should return 400 Bad Request when path is invalid", func() {
+							getReq, _ := generateHTTPRequest("GET", "/v1/data?path=%2Fdeployments%25", nil)
+							getRecorder := httptest.NewRecorder()
+							requestHandler.ServeHTTP(getRecorder, getReq)
+
+							Expect(getRecorder.Code).To(Equal(http.StatusBadRequest))
+							Expect(getRecorder.Body.String()).To(ContainSubstring("Path must consist of alphanumeric, underscores, dashes, and forward slashes"))
+						})
+
+						It("should return 400 Bad Request when values is not a boolean", func() {
+							getReq, _ := generateHTTPRequest("GET", "/v1/data?path=%2Fdeployments&values=maybe", nil)
+							getRecorder := httptest.NewRecorder()
+							requestHandler.ServeHTTP(getRecorder, getReq)
+
+							Expect(getRecorder.Code).To(Equal(http.StatusBadRequest))
+							Expect(getRecorder.Body.String()).To(ContainSubstring("Query parameter 'values' must be a boolean"))
+						})
+
+						It("returns 500 Internal Server Error when store errors", func() {
+							mockStore.GetByPathReturns(nil, errors.New("Kaboom!"))
+
+							getReq, _ := generateHTTPRequest("GET", "/v1/data?path=%2Fdeployments", nil)
+							getRecorder := httptest.NewRecorder()
+							requestHandler.ServeHTTP(getRecorder, getReq)
+
+							Expect(getRecorder.Code).To(Equal(http.StatusInternalServerError))
+						})
+					})
+				})
+
 				Describe("/v1/data?name=<configuration name>", func() {
 					validURLPaths := map[string]string{
 						"/v1/data?name=smurf":                                "smurf",
@@ -250,6 +402,79 @@ var _ = Describe("RequestHandlerConcrete", func() {
 							})
 						})
 
+						Context("when limit and offset are given", func() {
+							It("returns the requested page of values", func() {
+								mockStore.GetPageByNameReturns(store.Configurations{
+									{Value: `{"value":"v2"}`, Name: "bla", ID: "2"},
+								}, nil)
+
+								getReq, _ := generateHTTPRequest("GET", "/v1/data?name=bla&limit=1&offset=1", nil)
+								getRecorder := httptest.NewRecorder()
+								requestHandler.ServeHTTP(getRecorder, getReq)
+
+								Expect(getRecorder.Code).To(Equal(http.StatusOK))
+								Expect(getRecorder.Body.String()).To(Equal(`{"data":[{"id":"2","name":"bla","value":"v2"}]}`))
+
+								name, limit, offset := mockStore.GetPageByNameArgsForCall(0)
+								Expect(name).To(Equal("bla"))
+								Expect(limit).To(Equal(1))
+								Expect(offset).To(Equal(1))
+								Expect(mockStore.GetByNameCallCount()).To(Equal(0))
+							})
+
+							It("should return 404 Not Found when page is empty", func() {
+								getReq, _ := generateHTTPRequest("GET", "/v1/data?name=bla&limit=1&offset=5", nil)
+								getRecorder := httptest.NewRecorder()
+								requestHandler.ServeHTTP(getRecorder, getReq)
+
+								Expect(getRecorder.Code).To(Equal(http.StatusNotFound))
+							})
+						})
+
+						Context("when current=true is given", func() {
+							It("returns only the latest value", func() {
+								mockStore.GetCurrentByNameReturns(store.Configuration{Value: `{"value":"v3"}`, Name: "bla", ID: "3"}, nil)
+
+								getReq, _ := generateHTTPRequest("GET", "/v1/data?name=bla&current=true", nil)
+								getRecorder := httptest.NewRecorder()
+								requestHandler.ServeHTTP(getRecorder, getReq)
+
+								Expect(getRecorder.Code).To(Equal(http.StatusOK))
+								Expect(getRecorder.Body.String()).To(Equal(`{"data":[{"id":"3","name":"bla","value":"v3"}]}`))
+								Expect(mockStore.GetCurrentByNameArgsForCall(0)).To(Equal("bla"))
+							})
+
+							It("should return 404 Not Found when name does not exist", func() {
+								getReq, _ := generateHTTPRequest("GET", "/v1/data?name=bla&current=true", nil)
+								getRecorder := httptest.NewRecorder()
+								requestHandler.ServeHTTP(getRecorder, getReq)
+
+								Expect(getRecorder.Code).To(Equal(http.StatusNotFound))
+							})
+						})
+
+						Context("when paging query parameters are invalid", func() {
+							It("should return 400 Bad Request", func() {
+								invalidQueries := map[string]string{
+									"limit=0":              "Query parameter 'limit' must be a positive integer",
+									"limit=abc":            "Query parameter 'limit' must be a positive integer",
+									"limit=1&offset=-1":    "Query parameter 'offset' must be a non-negative integer",
+									"offset=1":             "Query parameter 'offset' requires 'limit'",
+									"current=maybe":        "Query parameter 'current' must be a boolean",
+									"current=true&limit=1": "Query parameter 'current' cannot be combined with 'limit' or 'offset'",
+								}
+
+								for query, message := range invalidQueries {
+									getReq, _ := generateHTTPRequest("GET", "/v1/data?name=bla&"+query, nil)
+									getRecorder := httptest.NewRecorder()
+									requestHandler.ServeHTTP(getRecorder, getReq)
+
+									Expect(getRecorder.Code).To(Equal(http.StatusBadRequest))
+									Expect(getRecorder.Body.String()).To(ContainSubstring(message))
+								}
+							})
+						})
+
 						Context("when store errors", func() {
 							It("returns 500 Internal Server Error", func() {
 								mockStore.GetByNameReturns([]store.Configuration{}, errors.New("Kaboom!"))
@@ -361,14 +586,27 @@ var _ = Describe("RequestHandlerConcrete", func() {
 									requestHandler.ServeHTTP(putRecorder, req)
 
 									Expect(mockStore.PutCallCount()).To(Equal(1))
-									name, value := mockStore.PutArgsForCall(0)
+									name, value, metadata := mockStore.PutArgsForCall(0)
 
 									Expect(name).To(Equal("bla"))
 									Expect(value).To(Equal(`{"value":"str"}`))
+									Expect(metadata).To(Equal(store.Metadata{Type: "json"}))
 									Expect(putRecorder.Code).To(Equal(http.StatusOK))
 								})
 							})
 
+							Context("when request is authenticated", func() {
+								It("records the identity as the creator", func() {
+									req, _ := generateHTTPRequest("PUT", "/v1/data", strings.NewReader(`{"name":"bla","value":"str"}`))
+									req = WithIdentity(req, Identity{Name: "admin"})
+									putRecorder := httptest.NewRecorder()
+									requestHandler.ServeHTTP(putRecorder, req)
+
+									_, _, metadata := mockStore.PutArgsForCall(0)
+									Expect(metadata).To(Equal(store.Metadata{CreatedBy: "admin", Type: "json"}))
+								})
+							})
+
 							Context("when value is a number", func() {
 								It("should store value in a specific JSON format and respond with 204 StatusNoContent", func() {
 									req, _ := generateHTTPRequest("PUT", "/v1/data", strings.NewReader(`{"name":"bla","value":123}`))
@@ -376,7 +614,7 @@ var _ = Describe("RequestHandlerConcrete", func() {
 									requestHandler.ServeHTTP(putRecorder, req)
 
 									Expect(mockStore.PutCallCount()).To(Equal(1))
-									name, value := mockStore.PutArgsForCall(0)
+									name, value, _ := mockStore.PutArgsForCall(0)
 
 									Expect(name).To(Equal("bla"))
 									Expect(value).To(Equal(`{"value":123}`))
@@ -394,13 +632,92 @@ var _ = Describe("RequestHandlerConcrete", func() {
 									requestHandler.ServeHTTP(putRecorder, req)
 
 									Expect(mockStore.PutCallCount()).To(Equal(1))
-									name, value := mockStore.PutArgsForCall(0)
+									name, value, _ := mockStore.PutArgsForCall(0)
 
 									Expect(name).To(Equal("bla"))
 									Expect(value).To(Equal(valueToStore))
 									Expect(putRecorder.Code).To(Equal(http.StatusOK))
 								})
 							})
+
+							Context("when name is reserved", func() {
+								It("should return 400 Bad Request", func() {
+									mockStore.PutReturns("", store.ErrNameReserved)
+
+									req, _ := generateHTTPRequest("PUT", "/v1/data", strings.NewReader(`{"name":"nats","value":"str"}`))
+									putRecorder := httptest.NewRecorder()
+									requestHandler.ServeHTTP(putRecorder, req)
+
+									Expect(putRecorder.Code).To(Equal(http.StatusBadRequest))
+									Expect(putRecorder.Body.String()).To(ContainSubstring("Name is reserved"))
+								})
+							})
+
+							Context("when an expected id is given", func() {
+								BeforeEach(func() {
+									mockStore.PutIfLatestReturns("1", nil)
+								})
+
+								It("writes conditionally using the If-Match header", func() {
+									req, _ := generateHTTPRequest("PUT", "/v1/data", strings.NewReader(`{"name":"bla","value":"str"}`))
+									req.Header.Set("If-Match", `"0"`)
+									putRecorder := httptest.NewRecorder()
+									requestHandler.ServeHTTP(putRecorder, req)
+
+									Expect(putRecorder.Code).To(Equal(http.StatusOK))
+									Expect(mockStore.PutCallCount()).To(Equal(0))
+
+									name, value, expectedID, metadata := mockStore.PutIfLatestArgsForCall(0)
+									Expect(name).To(Equal("bla"))
+									Expect(value).To(Equal(`{"value":"str"}`))
+									Expect(expectedID).To(Equal("0"))
+									Expect(metadata).To(Equal(store.Metadata{Type: "json"}))
+								})
+
+								It("writes conditionally using the expected_id body key", func() {
+									req, _ := generateHTTPRequest("PUT", "/v1/data", strings.NewReader(`{"name":"bla","value":"str","expected_id":"0"}`))
+									putRecorder := httptest.NewRecorder()
+									requestHandler.ServeHTTP(putRecorder, req)
+
+									Expect(putRecorder.Code).To(Equal(http.StatusOK))
+									_, _, expectedID, _ := mockStore.PutIfLatestArgsForCall(0)
+									Expect(expectedID).To(Equal("0"))
+								})
+
+								It("should return 412 Precondition Failed when latest id does not match", func() {
+									mockStore.PutIfLatestReturns("", store.ErrPreconditionFailed)
+
+									req, _ := generateHTTPRequest("PUT", "/v1/data", strings.NewReader(`{"name":"bla","value":"str"}`))
+									req.Header.Set("If-Match", "0")
+									putRecorder := httptest.NewRecorder()
+									requestHandler.ServeHTTP(putRecorder, req)
+
+									Expect(putRecorder.Code).To(Equal(http.StatusPreconditionFailed))
+									Expect(putRecorder.Body.String()).To(ContainSubstring("Latest ID does not match expected ID"))
+								})
+
+								It("should return 400 Bad Request when If-Match header and expected_id differ", func() {
+									req, _ := generateHTTPRequest("PUT", "/v1/data", strings.NewReader(`{"name":"bla","value":"str","expected_id":"1"}`))
+									req.Header.Set("If-Match", "0")
+									putRecorder := httptest.NewRecorder()
+									requestHandler.ServeHTTP(putRecorder, req)
+
+									Expect(putRecorder.Code).To(Equal(http.StatusBadRequest))
+									Expect(putRecorder.Body.String()).To(ContainSubstring("If-Match header and JSON request body key 'expected_id' must match"))
+									Expect(mockStore.PutIfLatestCallCount()).To(Equal(0))
+								})
+
+								It("should return 400 Bad Request when If-Match header is a wildcard", func() {
+									req, _ := generateHTTPRequest("PUT", "/v1/data", strings.NewReader(`{"name":"bla","value":"str"}`))
+									req.Header.Set("If-Match", "*")
+									putRecorder := httptest.NewRecorder()
+									requestHandler.ServeHTTP(putRecorder, req)
+
+									Expect(putRecorder.Code).To(Equal(http.StatusBadRequest))
+									Expect(mockStore.PutCallCount()).To(Equal(0))
+									Expect(mockStore.PutIfLatestCallCount()).To(Equal(0))
+								})
+							})
 						})
 					})
 
@@ -487,16 +804,11 @@ var _ = Describe("RequestHandlerConcrete", func() {
 							Describe("Password generation", func() {
 								Context("when value already exists", func() {
 									It("should not generate a password", func() {
-										mockStore.GetByNameStub = func(name string) (store.Configurations, error) {
-											respValues := store.Configurations{
-												{
-													Value: `{"value":"smurf"}`,
-													ID:    "some_id",
-													Name:  "bla",
-												},
-											}
-											return respValues, nil
-										}
+										mockStore.GetCurrentByNameReturns(store.Configuration{
+											Value: `{"value":"smurf"}`,
+											ID:    "some_id",
+											Name:  "bla",
+										}, nil)
 
 										postReq, _ := generateHTTPRequest("POST", "/v1/data", strings.NewReader(`{"name":"bla", "type":"password","parameters":{}}`))
 
@@ -511,7 +823,7 @@ var _ = Describe("RequestHandlerConcrete", func() {
 
 								Context("when value does NOT exist", func() {
 									It("should return generated password", func() {
-										requestHandler, _ = NewRequestHandler(store.NewMemoryStore(), types.NewValueGeneratorConcrete(&FakeCertsLoader{}))
+										requestHandler, _ = NewRequestHandler(store.NewMemoryStore(), types.NewValueGeneratorConcrete(&FakeCertsLoader{}), allowingAuthorizer())
 
 										postReq, _ := generateHTTPRequest("POST", "/v1/data", strings.NewReader(`{"name":"bla","type":"password","parameters":{}}`))
 
@@ -533,16 +845,11 @@ var _ = Describe("RequestHandlerConcrete", func() {
 								Context("when value already exists", func() {
 									It("should not generate certificates", func() {
 
-										mockStore.GetByNameStub = func(name string) (store.Configurations, error) {
-											respValue := store.Configurations{
-												{
-													Value: `{"value":"smurf"}`,
-													ID:    "some_id",
-													Name:  "bla",
-												},
-											}
-											return respValue, nil
-										}
+										mockStore.GetCurrentByNameReturns(store.Configuration{
+											Value: `{"value":"smurf"}`,
+											ID:    "some_id",
+											Name:  "bla",
+										}, nil)
 
 										postReq, _ := generateHTTPRequest("POST", "/v1/data", strings.NewReader(`{"name":"bla","type":"certificate","parameters":{}}`))
 
@@ -557,7 +864,7 @@ var _ = Describe("RequestHandlerConcrete", func() {
 
 								Context("when value does NOT exist", func() {
 									It("should return generated certificate, its private key and root certificate used to sign the generated certificate", func() {
-										requestHandler, _ = NewRequestHandler(store.NewMemoryStore(), mockValueGeneratorFactory)
+										requestHandler, _ = NewRequestHandler(store.NewMemoryStore(), mockValueGeneratorFactory, allowingAuthorizer())
 										mockValueGeneratorFactory.GetGeneratorReturns(mockValueGenerator, nil)
 
 										mockValueGenerator.GenerateReturns(types.CertResponse{
@@ -586,6 +893,289 @@ var _ = Describe("RequestHandlerConcrete", func() {
 								})
 							})
 						})
+
+						Context("when request body contains a mode", func() {
+							var memoryStore store.MemoryStore
+
+							generate := func(body string) *httptest.ResponseRecorder {
+								postReq, _ := generateHTTPRequest("POST", "/v1/data", strings.NewReader(body))
+								recorder := httptest.NewRecorder()
+								requestHandler.ServeHTTP(recorder, postReq)
+								return recorder
+							}
+
+							BeforeEach(func() {
+								memoryStore = store.NewMemoryStore()
+								requestHandler, _ = NewRequestHandler(memoryStore, mockValueGeneratorFactory, allowingAuthorizer())
+								mockValueGeneratorFactory.GetGeneratorReturns(mockValueGenerator, nil)
+								mockValueGenerator.GenerateStub = func(parameters interface{}) (interface{}, error) {
+									return fmt.Sprintf("generated-%d", mockValueGenerator.GenerateCallCount()), nil
+								}
+
+								recorder := generate(`{"name":"bla","type":"password","parameters":{"length":20}}`)
+								Expect(recorder.Code).To(Equal(http.StatusCreated))
+							})
+
+							It("stores the generation type and parameters alongside the value", func() {
+								configuration, _ := memoryStore.GetCurrentByName("bla")
+								Expect(configuration.Value).To(MatchJSON(`{"value":"generated-1"}`))
+								Expect(configuration.Type).To(Equal("password"))
+								Expect(configuration.Parameters).To(MatchJSON(`{"length":20}`))
+							})
+
+							It("records the identity of the request as the creator", func() {
+								postReq, _ := generateHTTPRequest("POST", "/v1/data", strings.NewReader(`{"name":"bla","type":"password","mode":"overwrite"}`))
+								recorder := httptest.NewRecorder()
+								requestHandler.ServeHTTP(recorder, WithIdentity(postReq, Identity{Name: "admin"}))
+
+								Expect(recorder.Code).To(Equal(http.StatusCreated))
+								Expect(withoutCreatedAt(recorder.Body.String())).To(Equal(`{"created_by":"admin","id":"1","name":"bla","type":"password","value":"generated-2"}`))
+							})
+
+							It("ignores generation metadata stored inside the value", func() {
+								memoryStore.Put("bla", `{"value":"set-by-put","type":"password","parameters":{"length":20}}`, store.Metadata{})
+
+								recorder := generate(`{"name":"bla","type":"password","parameters":{"length":20},"mode":"converge"}`)
+
+								Expect(recorder.Code).To(Equal(http.StatusCreated))
+								Expect(withoutCreatedAt(recorder.Body.String())).To(Equal(`{"id":"2","name":"bla","type":"password","value":"generated-2"}`))
+							})
+
+							Context("when mode is no-overwrite", func() {
+								It("returns the existing value", func() {
+									recorder := generate(`{"name":"bla","type":"password","parameters":{"length":30},"mode":"no-overwrite"}`)
+
+									Expect(recorder.Code).To(Equal(http.StatusOK))
+									Expect(withoutCreatedAt(recorder.Body.String())).To(Equal(`{"id":"0","name":"bla","type":"password","value":"generated-1"}`))
+								})
+							})
+
+							Context("when mode is overwrite", func() {
+								It("generates a new version and keeps the history", func() {
+									recorder := generate(`{"name":"bla","type":"password","parameters":{"length":20},"mode":"overwrite"}`)
+
+									Expect(recorder.Code).To(Equal(http.StatusCreated))
+									Expect(withoutCreatedAt(recorder.Body.String())).To(Equal(`{"id":"1","name":"bla","type":"password","value":"generated-2"}`))
+
+									values, _ := memoryStore.GetByName("bla")
+									Expect(len(values)).To(Equal(2))
+								})
+							})
+
+							Context("when mode is converge", func() {
+								It("returns the existing value when parameters are the same", func() {
+									recorder := generate(`{"name":"bla","type":"password","parameters":{"length":20},"mode":"converge"}`)
+
+									Expect(recorder.Code).To(Equal(http.StatusOK))
+									Expect(withoutCreatedAt(recorder.Body.String())).To(Equal(`{"id":"0","name":"bla","type":"password","value":"generated-1"}`))
+								})
+
+								It("generates a new version when parameters differ", func() {
+									recorder := generate(`{"name":"bla","type":"password","parameters":{"length":30},"mode":"converge"}`)
+
+									Expect(recorder.Code).To(Equal(http.StatusCreated))
+									Expect(withoutCreatedAt(recorder.Body.String())).To(Equal(`{"id":"1","name":"bla","type":"password","value":"generated-2"}`))
+								})
+
+								It("generates a new version when type differs", func() {
+									recorder := generate(`{"name":"bla","type":"certificate","parameters":{"length":20},"mode":"converge"}`)
+
+									Expect(recorder.Code).To(Equal(http.StatusCreated))
+								})
+
+								It("generates a new version when existing value was not generated", func() {
+									memoryStore.Put("bla", `{"value":"set-by-put"}`, store.Metadata{})
+
+									recorder := generate(`{"name":"bla","type":"password","parameters":{"length":20},"mode":"converge"}`)
+
+									Expect(recorder.Code).To(Equal(http.StatusCreated))
+								})
+
+								It("applies to variables in a list", func() {
+									recorder := generate(`{"variables":[
+										{"name":"bla","type":"password","options":{"length":20},"mode":"converge"},
+										{"name":"other","type":"password"}
+									]}`)
+									Expect(recorder.Code).To(Equal(http.StatusCreated))
+									Expect(mockValueGenerator.GenerateCallCount()).To(Equal(2))
+
+									recorder = generate(`{"variables":[{"name":"bla","type":"password","options":{"length":30},"mode":"converge"}]}`)
+									Expect(recorder.Code).To(Equal(http.StatusCreated))
+									Expect(mockValueGenerator.GenerateCallCount()).To(Equal(3))
+								})
+							})
+
+							Context("when an expected id is given", func() {
+								It("generates a new version when latest id matches", func() {
+									recorder := generate(`{"name":"bla","type":"password","mode":"overwrite","expected_id":"0"}`)
+
+									Expect(recorder.Code).To(Equal(http.StatusCreated))
+									Expect(withoutCreatedAt(recorder.Body.String())).To(Equal(`{"id":"1","name":"bla","type":"password","value":"generated-2"}`))
+								})
+
+								It("should return 412 Precondition Failed when latest id does not match", func() {
+									memoryStore.Put("bla", `{"value":"concurrent"}`, store.Metadata{})
+
+									postReq, _ := generateHTTPRequest("POST", "/v1/data", strings.NewReader(`{"name":"bla","type":"password","mode":"overwrite"}`))
+									postReq.Header.Set("If-Match", "0")
+									recorder := httptest.NewRecorder()
+									requestHandler.ServeHTTP(recorder, postReq)
+
+									Expect(recorder.Code).To(Equal(http.StatusPreconditionFailed))
+									Expect(mockValueGenerator.GenerateCallCount()).To(Equal(1))
+								})
+							})
+
+							Context("when mode is not supported", func() {
+								It("should return 400 Bad Request", func() {
+									for _, body := range []string{
+										`{"name":"bla","type":"password","mode":"sometimes"}`,
+										`{"name":"bla","type":"password","mode":1}`,
+										`{"variables":[{"name":"bla","type":"password","mode":"sometimes"}]}`,
+									} {
+										recorder := generate(body)
+
+										Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+									}
+									Expect(mockValueGenerator.GenerateCallCount()).To(Equal(1))
+								})
+							})
+						})
+
+						Context("when request body contains a list of variables", func() {
+							var memoryStore store.MemoryStore
+
+							BeforeEach(func() {
+								memoryStore = store.NewMemoryStore()
+								requestHandler, _ = NewRequestHandler(memoryStore, mockValueGeneratorFactory, allowingAuthorizer())
+								mockValueGeneratorFactory.GetGeneratorReturns(mockValueGenerator, nil)
+								mockValueGenerator.GenerateStub = func(parameters interface{}) (interface{}, error) {
+									return "generated", nil
+								}
+							})
+
+							It("generates all missing values and returns every value in request order", func() {
+								memoryStore.Put("existing", `{"value":"stored"}`, store.Metadata{})
+
+								postReq, _ := generateHTTPRequest("POST", "/v1/data", strings.NewReader(`{"variables":[
+									{"name":"existing","type":"password"},
+									{"name":"new","type":"password"}
+								]}`))
+
+								recorder := httptest.NewRecorder()
+								requestHandler.ServeHTTP(recorder, postReq)
+
+								Expect(recorder.Code).To(Equal(http.StatusCreated))
+								Expect(withoutCreatedAt(recorder.Body.String())).To(Equal(`{"data":[{"id":"0","name":"existing","value":"stored"}, {"id":"1","name":"new","type":"password","value":"generated"}]}`))
+								Expect(mockValueGenerator.GenerateCallCount()).To(Equal(1))
+							})
+
+							It("returns 200 OK when all values already exist", func() {
+								memoryStore.Put("existing", `{"value":"stored"}`, store.Metadata{})
+
+								postReq, _ := generateHTTPRequest("POST", "/v1/data", strings.NewReader(`{"variables":[{"name":"existing","type":"password"}]}`))
+
+								recorder := httptest.NewRecorder()
+								requestHandler.ServeHTTP(recorder, postReq)
+
+								Expect(recorder.Code).To(Equal(http.StatusOK))
+								Expect(mockValueGenerator.GenerateCallCount()).To(Equal(0))
+							})
+
+							It("generates certificate authorities before the certificates they sign", func() {
+								postReq, _ := generateHTTPRequest("POST", "/v1/data", strings.NewReader(`{"variables":[
+									{"name":"leaf","type":"certificate","options":{"ca":"intermediate_ca","common_name":"leaf"}},
+									{"name":"password","type":"password"},
+									{"name":"intermediate_ca","type":"certificate","options":{"ca":"root_ca","is_ca":true}},
+									{"name":"root_ca","type":"certificate","options":{"is_ca":true}}
+								]}`))
+
+								recorder := httptest.NewRecorder()
+								requestHandler.ServeHTTP(recorder, postReq)
+
+								Expect(recorder.Code).To(Equal(http.StatusCreated))
+								Expect(mockValueGenerator.GenerateCallCount()).To(Equal(4))
+
+								Expect(mockValueGenerator.GenerateArgsForCall(0)).To(Equal(map[string]interface{}{"is_ca": true}))
+								Expect(mockValueGenerator.GenerateArgsForCall(1)).To(Equal(map[string]interface{}{"ca": "root_ca", "is_ca": true}))
+								Expect(mockValueGenerator.GenerateArgsForCall(2)).To(Equal(map[string]interface{}{"ca": "intermediate_ca", "common_name": "leaf"}))
+								Expect(mockValueGenerator.GenerateArgsForCall(3)).To(BeNil())
+
+								var data map[string][]map[string]interface{}
+								json.Unmarshal(recorder.Body.Bytes(), &data)
+								Expect(data["data"][0]["name"]).To(Equal("leaf"))
+								Expect(data["data"][1]["name"]).To(Equal("password"))
+								Expect(data["data"][2]["name"]).To(Equal("intermediate_ca"))
+								Expect(data["data"][3]["name"]).To(Equal("root_ca"))
+							})
+
+							It("returns 400 Bad Request when certificates reference each other in a cycle", func() {
+								postReq, _ := generateHTTPRequest("POST", "/v1/data", strings.NewReader(`{"variables":[
+									{"name":"a","type":"certificate","options":{"ca":"b"}},
+									{"name":"b","type":"certificate","options":{"ca":"a"}}
+								]}`))
+
+								recorder := httptest.NewRecorder()
+								requestHandler.ServeHTTP(recorder, postReq)
+
+								Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+								Expect(recorder.Body.String()).To(ContainSubstring("Variables have a circular dependency: a -> b -> a"))
+								Expect(mockValueGenerator.GenerateCallCount()).To(Equal(0))
+							})
+
+							It("returns 400 Bad Request when a variable is defined more than once", func() {
+								postReq, _ := generateHTTPRequest("POST", "/v1/data", strings.NewReader(`{"variables":[{"name":"a","type":"password"},{"name":"a","type":"password"}]}`))
+
+								recorder := httptest.NewRecorder()
+								requestHandler.ServeHTTP(recorder, postReq)
+
+								Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+								Expect(recorder.Body.String()).To(ContainSubstring("Variable 'a' is defined more than once"))
+							})
+
+							It("returns 400 Bad Request when variables is not a list of objects", func() {
+								for _, body := range []string{`{"variables":"a"}`, `{"variables":["a"]}`} {
+									postReq, _ := generateHTTPRequest("POST", "/v1/data", strings.NewReader(body))
+
+									recorder := httptest.NewRecorder()
+									requestHandler.ServeHTTP(recorder, postReq)
+
+									Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+								}
+							})
+
+							It("returns 400 Bad Request without generating anything when a type is not supported", func() {
+								mockValueGeneratorFactory.GetGeneratorStub = func(valueType string) (types.ValueGenerator, error) {
+									if valueType == "bad_type" {
+										return nil, errors.New("Unsupported value type: bad_type")
+									}
+									return mockValueGenerator, nil
+								}
+
+								postReq, _ := generateHTTPRequest("POST", "/v1/data", strings.NewReader(`{"variables":[{"name":"a","type":"password"},{"name":"b","type":"bad_type"}]}`))
+
+								recorder := httptest.NewRecorder()
+								requestHandler.ServeHTTP(recorder, postReq)
+
+								Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+								Expect(recorder.Body.String()).To(ContainSubstring("Unsupported value type: bad_type"))
+								Expect(mockValueGenerator.GenerateCallCount()).To(Equal(0))
+							})
+
+							It("returns 500 Internal Server Error naming the variable that failed to generate", func() {
+								mockValueGenerator.GenerateStub = func(parameters interface{}) (interface{}, error) {
+									return nil, errors.New("Kaboom!")
+								}
+
+								postReq, _ := generateHTTPRequest("POST", "/v1/data", strings.NewReader(`{"variables":[{"name":"a","type":"password"}]}`))
+
+								recorder := httptest.NewRecorder()
+								requestHandler.ServeHTTP(recorder, postReq)
+
+								Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+								Expect(recorder.Body.String()).To(ContainSubstring("Failed to generate value for 'a': Kaboom!"))
+							})
+						})
 					})
 
 					Describe("DELETE", func() {
@@ -630,6 +1220,40 @@ var _ = Describe("RequestHandlerConcrete", func() {
 							})
 						})
 
+						Context("when an id is given in the URL path", func() {
+							It("should delete only the version with that id and return 204 Status No Content", func() {
+								mockStore.GetByIDReturns(store.Configuration{ID: "5", Name: "bla"}, nil)
+								mockStore.DeleteByIDReturns(1, nil)
+
+								req, _ := generateHTTPRequest("DELETE", "/v1/data/5", nil)
+								recorder := httptest.NewRecorder()
+								requestHandler.ServeHTTP(recorder, req)
+
+								Expect(recorder.Code).To(Equal(http.StatusNoContent))
+								Expect(mockStore.DeleteByIDArgsForCall(0)).To(Equal("5"))
+								Expect(mockStore.DeleteCallCount()).To(Equal(0))
+							})
+
+							It("should return 404 Status Not Found when id does not exist", func() {
+								req, _ := generateHTTPRequest("DELETE", "/v1/data/5", nil)
+								recorder := httptest.NewRecorder()
+								requestHandler.ServeHTTP(recorder, req)
+
+								Expect(recorder.Code).To(Equal(http.StatusNotFound))
+							})
+
+							It("should return 500 Internal Server Error when store errors", func() {
+								mockStore.GetByIDReturns(store.Configuration{ID: "5", Name: "bla"}, nil)
+								mockStore.DeleteByIDReturns(0, errors.New("Kaboom!"))
+
+								req, _ := generateHTTPRequest("DELETE", "/v1/data/5", nil)
+								recorder := httptest.NewRecorder()
+								requestHandler.ServeHTTP(recorder, req)
+
+								Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+							})
+						})
+
 						Context("Name does not exist", func() {
 							It("should return 404 Status Not Found", func() {
 								req, _ := generateHTTPRequest("DELETE", "/v1/data?name=bla", nil)
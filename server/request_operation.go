@@ -0,0 +1,69 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+)
+
+// requestOperation names what a request does, e.g. for the audit log
+func requestOperation(req *http.Request) string {
+	if strings.HasPrefix(req.URL.Path, "/v1/permissions") {
+		return permissionsOperation(req)
+	}
+
+	switch {
+	case req.URL.Path == "/v1/interpolate":
+		return "interpolate"
+	case req.URL.Path == "/v1/watch":
+		return "watch"
+	case req.URL.Path == "/v1/undelete":
+		return "undelete"
+	case strings.HasSuffix(req.URL.Path, "/restore"):
+		return "restore"
+	}
+
+	switch req.Method {
+	case "GET":
+		return "read"
+	case "PUT":
+		return "write"
+	case "POST":
+		return "generate"
+	case "DELETE":
+		return "delete"
+	default:
+		return strings.ToLower(req.Method)
+	}
+}
+
+// requiredOperation returns the operation a caller must be allowed to perform
+// for a request, or an empty string for requests no handler serves
+func requiredOperation(req *http.Request) string {
+	switch requestOperation(req) {
+	case "read", "interpolate", "watch":
+		return OperationRead
+	case "write", "restore", "undelete":
+		return OperationWrite
+	case "generate":
+		return OperationGenerate
+	case "delete":
+		return OperationDelete
+	case "list_permissions", "grant", "revoke":
+		return OperationAdmin
+	default:
+		return ""
+	}
+}
+
+func permissionsOperation(req *http.Request) string {
+	switch req.Method {
+	case "GET":
+		return "list_permissions"
+	case "POST":
+		return "grant"
+	case "DELETE":
+		return "revoke"
+	default:
+		return strings.ToLower(req.Method)
+	}
+}
@@ -1,53 +1,441 @@
 package server
 
 import (
+	"context"
+	"crypto/x509"
 	"github.com/cloudfoundry/config-server/config"
+	"github.com/cloudfoundry/config-server/log"
 	"github.com/cloudfoundry/config-server/store"
 	"github.com/cloudfoundry/config-server/types"
+	"io"
 	"net/http"
+	"os"
+	"os/signal"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/cloudfoundry/bosh-utils/errors"
 )
 
+const (
+	serverLogTag              = "ConfigServer"
+	deletedPurgeInterval      = time.Hour
+	certificateExpiryInterval = 5 * time.Minute
+	jwtKeysMinReload          = 10 * time.Second
+	webhookRetryBackoff       = time.Second
+)
+
 type configServer struct {
-	config config.ServerConfig
+	config     config.ServerConfig
+	stop       chan struct{}
+	background *sync.WaitGroup
+}
+
+// serverResources are what handlers are configured with that is released on
+// shutdown or reloaded on SIGHUP
+type serverResources struct {
+	dataStore        store.Store
+	eventBroker      EventBroker
+	metrics          Metrics
+	verificationKeys RefreshingVerificationKeys
+	checkClientCAs   ClientCACheck
 }
 
 func NewConfigServer(config config.ServerConfig) ConfigServer {
-	return configServer{config: config}
+	return configServer{config: config, stop: make(chan struct{}), background: &sync.WaitGroup{}}
 }
 
 func (cs configServer) Start() error {
-	if err := cs.configureHandler(); err != nil {
+	resources, err := cs.configureHandler()
+	if err != nil {
 		return err
 	}
 
-	return http.ListenAndServeTLS(":"+strconv.Itoa(cs.config.Port),
-		cs.config.CertificateFilePath,
-		cs.config.PrivateKeyFilePath, nil)
+	clientCAPath := cs.config.ClientCertificates.CACertificatePath
+	tlsCertificates, err := NewTLSCertificates(cs.config.CertificateFilePath, cs.config.PrivateKeyFilePath, clientCAPath, resources.checkClientCAs)
+	if err != nil {
+		cs.release(resources)
+		return err
+	}
+
+	server := &http.Server{
+		Addr:      ":" + strconv.Itoa(cs.config.Port),
+		Handler:   NewRequestMetricsHandler(resources.metrics, http.DefaultServeMux),
+		TLSConfig: tlsCertificates.Config(),
+	}
+	server.RegisterOnShutdown(resources.eventBroker.Close)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.ListenAndServeTLS("", "")
+	}()
+
+	for {
+		select {
+		case err := <-served:
+			cs.release(resources)
+			return err
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				cs.reload(tlsCertificates, resources.verificationKeys)
+				continue
+			}
+
+			cs.shutdown(server)
+			cs.release(resources)
+			return nil
+		}
+	}
 }
 
-func (cs configServer) configureHandler() error {
-	jwtTokenValidator, err := NewJwtTokenValidator(cs.config.JwtVerificationKeyPath)
+// shutdown stops accepting connections and waits for in-flight requests to
+// complete, closing the connections still busy after the shutdown timeout
+func (cs configServer) shutdown(server *http.Server) {
+	log.Logger.Info(serverLogTag, "Draining requests")
+
+	ctx, cancel := context.WithTimeout(context.Background(), cs.config.ShutdownTimeout())
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Logger.Warn(serverLogTag, "Closing connections still busy after shutdown timeout: %s", err.Error())
+		server.Close()
+	}
+}
+
+// release stops background work, waits for it to return and closes the
+// connections to the database
+func (cs configServer) release(resources serverResources) {
+	close(cs.stop)
+	cs.background.Wait()
+
+	if closer, ok := resources.dataStore.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Logger.Error(serverLogTag, "Failed to close data store: %s", err.Error())
+		}
+	}
+}
+
+// runInBackground runs work in a goroutine until stop is closed. release
+// waits for it to return before closing the database.
+func (cs configServer) runInBackground(work func(stop <-chan struct{})) {
+	cs.background.Add(1)
+	go func() {
+		defer cs.background.Done()
+		work(cs.stop)
+	}()
+}
+
+// reload reads the server certificate, client CA and JWT verification keys
+// again, keeping the previous ones when they cannot be loaded or the client
+// CA is a CA certificates are generated with. CAs used to generate
+// certificates are read whenever they are used.
+func (cs configServer) reload(tlsCertificates TLSCertificates, verificationKeys RefreshingVerificationKeys) {
+	log.Logger.Info(serverLogTag, "Reloading TLS certificates and JWT verification keys")
+
+	if err := tlsCertificates.Reload(); err != nil {
+		log.Logger.Error(serverLogTag, "Failed to reload TLS certificates: %s", err.Error())
+	}
+
+	if err := verificationKeys.Reload(); err != nil {
+		log.Logger.Error(serverLogTag, "Failed to reload JWT verification keys: %s", err.Error())
+	}
+}
+
+func (cs configServer) configureHandler() (serverResources, error) {
+	verificationKeys, err := cs.verificationKeys()
+	if err != nil {
+		return serverResources{}, errors.WrapError(err, "Failed to create JWT token validator")
+	}
+	cs.runInBackground(func(stop <-chan struct{}) {
+		verificationKeys.Run(cs.config.JwtKeysRefresh(), stop)
+	})
+	jwtTokenValidator := NewJwtTokenValidator(verificationKeys, ClaimRequirements{
+		Issuer:    cs.config.JwtIssuer,
+		Audience:  cs.config.JwtAudience,
+		ClockSkew: time.Duration(cs.config.JwtClockSkewSeconds) * time.Second,
+	})
+
+	dataStore, err := store.CreateStore(cs.config)
+	if err != nil {
+		return serverResources{}, errors.WrapError(err, "Failed to create data store")
+	}
+
+	permissionStore, ok := dataStore.(store.PermissionStore)
+	if !ok {
+		return serverResources{}, errors.Error("Data store does not support permissions")
+	}
+
+	eventBroker := NewEventBroker()
+	eventPublisher, err := cs.configureEvents(dataStore, eventBroker)
+	if err != nil {
+		return serverResources{}, errors.WrapError(err, "Failed to configure events")
+	}
+	webhookNotifier := NewWebhookNotifier(cs.config.Webhooks, webhookRetryBackoff, cs.config.WebhookDeadLetterPath, log.Logger)
+	cs.runInBackground(webhookNotifier.Run)
+
+	metrics := NewMetrics()
+	backend := cs.storeBackend()
+	if reporter, ok := dataStore.(store.DBStatsReporter); ok {
+		metrics.AddCollector(NewDBStatsCollector(metrics, backend, reporter))
+	}
+
+	instrumentedStore := store.NewInstrumentedStore(dataStore, NewStoreObserver(metrics, backend))
+	publishingStore := store.NewPublishingStore(instrumentedStore, store.NewEventPublishers(eventPublisher, webhookNotifier), log.Logger)
+	store := store.NewReservedNamesStore(publishingStore, cs.configuredCANames())
+
+	authenticated, err := cs.authenticatedHandlers(jwtTokenValidator)
+	if err != nil {
+		return serverResources{}, err
+	}
+
+	authorizer, err := cs.authorizer(permissionStore)
+	if err != nil {
+		return serverResources{}, err
+	}
+
+	x509Loader := types.NewX509Loader(cs.config.CACertificateFilePath, cs.config.CAPrivateKeyFilePath, cs.config.CertificateAuthorities)
+
+	certsLoader := types.NewStoreCertsLoader(store, x509Loader, cs.config.CertificateAuthorities)
+	valueGeneratorFactory := types.NewInstrumentedValueGeneratorFactory(types.NewValueGeneratorConcrete(certsLoader), func(valueType string, duration time.Duration) {
+		metrics.Observe(MetricGenerateDuration, duration.Seconds(), valueType)
+	})
+	requestHandler, err := NewRequestHandler(store, valueGeneratorFactory, authorizer)
 	if err != nil {
-		return errors.WrapError(err, "Failed to create JWT token validator")
+		return serverResources{}, errors.WrapError(err, "Failed to create Request Handler")
 	}
+	authenticationHandler := authenticated(requestHandler)
 
-	store, err := store.CreateStore(cs.config)
+	interpolationHandler, err := NewInterpolationHandler(store, authorizer)
 	if err != nil {
-		return errors.WrapError(err, "Failed to create data store")
+		return serverResources{}, errors.WrapError(err, "Failed to create Interpolation Handler")
 	}
 
-	x509Loader := types.NewX509Loader(cs.config.CACertificateFilePath, cs.config.CAPrivateKeyFilePath)
-	requestHandler, err := NewRequestHandler(store, types.NewValueGeneratorConcrete(x509Loader))
+	undeleteHandler, err := NewUndeleteHandler(store, cs.config.DeletedRetention(), authorizer)
 	if err != nil {
-		return errors.WrapError(err, "Failed to create Request Handler")
+		return serverResources{}, errors.WrapError(err, "Failed to create Undelete Handler")
 	}
-	authenticationHandler := NewAuthenticationHandler(jwtTokenValidator, requestHandler)
 
+	permissionsHandler, err := NewPermissionsHandler(permissionStore, NewScopeAuthorizer())
+	if err != nil {
+		return serverResources{}, errors.WrapError(err, "Failed to create Permissions Handler")
+	}
+
+	http.Handle("/health", NewHealthHandler())
+	http.Handle("/ready", NewReadinessHandler(cs.readinessChecks(dataStore, x509Loader, verificationKeys)))
+	http.Handle("/metrics", NewMetricsHandler(metrics))
 	http.Handle("/v1/data", authenticationHandler)
 	http.Handle("/v1/data/", authenticationHandler)
+	http.Handle("/v1/interpolate", authenticated(interpolationHandler))
+	http.Handle("/v1/undelete", authenticated(undeleteHandler))
+	http.Handle("/v1/watch", authenticated(NewWatchHandler(eventBroker, authorizer)))
+	http.Handle("/v1/permissions", authenticated(permissionsHandler))
+	http.Handle("/v1/permissions/", authenticated(permissionsHandler))
+
+	cs.runInBackground(NewDeletedPurger(store, cs.config.DeletedRetention(), deletedPurgeInterval, log.Logger).Run)
+	cs.runInBackground(NewCertificateExpiryCollector(store, metrics, certificateExpiryInterval, log.Logger).Run)
+
+	return serverResources{
+		dataStore:        dataStore,
+		eventBroker:      eventBroker,
+		metrics:          metrics,
+		verificationKeys: verificationKeys,
+		checkClientCAs:   cs.clientCACheck(x509Loader, dataStore),
+	}, nil
+}
+
+// clientCACheck returns a check refusing a client CA that is also a CA
+// certificates are generated with: the default CA, configured CAs and CAs in
+// the store. The CAs are loaded on every check, so that reloading the client
+// CA is checked against CAs stored since the server started.
+func (cs configServer) clientCACheck(certsLoader types.CertsLoader, dataStore store.Store) ClientCACheck {
+	return func(clientCAs []*x509.Certificate) error {
+		var generationCAs []*x509.Certificate
+		for _, name := range append([]string{""}, cs.configuredCANames()...) {
+			certificate, _, err := certsLoader.LoadCerts(name)
+			if err != nil {
+				return errors.WrapError(err, "Failed to load CA to check client CA against")
+			}
+			generationCAs = append(generationCAs, certificate)
+		}
+
+		configurations, err := dataStore.GetByPath("")
+		if err != nil {
+			return errors.WrapError(err, "Failed to load stored CAs to check client CA against")
+		}
+
+		for _, configuration := range configurations {
+			if certificate, ok := storedCertificate(configuration.Value); ok && certificate.IsCA {
+				generationCAs = append(generationCAs, certificate)
+			}
+		}
+
+		return CheckClientCASeparate(clientCAs, generationCAs)
+	}
+}
+
+// configuredCANames returns the sorted names of the CAs configured in the
+// server configuration. Values cannot be written under these names.
+func (cs configServer) configuredCANames() []string {
+	var names []string
+	for name := range cs.config.CertificateAuthorities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// storeBackend names the backend of the store in metrics
+func (cs configServer) storeBackend() string {
+	if strings.EqualFold(cs.config.Store, "database") {
+		return cs.config.Database.Adapter
+	}
+	return "memory"
+}
+
+// readinessChecks check that the database can be reached, that the CAs
+// certificates are generated with can be loaded and that tokens can be
+// verified
+func (cs configServer) readinessChecks(dataStore store.Store, certsLoader types.CertsLoader, verificationKeys RefreshingVerificationKeys) map[string]ReadinessCheck {
+	return map[string]ReadinessCheck{
+		"store": func() error {
+			if pinger, ok := dataStore.(store.Pinger); ok {
+				return pinger.Ping()
+			}
+			return nil
+		},
+		"certificate_authorities": func() error {
+			if _, _, err := certsLoader.LoadCerts(""); err != nil {
+				return errors.WrapError(err, "Loading default CA")
+			}
+
+			for _, name := range cs.configuredCANames() {
+				if _, _, err := certsLoader.LoadCerts(name); err != nil {
+					return errors.WrapErrorf(err, "Loading CA '%s'", name)
+				}
+			}
+			return nil
+		},
+		"jwt_verification_keys": func() error {
+			if verificationKeys.Len() == 0 {
+				return errors.Error("No JWT verification keys loaded")
+			}
+			return nil
+		},
+	}
+}
+
+// verificationKeys loads the keys tokens are verified with from the
+// configured token keys URL, keys directory or key file
+func (cs configServer) verificationKeys() (RefreshingVerificationKeys, error) {
+	var loader KeyLoader
+
+	switch {
+	case cs.config.JwtTokenKeysURL != "":
+		client, err := NewJWKSClient(cs.config.JwtTokenKeysCACertificatePath)
+		if err != nil {
+			return RefreshingVerificationKeys{}, err
+		}
+		loader = NewJWKSKeyLoader(cs.config.JwtTokenKeysURL, client)
+	case cs.config.JwtVerificationKeysDir != "":
+		loader = NewDirectoryKeyLoader(cs.config.JwtVerificationKeysDir)
+	default:
+		loader = NewPEMFileKeyLoader(cs.config.JwtVerificationKeyPath)
+	}
+
+	return NewRefreshingVerificationKeys(loader, jwtKeysMinReload, log.Logger)
+}
+
+// authenticatedHandlers returns a function wrapping handlers so that they
+// only serve authenticated requests, recording them to the audit log when one
+// is configured. Handlers authorize the names they decode themselves.
+func (cs configServer) authenticatedHandlers(tokenValidator TokenValidator) (func(http.Handler) http.Handler, error) {
+	authenticators := cs.authenticators(tokenValidator)
+	authenticate := func(handler http.Handler) http.Handler {
+		return NewAuthenticationHandler(authenticators, handler)
+	}
+
+	if cs.config.AuditLogPath == "" {
+		return func(handler http.Handler) http.Handler {
+			return authenticate(handler)
+		}, nil
+	}
+
+	auditLog, err := NewFileAuditLog(cs.config.AuditLogPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(handler http.Handler) http.Handler {
+		return authenticate(NewAuditHandler(auditLog, handler, log.Logger))
+	}, nil
+}
+
+// authenticators authenticates requests by their verified client
+// certificate, when client certificates are configured, before their token
+// or API key
+func (cs configServer) authenticators(tokenValidator TokenValidator) []Authenticator {
+	var authenticators []Authenticator
+
+	if cs.config.ClientCertificates.CACertificatePath != "" {
+		authenticators = append(authenticators, NewCertificateAuthenticator(cs.config.ClientCertificates.Identities))
+	}
+
+	authenticators = append(authenticators, NewTokenAuthenticator(tokenValidator))
+
+	if len(cs.config.APIKeys) > 0 {
+		authenticators = append(authenticators, NewAPIKeyAuthenticator(cs.config.APIKeys))
+	}
+
+	return authenticators
+}
+
+// authorizer allows each name callers access by a permission granted to them
+// at runtime, or else by their scopes and, when a policy file is configured,
+// a policy
+func (cs configServer) authorizer(permissionStore store.PermissionStore) (Authorizer, error) {
+	permissionAuthorizer := NewPermissionAuthorizer(permissionStore)
+
+	if cs.config.PolicyFilePath == "" {
+		return NewAnyAuthorizers(NewScopeAuthorizer(), permissionAuthorizer), nil
+	}
+
+	policies, err := config.ParsePolicies(cs.config.PolicyFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	policyAuthorizer, err := NewPolicyAuthorizer(policies)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewAnyAuthorizers(NewAllAuthorizers(NewScopeAuthorizer(), policyAuthorizer), permissionAuthorizer), nil
+}
+
+// configureEvents returns the publisher writes are announced to. Stores that
+// publish through the database are used so that watchers of every config
+// server sharing it see every write; their events reach local watchers
+// through a listener.
+func (cs configServer) configureEvents(dataStore store.Store, eventBroker EventBroker) (store.EventPublisher, error) {
+	databasePublisher, ok := dataStore.(store.EventPublisher)
+	if !ok {
+		return eventBroker, nil
+	}
+
+	listener, err := store.NewPostgresEventListener(cs.config.Database, eventBroker, log.Logger)
+	if err != nil {
+		return nil, err
+	}
+	cs.runInBackground(listener.Run)
 
-	return nil
+	return databasePublisher, nil
 }
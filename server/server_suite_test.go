@@ -1,6 +1,8 @@
 package server_test
 
 import (
+	. "github.com/cloudfoundry/config-server/server/serverfakes"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
@@ -11,3 +13,10 @@ func TestServer(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Server Suite")
 }
+
+// allowingAuthorizer returns an Authorizer allowing every request
+func allowingAuthorizer() *FakeAuthorizer {
+	authorizer := &FakeAuthorizer{}
+	authorizer.AuthorizeReturns(true, nil)
+	return authorizer
+}
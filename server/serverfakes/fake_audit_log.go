@@ -0,0 +1,75 @@
+// This file was generated by counterfeiter
+package serverfakes
+
+import (
+	"github.com/cloudfoundry/config-server/server"
+	"sync"
+)
+
+type FakeAuditLog struct {
+	RecordStub        func(entry server.AuditEntry) error
+	recordMutex       sync.RWMutex
+	recordArgsForCall []struct {
+		entry server.AuditEntry
+	}
+	recordReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeAuditLog) Record(entry server.AuditEntry) error {
+	fake.recordMutex.Lock()
+	fake.recordArgsForCall = append(fake.recordArgsForCall, struct {
+		entry server.AuditEntry
+	}{entry})
+	fake.recordInvocation("Record", []interface{}{entry})
+	fake.recordMutex.Unlock()
+	if fake.RecordStub != nil {
+		return fake.RecordStub(entry)
+	} else {
+		return fake.recordReturns.result1
+	}
+}
+
+func (fake *FakeAuditLog) RecordCallCount() int {
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	return len(fake.recordArgsForCall)
+}
+
+func (fake *FakeAuditLog) RecordArgsForCall(i int) server.AuditEntry {
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	return fake.recordArgsForCall[i].entry
+}
+
+func (fake *FakeAuditLog) RecordReturns(result1 error) {
+	fake.RecordStub = nil
+	fake.recordReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeAuditLog) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.recordMutex.RLock()
+	defer fake.recordMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeAuditLog) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ server.AuditLog = new(FakeAuditLog)
@@ -0,0 +1,79 @@
+// This file was generated by counterfeiter
+package serverfakes
+
+import (
+	"github.com/cloudfoundry/config-server/server"
+	"sync"
+)
+
+type FakeAuthorizer struct {
+	AuthorizeStub        func(identity server.Identity, access server.AccessRequest) (bool, error)
+	authorizeMutex       sync.RWMutex
+	authorizeArgsForCall []struct {
+		identity server.Identity
+		access   server.AccessRequest
+	}
+	authorizeReturns struct {
+		result1 bool
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeAuthorizer) Authorize(identity server.Identity, access server.AccessRequest) (bool, error) {
+	fake.authorizeMutex.Lock()
+	fake.authorizeArgsForCall = append(fake.authorizeArgsForCall, struct {
+		identity server.Identity
+		access   server.AccessRequest
+	}{identity, access})
+	fake.recordInvocation("Authorize", []interface{}{identity, access})
+	fake.authorizeMutex.Unlock()
+	if fake.AuthorizeStub != nil {
+		return fake.AuthorizeStub(identity, access)
+	} else {
+		return fake.authorizeReturns.result1, fake.authorizeReturns.result2
+	}
+}
+
+func (fake *FakeAuthorizer) AuthorizeCallCount() int {
+	fake.authorizeMutex.RLock()
+	defer fake.authorizeMutex.RUnlock()
+	return len(fake.authorizeArgsForCall)
+}
+
+func (fake *FakeAuthorizer) AuthorizeArgsForCall(i int) (server.Identity, server.AccessRequest) {
+	fake.authorizeMutex.RLock()
+	defer fake.authorizeMutex.RUnlock()
+	return fake.authorizeArgsForCall[i].identity, fake.authorizeArgsForCall[i].access
+}
+
+func (fake *FakeAuthorizer) AuthorizeReturns(result1 bool, result2 error) {
+	fake.AuthorizeStub = nil
+	fake.authorizeReturns = struct {
+		result1 bool
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAuthorizer) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.authorizeMutex.RLock()
+	defer fake.authorizeMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeAuthorizer) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ server.Authorizer = new(FakeAuthorizer)
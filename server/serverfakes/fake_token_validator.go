@@ -7,19 +7,20 @@ import (
 )
 
 type FakeTokenValidator struct {
-	ValidateStub        func(token string) error
+	ValidateStub        func(token string) (server.Identity, error)
 	validateMutex       sync.RWMutex
 	validateArgsForCall []struct {
 		token string
 	}
 	validateReturns struct {
-		result1 error
+		result1 server.Identity
+		result2 error
 	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeTokenValidator) Validate(token string) error {
+func (fake *FakeTokenValidator) Validate(token string) (server.Identity, error) {
 	fake.validateMutex.Lock()
 	fake.validateArgsForCall = append(fake.validateArgsForCall, struct {
 		token string
@@ -29,7 +30,7 @@ func (fake *FakeTokenValidator) Validate(token string) error {
 	if fake.ValidateStub != nil {
 		return fake.ValidateStub(token)
 	} else {
-		return fake.validateReturns.result1
+		return fake.validateReturns.result1, fake.validateReturns.result2
 	}
 }
 
@@ -45,11 +46,12 @@ func (fake *FakeTokenValidator) ValidateArgsForCall(i int) string {
 	return fake.validateArgsForCall[i].token
 }
 
-func (fake *FakeTokenValidator) ValidateReturns(result1 error) {
+func (fake *FakeTokenValidator) ValidateReturns(result1 server.Identity, result2 error) {
 	fake.ValidateStub = nil
 	fake.validateReturns = struct {
-		result1 error
-	}{result1}
+		result1 server.Identity
+		result2 error
+	}{result1, result2}
 }
 
 func (fake *FakeTokenValidator) Invocations() map[string][][]interface{} {
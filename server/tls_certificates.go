@@ -0,0 +1,173 @@
+package server
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"sync"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// ClientCACheck returns an error when the certificates of a client CA must
+// not be used to verify client certificates
+type ClientCACheck func(clientCAs []*x509.Certificate) error
+
+// TLSCertificates keeps the server certificate and, when a client CA is
+// given, the CA client certificates are verified against. Reloading them
+// affects new connections only, so that certificates can be rotated without
+// dropping established ones.
+type TLSCertificates struct {
+	certificatePath string
+	privateKeyPath  string
+	clientCAPath    string
+	checkClientCAs  ClientCACheck
+	loaded          *loadedTLSCertificates
+	mutex           *sync.RWMutex
+}
+
+type loadedTLSCertificates struct {
+	certificate *tls.Certificate
+	clientCAs   *x509.CertPool
+}
+
+// NewTLSCertificates loads the certificates. Every client CA loaded, now and
+// on reload, must pass checkClientCAs unless it is nil.
+func NewTLSCertificates(certificatePath, privateKeyPath, clientCAPath string, checkClientCAs ClientCACheck) (TLSCertificates, error) {
+	certificates := TLSCertificates{
+		certificatePath: certificatePath,
+		privateKeyPath:  privateKeyPath,
+		clientCAPath:    clientCAPath,
+		checkClientCAs:  checkClientCAs,
+		loaded:          &loadedTLSCertificates{},
+		mutex:           &sync.RWMutex{},
+	}
+
+	if err := certificates.Reload(); err != nil {
+		return TLSCertificates{}, err
+	}
+
+	return certificates, nil
+}
+
+// Reload reads the certificate files again. The previous certificates are
+// kept when the files cannot be loaded or the client CA fails its check.
+func (c TLSCertificates) Reload() error {
+	certificate, err := tls.LoadX509KeyPair(c.certificatePath, c.privateKeyPath)
+	if err != nil {
+		return errors.WrapError(err, "Failed to load server certificate")
+	}
+
+	var clientCAs *x509.CertPool
+	if c.clientCAPath != "" {
+		clientCACertificates, err := readCertificates(c.clientCAPath)
+		if err != nil {
+			return err
+		}
+
+		if c.checkClientCAs != nil {
+			if err := c.checkClientCAs(clientCACertificates); err != nil {
+				return err
+			}
+		}
+
+		clientCAs = x509.NewCertPool()
+		for _, clientCA := range clientCACertificates {
+			clientCAs.AddCert(clientCA)
+		}
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	*c.loaded = loadedTLSCertificates{certificate: &certificate, clientCAs: clientCAs}
+
+	return nil
+}
+
+// Config returns a TLS configuration serving the latest loaded certificate.
+// When a client CA is given, clients are asked for a certificate signed by
+// the latest loaded client CA; clients without one can still authenticate
+// otherwise.
+func (c TLSCertificates) Config() *tls.Config {
+	config := &tls.Config{
+		NextProtos: []string{"h2", "http/1.1"},
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return c.current().certificate, nil
+		},
+	}
+
+	if c.clientCAPath == "" {
+		return config
+	}
+
+	config.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		clientConfig := config.Clone()
+		clientConfig.GetConfigForClient = nil
+		clientConfig.ClientCAs = c.current().clientCAs
+		clientConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		return clientConfig, nil
+	}
+
+	return config
+}
+
+func (c TLSCertificates) current() loadedTLSCertificates {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return *c.loaded
+}
+
+// CheckClientCASeparate returns an error when one of clientCAs shares its key
+// with, or issued, one of the CAs certificates are generated with. Callers
+// allowed to generate certificates could otherwise mint client certificates
+// authenticating as any identity.
+func CheckClientCASeparate(clientCAs []*x509.Certificate, generationCAs []*x509.Certificate) error {
+	for _, clientCA := range clientCAs {
+		for _, generationCA := range generationCAs {
+			if bytes.Equal(clientCA.RawSubjectPublicKeyInfo, generationCA.RawSubjectPublicKeyInfo) {
+				return errors.Errorf("Client CA certificate '%s' must not be a CA certificates are generated with", clientCA.Subject.CommonName)
+			}
+
+			if generationCA.CheckSignatureFrom(clientCA) == nil {
+				return errors.Errorf("Client CA certificate '%s' must not have issued CA '%s' certificates are generated with", clientCA.Subject.CommonName, generationCA.Subject.CommonName)
+			}
+		}
+	}
+
+	return nil
+}
+
+func readCertificates(path string) ([]*x509.Certificate, error) {
+	rest, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to read client CA certificate")
+	}
+
+	var certificates []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to parse client CA certificate")
+		}
+		certificates = append(certificates, certificate)
+	}
+
+	if len(certificates) == 0 {
+		return nil, errors.Error("Failed to parse client CA certificate")
+	}
+
+	return certificates, nil
+}
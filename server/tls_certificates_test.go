@@ -0,0 +1,226 @@
+package server_test
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/cloudfoundry/config-server/server"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func selfSignedCertificate(commonName string, notAfter time.Time) ([]byte, []byte) {
+	privateKey, _ := generateRSAKeyPair()
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, privateKey.Public(), privateKey)
+	Expect(err).ToNot(HaveOccurred())
+
+	certificatePEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	privateKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	return certificatePEM, privateKeyPEM
+}
+
+func writeSelfSignedCertificate(certificatePath, privateKeyPath, commonName string) {
+	certificatePEM, privateKeyPEM := selfSignedCertificate(commonName, time.Now().Add(time.Hour))
+
+	Expect(ioutil.WriteFile(certificatePath, certificatePEM, 0600)).To(Succeed())
+	Expect(ioutil.WriteFile(privateKeyPath, privateKeyPEM, 0600)).To(Succeed())
+}
+
+var _ = Describe("TLSCertificates", func() {
+	var (
+		tempDir         string
+		certificatePath string
+		privateKeyPath  string
+		clientCAPath    string
+	)
+
+	servedCommonName := func(config *tls.Config) string {
+		certificate, err := config.GetCertificate(&tls.ClientHelloInfo{})
+		Expect(err).ToNot(HaveOccurred())
+
+		parsed, err := x509.ParseCertificate(certificate.Certificate[0])
+		Expect(err).ToNot(HaveOccurred())
+		return parsed.Subject.CommonName
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "tls-certificates")
+		Expect(err).ToNot(HaveOccurred())
+
+		certificatePath = filepath.Join(tempDir, "server.crt")
+		privateKeyPath = filepath.Join(tempDir, "server.key")
+		clientCAPath = filepath.Join(tempDir, "client-ca.crt")
+
+		writeSelfSignedCertificate(certificatePath, privateKeyPath, "before")
+		writeSelfSignedCertificate(clientCAPath, filepath.Join(tempDir, "client-ca.key"), "client-ca-before")
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	It("returns an error when certificate cannot be loaded", func() {
+		_, err := NewTLSCertificates(filepath.Join(tempDir, "missing.crt"), privateKeyPath, "", nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Failed to load server certificate"))
+	})
+
+	It("returns an error when client CA cannot be parsed", func() {
+		Expect(ioutil.WriteFile(clientCAPath, []byte("not a certificate"), 0600)).To(Succeed())
+
+		_, err := NewTLSCertificates(certificatePath, privateKeyPath, clientCAPath, nil)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Failed to parse client CA certificate"))
+	})
+
+	It("serves the certificate loaded last", func() {
+		certificates, err := NewTLSCertificates(certificatePath, privateKeyPath, "", nil)
+		Expect(err).ToNot(HaveOccurred())
+		config := certificates.Config()
+
+		Expect(servedCommonName(config)).To(Equal("before"))
+		Expect(config.GetConfigForClient).To(BeNil())
+
+		writeSelfSignedCertificate(certificatePath, privateKeyPath, "after")
+		Expect(certificates.Reload()).To(Succeed())
+
+		Expect(servedCommonName(config)).To(Equal("after"))
+	})
+
+	It("keeps the previous certificate when reloading fails", func() {
+		certificates, err := NewTLSCertificates(certificatePath, privateKeyPath, "", nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(ioutil.WriteFile(certificatePath, []byte("not a certificate"), 0600)).To(Succeed())
+		Expect(certificates.Reload()).ToNot(Succeed())
+
+		Expect(servedCommonName(certificates.Config())).To(Equal("before"))
+	})
+
+	It("verifies client certificates against the client CA loaded last", func() {
+		certificates, err := NewTLSCertificates(certificatePath, privateKeyPath, clientCAPath, nil)
+		Expect(err).ToNot(HaveOccurred())
+		config := certificates.Config()
+
+		clientConfig, err := config.GetConfigForClient(&tls.ClientHelloInfo{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(clientConfig.ClientAuth).To(Equal(tls.VerifyClientCertIfGiven))
+		Expect(servedCommonName(clientConfig)).To(Equal("before"))
+		Expect(clientConfig.ClientCAs.Subjects()).To(HaveLen(1))
+		Expect(string(clientConfig.ClientCAs.Subjects()[0])).To(ContainSubstring("client-ca-before"))
+
+		writeSelfSignedCertificate(clientCAPath, filepath.Join(tempDir, "client-ca.key"), "client-ca-after")
+		Expect(certificates.Reload()).To(Succeed())
+
+		clientConfig, err = config.GetConfigForClient(&tls.ClientHelloInfo{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(clientConfig.ClientCAs.Subjects()[0])).To(ContainSubstring("client-ca-after"))
+	})
+
+	It("returns an error when the client CA fails its check", func() {
+		_, err := NewTLSCertificates(certificatePath, privateKeyPath, clientCAPath, func(clientCAs []*x509.Certificate) error {
+			Expect(clientCAs).To(HaveLen(1))
+			Expect(clientCAs[0].Subject.CommonName).To(Equal("client-ca-before"))
+			return errors.New("shared with a generation CA")
+		})
+		Expect(err).To(MatchError("shared with a generation CA"))
+	})
+
+	It("keeps the previous client CA when the reloaded one fails its check", func() {
+		certificates, err := NewTLSCertificates(certificatePath, privateKeyPath, clientCAPath, func(clientCAs []*x509.Certificate) error {
+			if clientCAs[0].Subject.CommonName == "client-ca-after" {
+				return errors.New("shared with a generation CA")
+			}
+			return nil
+		})
+		Expect(err).ToNot(HaveOccurred())
+
+		writeSelfSignedCertificate(certificatePath, privateKeyPath, "after")
+		writeSelfSignedCertificate(clientCAPath, filepath.Join(tempDir, "client-ca.key"), "client-ca-after")
+		Expect(certificates.Reload()).To(MatchError("shared with a generation CA"))
+
+		clientConfig, err := certificates.Config().GetConfigForClient(&tls.ClientHelloInfo{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(servedCommonName(clientConfig)).To(Equal("before"))
+		Expect(string(clientConfig.ClientCAs.Subjects()[0])).To(ContainSubstring("client-ca-before"))
+	})
+})
+
+var _ = Describe("CheckClientCASeparate", func() {
+	var (
+		clientCA    *x509.Certificate
+		clientCAKey interface{}
+	)
+
+	parseCertificate := func(certificatePEM []byte) *x509.Certificate {
+		block, _ := pem.Decode(certificatePEM)
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		Expect(err).ToNot(HaveOccurred())
+		return certificate
+	}
+
+	BeforeEach(func() {
+		certificatePEM, privateKeyPEM := selfSignedCertificate("client-ca", time.Now().Add(time.Hour))
+
+		clientCA = parseCertificate(certificatePEM)
+		block, _ := pem.Decode(privateKeyPEM)
+		var err error
+		clientCAKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("accepts a client CA separate from the CAs certificates are generated with", func() {
+		generationCA, _ := selfSignedCertificate("generation-ca", time.Now().Add(time.Hour))
+
+		Expect(CheckClientCASeparate([]*x509.Certificate{clientCA}, []*x509.Certificate{parseCertificate(generationCA)})).To(Succeed())
+	})
+
+	It("refuses a client CA certificates are generated with", func() {
+		err := CheckClientCASeparate([]*x509.Certificate{clientCA}, []*x509.Certificate{clientCA})
+
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Client CA certificate 'client-ca' must not be a CA certificates are generated with"))
+	})
+
+	It("refuses a client CA that issued a CA certificates are generated with", func() {
+		privateKey, _ := generateRSAKeyPair()
+		template := &x509.Certificate{
+			SerialNumber:          big.NewInt(2),
+			Subject:               pkix.Name{CommonName: "intermediate-ca"},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			IsCA:                  true,
+			BasicConstraintsValid: true,
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, clientCA, privateKey.Public(), clientCAKey)
+		Expect(err).ToNot(HaveOccurred())
+		intermediateCA, err := x509.ParseCertificate(der)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = CheckClientCASeparate([]*x509.Certificate{clientCA}, []*x509.Certificate{intermediateCA})
+
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("must not have issued CA 'intermediate-ca'"))
+	})
+})
@@ -1,5 +1,5 @@
 package server
 
 type TokenValidator interface {
-	Validate(token string) error
+	Validate(token string) (Identity, error)
 }
@@ -2,58 +2,223 @@ package server
 
 import (
 	"crypto/rsa"
-	"io/ioutil"
+	"encoding/json"
+	"strings"
+	"time"
 
 	"github.com/cloudfoundry/bosh-utils/errors"
 	"github.com/dgrijalva/jwt-go"
 )
 
-const (
-	expectedScope = "config_server.admin"
-)
+// identityClaims are checked in order for the name of the token's owner
+var identityClaims = []string{"user_name", "client_id", "sub"}
+
+// ClaimRequirements are checked on every token in addition to its signature
+type ClaimRequirements struct {
+	// Issuer is the required iss claim, if set
+	Issuer string
+	// Audience must be one of the aud claim, if set
+	Audience string
+	// ClockSkew is tolerated when checking exp, nbf and iat claims
+	ClockSkew time.Duration
+}
 
 type JwtTokenValidator struct {
-	verificationKey *rsa.PublicKey
+	verificationKeys VerificationKeys
+	requirements     ClaimRequirements
+}
+
+// NewJwtTokenValidator returns a validator of tokens signed with one of
+// verificationKeys, selected by the key ID in the token's header
+func NewJwtTokenValidator(verificationKeys VerificationKeys, requirements ClaimRequirements) JwtTokenValidator {
+	return JwtTokenValidator{verificationKeys: verificationKeys, requirements: requirements}
 }
 
-func NewJwtTokenValidator(jwtVerificationKeyPath string) (JwtTokenValidator, error) {
-	bytes, err := ioutil.ReadFile(jwtVerificationKeyPath)
+func NewJWTTokenValidatorWithKey(verificationKey *rsa.PublicKey) JwtTokenValidator {
+	return JwtTokenValidator{verificationKeys: NewStaticVerificationKeys(verificationKey)}
+}
+
+func (j JwtTokenValidator) Validate(tokenStr string) (Identity, error) {
+	parser := jwt.Parser{UseJSONNumber: true, SkipClaimsValidation: true}
+
+	token, err := parser.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
+		if !j.isValidSigningMethod(t) {
+			return nil, errors.Error("Invalid signing method")
+		}
+
+		kid, _ := t.Header["kid"].(string)
+		return j.verificationKeys.Key(kid)
+	})
 	if err != nil {
-		return JwtTokenValidator{}, errors.WrapError(err, "Failed to read JWT Verification key")
+		return Identity{}, errors.WrapError(err, "Validating token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return Identity{}, errors.Error("Validating token: Malformed claims")
+	}
+
+	if err := j.validateClaims(claims); err != nil {
+		return Identity{}, errors.WrapError(err, "Validating token")
 	}
 
-	verificationKey, err := jwt.ParseRSAPublicKeyFromPEM(bytes)
+	identity, err := identityFromClaims(claims)
 	if err != nil {
-		return JwtTokenValidator{}, errors.WrapError(err, "Failed to parse RSA public key from PEM")
+		return Identity{}, errors.WrapError(err, "Validating token")
 	}
 
-	return JwtTokenValidator{verificationKey: verificationKey}, nil
+	for _, scope := range configServerScopes {
+		if identity.HasScope(scope) {
+			return identity, nil
+		}
+	}
+
+	return Identity{}, errors.Errorf("Missing required scope: %s", strings.Join(configServerScopes, ", "))
 }
 
-func NewJWTTokenValidatorWithKey(verificationKey *rsa.PublicKey) JwtTokenValidator {
-	return JwtTokenValidator{verificationKey: verificationKey}
+func (j JwtTokenValidator) validateClaims(claims jwt.MapClaims) error {
+	now := time.Now()
+	skew := j.requirements.ClockSkew
+
+	expiresAt, found, err := timeClaim(claims, "exp")
+	if err != nil {
+		return err
+	}
+	if found && now.After(expiresAt.Add(skew)) {
+		return errors.Error("Token is expired")
+	}
+
+	notBefore, found, err := timeClaim(claims, "nbf")
+	if err != nil {
+		return err
+	}
+	if found && now.Add(skew).Before(notBefore) {
+		return errors.Error("Token is not valid yet")
+	}
+
+	issuedAt, found, err := timeClaim(claims, "iat")
+	if err != nil {
+		return err
+	}
+	if found && now.Add(skew).Before(issuedAt) {
+		return errors.Error("Token used before issued")
+	}
+
+	issuer, err := stringClaim(claims, "iss")
+	if err != nil {
+		return err
+	}
+	if j.requirements.Issuer != "" && issuer != j.requirements.Issuer {
+		return errors.Errorf("Token issuer is not '%s'", j.requirements.Issuer)
+	}
+
+	audiences, err := audienceClaim(claims)
+	if err != nil {
+		return err
+	}
+	if j.requirements.Audience != "" && !containsString(audiences, j.requirements.Audience) {
+		return errors.Errorf("Token audience does not include '%s'", j.requirements.Audience)
+	}
+
+	return nil
 }
 
-func (j JwtTokenValidator) Validate(tokenStr string) error {
-	token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
-		if !j.isValidSigningMethod(t) {
-			return nil, errors.Error("Invalid signing method")
+func identityFromClaims(claims jwt.MapClaims) (Identity, error) {
+	var identity Identity
+
+	names := map[string]string{}
+	for _, claim := range identityClaims {
+		name, err := stringClaim(claims, claim)
+		if err != nil {
+			return Identity{}, err
 		}
-		return j.verificationKey, nil
-	})
+		names[claim] = name
+
+		if identity.Name == "" {
+			identity.Name = name
+		}
+	}
+	identity.ClientID = names["client_id"]
+	identity.UserName = names["user_name"]
+
+	scopes, err := stringsClaim(claims, "scope")
+	if err != nil {
+		return Identity{}, err
+	}
+	identity.Scopes = scopes
+
+	return identity, nil
+}
+
+// timeClaim returns the time of a NumericDate claim and whether it is set
+func timeClaim(claims jwt.MapClaims, claim string) (time.Time, bool, error) {
+	value, found := claims[claim]
+	if !found {
+		return time.Time{}, false, nil
+	}
+
+	number, ok := value.(json.Number)
+	if !ok {
+		return time.Time{}, false, malformedClaimError(claim)
+	}
+
+	seconds, err := number.Float64()
 	if err != nil {
-		return errors.WrapError(err, "Validating token")
+		return time.Time{}, false, malformedClaimError(claim)
+	}
+
+	return time.Unix(int64(seconds), 0), true, nil
+}
+
+func stringClaim(claims jwt.MapClaims, claim string) (string, error) {
+	value, found := claims[claim]
+	if !found {
+		return "", nil
 	}
 
-	scopes := token.Claims.(jwt.MapClaims)["scope"].([]interface{})
+	str, ok := value.(string)
+	if !ok {
+		return "", malformedClaimError(claim)
+	}
 
-	for _, el := range scopes {
-		if el == expectedScope {
-			return nil
+	return str, nil
+}
+
+// audienceClaim returns the aud claim, which is either one string or an
+// array of strings
+func audienceClaim(claims jwt.MapClaims) ([]string, error) {
+	if audience, ok := claims["aud"].(string); ok {
+		return []string{audience}, nil
+	}
+
+	return stringsClaim(claims, "aud")
+}
+
+func stringsClaim(claims jwt.MapClaims, claim string) ([]string, error) {
+	value, found := claims[claim]
+	if !found {
+		return nil, nil
+	}
+
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil, malformedClaimError(claim)
+	}
+
+	var result []string
+	for _, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			return nil, malformedClaimError(claim)
 		}
+		result = append(result, str)
 	}
 
-	return errors.Errorf("Missing required scope: %s", expectedScope)
+	return result, nil
+}
+
+func malformedClaimError(claim string) error {
+	return errors.Errorf("Malformed '%s' claim", claim)
 }
 
 func (JwtTokenValidator) isValidSigningMethod(token *jwt.Token) bool {
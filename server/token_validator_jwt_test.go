@@ -6,7 +6,9 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"time"
 
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	"github.com/dgrijalva/jwt-go"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -37,10 +39,45 @@ var _ = Describe("JwtTokenValidator", func() {
 				signedToken, err := token.SignedString(privateKey)
 				Expect(err).ToNot(HaveOccurred())
 
-				err = jwtTokenValidator.Validate(signedToken)
+				_, err = jwtTokenValidator.Validate(signedToken)
 				Expect(err).ToNot(HaveOccurred())
 			})
 
+			It("returns the identity named by the user_name, client_id or sub claim", func() {
+				for _, claims := range []jwt.MapClaims{
+					{"user_name": "admin", "client_id": "cf", "sub": "1234"},
+					{"client_id": "admin", "sub": "1234"},
+					{"sub": "admin"},
+				} {
+					claims["scope"] = []string{"config_server.admin"}
+
+					signedToken, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(privateKey)
+					Expect(err).ToNot(HaveOccurred())
+
+					identity, err := jwtTokenValidator.Validate(signedToken)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(identity.Name).To(Equal("admin"))
+					Expect(identity.Scopes).To(Equal([]string{"config_server.admin"}))
+				}
+			})
+
+			It("accepts tokens with only operation specific scopes", func() {
+				token := jwt.NewWithClaims(
+					jwt.SigningMethodRS256,
+					jwt.MapClaims{
+						"client_id": "monitoring",
+						"scope":     []string{"openid", "config_server.read"},
+					},
+				)
+
+				signedToken, err := token.SignedString(privateKey)
+				Expect(err).ToNot(HaveOccurred())
+
+				identity, err := jwtTokenValidator.Validate(signedToken)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(identity).To(Equal(Identity{Name: "monitoring", ClientID: "monitoring", Scopes: []string{"openid", "config_server.read"}}))
+			})
+
 			It("returns error if non-rsa alg is used", func() {
 				token := jwt.NewWithClaims(
 					jwt.SigningMethodHS256,
@@ -52,12 +89,12 @@ var _ = Describe("JwtTokenValidator", func() {
 				signedToken, err := token.SignedString([]byte("secret"))
 				Expect(err).ToNot(HaveOccurred())
 
-				err = jwtTokenValidator.Validate(signedToken)
+				_, err = jwtTokenValidator.Validate(signedToken)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("Invalid signing method"))
 			})
 
-			It("returns error if token does not have config_server.admin scope", func() {
+			It("returns error if token does not have any config_server scope", func() {
 				token := jwt.NewWithClaims(
 					jwt.SigningMethodRS256,
 					jwt.MapClaims{
@@ -68,9 +105,138 @@ var _ = Describe("JwtTokenValidator", func() {
 				signedToken, err := token.SignedString(privateKey)
 				Expect(err).ToNot(HaveOccurred())
 
-				err = jwtTokenValidator.Validate(signedToken)
+				_, err = jwtTokenValidator.Validate(signedToken)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Missing required scope: config_server.admin, config_server.read, config_server.write, config_server.generate, config_server.delete"))
+			})
+
+			It("returns error if token does not have a scope claim", func() {
+				signedToken, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "admin"}).SignedString(privateKey)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = jwtTokenValidator.Validate(signedToken)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Missing required scope"))
+			})
+		})
+
+		Context("claims", func() {
+			validate := func(requirements ClaimRequirements, claims jwt.MapClaims) error {
+				if _, found := claims["scope"]; !found {
+					claims["scope"] = []string{"config_server.admin"}
+				}
+
+				signedToken, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(privateKey)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = NewJwtTokenValidator(NewStaticVerificationKeys(privateKey.Public().(*rsa.PublicKey)), requirements).Validate(signedToken)
+				return err
+			}
+
+			now := func(offset time.Duration) int64 {
+				return time.Now().Add(offset).Unix()
+			}
+
+			It("rejects expired tokens unless within clock skew", func() {
+				claims := jwt.MapClaims{"exp": now(-30 * time.Second)}
+
+				err := validate(ClaimRequirements{}, claims)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Validating token: Token is expired"))
+
+				Expect(validate(ClaimRequirements{ClockSkew: time.Minute}, claims)).To(Succeed())
+				Expect(validate(ClaimRequirements{}, jwt.MapClaims{"exp": now(time.Minute)})).To(Succeed())
+			})
+
+			It("rejects tokens used before their nbf unless within clock skew", func() {
+				claims := jwt.MapClaims{"nbf": now(30 * time.Second)}
+
+				err := validate(ClaimRequirements{}, claims)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Validating token: Token is not valid yet"))
+
+				Expect(validate(ClaimRequirements{ClockSkew: time.Minute}, claims)).To(Succeed())
+			})
+
+			It("rejects tokens used before they were issued unless within clock skew", func() {
+				claims := jwt.MapClaims{"iat": now(30 * time.Second)}
+
+				err := validate(ClaimRequirements{}, claims)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Validating token: Token used before issued"))
+
+				Expect(validate(ClaimRequirements{ClockSkew: time.Minute}, claims)).To(Succeed())
+			})
+
+			It("requires the configured issuer", func() {
+				requirements := ClaimRequirements{Issuer: "https://uaa.example.com/oauth/token"}
+
+				Expect(validate(requirements, jwt.MapClaims{"iss": "https://uaa.example.com/oauth/token"})).To(Succeed())
+
+				err := validate(requirements, jwt.MapClaims{"iss": "https://other-zone.uaa.example.com/oauth/token"})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Validating token: Token issuer is not 'https://uaa.example.com/oauth/token'"))
+
+				Expect(validate(requirements, jwt.MapClaims{})).ToNot(Succeed())
+			})
+
+			It("requires the configured audience", func() {
+				requirements := ClaimRequirements{Audience: "config_server"}
+
+				Expect(validate(requirements, jwt.MapClaims{"aud": "config_server"})).To(Succeed())
+				Expect(validate(requirements, jwt.MapClaims{"aud": []string{"director", "config_server"}})).To(Succeed())
+
+				err := validate(requirements, jwt.MapClaims{"aud": []string{"director"}})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Validating token: Token audience does not include 'config_server'"))
+
+				Expect(validate(requirements, jwt.MapClaims{})).ToNot(Succeed())
+			})
+
+			It("rejects malformed claims", func() {
+				for claim, value := range map[string]interface{}{
+					"exp":       "tomorrow",
+					"nbf":       true,
+					"iss":       42,
+					"aud":       []interface{}{"config_server", 42},
+					"client_id": []string{"admin"},
+					"scope":     "config_server.admin",
+				} {
+					err := validate(ClaimRequirements{}, jwt.MapClaims{claim: value})
+					Expect(err).To(HaveOccurred(), claim)
+					Expect(err.Error()).To(Equal(fmt.Sprintf("Validating token: Malformed '%s' claim", claim)))
+				}
+			})
+		})
+
+		Context("with several verification keys", func() {
+			It("verifies tokens with the key named by their header", func() {
+				otherPrivateKey, otherPublicKey := generateRSAKeyPair()
+				keys, err := NewRefreshingVerificationKeys(func() (map[string]*rsa.PublicKey, error) {
+					return map[string]*rsa.PublicKey{"key-1": privateKey.Public().(*rsa.PublicKey), "key-2": otherPublicKey}, nil
+				}, time.Hour, boshlog.NewLogger(boshlog.LevelNone))
+				Expect(err).ToNot(HaveOccurred())
+				jwtTokenValidator = NewJwtTokenValidator(keys, ClaimRequirements{})
+
+				token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"scope": []string{"config_server.admin"}})
+				token.Header["kid"] = "key-2"
+
+				signedToken, err := token.SignedString(otherPrivateKey)
+				Expect(err).ToNot(HaveOccurred())
+				_, err = jwtTokenValidator.Validate(signedToken)
+				Expect(err).ToNot(HaveOccurred())
+
+				signedToken, err = token.SignedString(privateKey)
+				Expect(err).ToNot(HaveOccurred())
+				_, err = jwtTokenValidator.Validate(signedToken)
+				Expect(err).To(HaveOccurred())
+
+				token.Header["kid"] = "key-3"
+				signedToken, err = token.SignedString(otherPrivateKey)
+				Expect(err).ToNot(HaveOccurred())
+				_, err = jwtTokenValidator.Validate(signedToken)
 				Expect(err).To(HaveOccurred())
-				Expect(err.Error()).To(ContainSubstring("Missing required scope: config_server.admin"))
+				Expect(err.Error()).To(ContainSubstring("Unknown verification key 'key-3'"))
 			})
 		})
 
@@ -87,7 +253,7 @@ var _ = Describe("JwtTokenValidator", func() {
 				signedToken, err := token.SignedString(differentPrivateKey)
 				Expect(err).ToNot(HaveOccurred())
 
-				err = jwtTokenValidator.Validate(signedToken)
+				_, err = jwtTokenValidator.Validate(signedToken)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("Validating token: crypto/rsa: verification error"))
 			})
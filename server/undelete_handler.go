@@ -0,0 +1,70 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cloudfoundry/config-server/store"
+)
+
+type undeleteHandler struct {
+	store      store.Store
+	retention  time.Duration
+	authorizer Authorizer
+}
+
+// NewUndeleteHandler returns a handler restoring names deleted within
+// retention. Versions deleted longer ago are not restored even when they have
+// not been purged yet.
+func NewUndeleteHandler(store store.Store, retention time.Duration, authorizer Authorizer) (http.Handler, error) {
+	if store == nil {
+		return nil, errors.Error("Data store must be set")
+	}
+	if authorizer == nil {
+		return nil, errors.Error("Authorizer must be set")
+	}
+	return undeleteHandler{store: store, retention: retention, authorizer: authorizer}, nil
+}
+
+func (handler undeleteHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	if req.Method != "POST" {
+		http.Error(resWriter, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := req.URL.Query().Get("name")
+	if isNameValid, nameError := isValidName(name); isNameValid == false {
+		http.Error(resWriter, nameError.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !authorizeRequest(handler.authorizer, resWriter, req, []string{name}, nil) {
+		return
+	}
+
+	undeleted, err := handler.store.Undelete(name, handler.retention)
+	if err != nil {
+		http.Error(resWriter, err.Error(), writeErrorStatus(err))
+		return
+	}
+
+	if undeleted == 0 {
+		http.Error(resWriter, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	configuration, err := handler.store.GetCurrentByName(name)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result, err := configuration.StringifiedJSON()
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	respond(resWriter, result, http.StatusOK)
+}
@@ -0,0 +1,95 @@
+package server_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/cloudfoundry/config-server/server"
+	"github.com/cloudfoundry/config-server/store"
+	. "github.com/cloudfoundry/config-server/store/storefakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UndeleteHandler", func() {
+
+	Describe("Given a nil store", func() {
+		It("should return an error", func() {
+			_, err := NewUndeleteHandler(nil, time.Hour, allowingAuthorizer())
+			Expect(err.Error()).To(Equal("Data store must be set"))
+		})
+	})
+
+	Describe("Given a handler with store", func() {
+		var undeleteHandler http.Handler
+		var memoryStore store.MemoryStore
+
+		undelete := func(method string, path string) *httptest.ResponseRecorder {
+			req, _ := http.NewRequest(method, path, nil)
+			recorder := httptest.NewRecorder()
+			undeleteHandler.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		BeforeEach(func() {
+			memoryStore = store.NewMemoryStore()
+			undeleteHandler, _ = NewUndeleteHandler(memoryStore, time.Hour, allowingAuthorizer())
+
+			memoryStore.Put("bla", `{"value":"old"}`, store.Metadata{})
+			memoryStore.Put("bla", `{"value":"latest"}`, store.Metadata{})
+			memoryStore.Delete("bla")
+		})
+
+		It("should return 405 Method Not Allowed for methods other than POST", func() {
+			recorder := undelete("GET", "/v1/undelete?name=bla")
+
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		})
+
+		It("restores a deleted name and returns its latest value", func() {
+			recorder := undelete("POST", "/v1/undelete?name=bla")
+
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(withoutCreatedAt(recorder.Body.String())).To(Equal(`{"id":"1","name":"bla","value":"latest"}`))
+
+			values, _ := memoryStore.GetByName("bla")
+			Expect(len(values)).To(Equal(2))
+		})
+
+		It("should return 404 Not Found when values were deleted longer than retention ago", func() {
+			undeleteHandler, _ = NewUndeleteHandler(memoryStore, time.Nanosecond, allowingAuthorizer())
+			time.Sleep(time.Millisecond)
+
+			recorder := undelete("POST", "/v1/undelete?name=bla")
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(memoryStore.GetByName("bla")).To(BeEmpty())
+		})
+
+		It("should return 404 Not Found when name has no deleted values", func() {
+			recorder := undelete("POST", "/v1/undelete?name=other")
+
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("should return 400 Bad Request when name is invalid", func() {
+			recorder := undelete("POST", "/v1/undelete?name=")
+
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("Name must consist of alphanumeric, underscores, dashes, and forward slashes"))
+		})
+
+		It("should return 500 Internal Server Error when store errors", func() {
+			mockStore := &FakeStore{}
+			mockStore.UndeleteReturns(0, errors.New("Kaboom!"))
+			undeleteHandler, _ = NewUndeleteHandler(mockStore, time.Hour, allowingAuthorizer())
+
+			recorder := undelete("POST", "/v1/undelete?name=bla")
+
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
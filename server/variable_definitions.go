@@ -0,0 +1,147 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// variableDefinition is a single entry of a BOSH manifest `variables:` block.
+type variableDefinition struct {
+	Name    string
+	Type    string
+	Options interface{}
+	Mode    generationMode
+}
+
+func readVariableDefinitions(jsonMap map[string]interface{}) ([]variableDefinition, error) {
+	variables, ok := jsonMap["variables"].([]interface{})
+	if !ok {
+		return nil, errors.Error("JSON request body key 'variables' must be an array")
+	}
+
+	var definitions []variableDefinition
+	seenNames := map[string]bool{}
+
+	for _, variable := range variables {
+		variableMap, ok := variable.(map[string]interface{})
+		if !ok {
+			return nil, errors.Error("JSON request body key 'variables' must only contain objects")
+		}
+
+		name, err := getStringValueFromJSONBody(variableMap, "name")
+		if err != nil {
+			return nil, err
+		}
+
+		if isNameValid, nameError := isValidName(name); isNameValid == false {
+			return nil, nameError
+		}
+
+		if seenNames[name] {
+			return nil, errors.Errorf("Variable '%s' is defined more than once", name)
+		}
+		seenNames[name] = true
+
+		generatorType, err := getStringValueFromJSONBody(variableMap, "type")
+		if err != nil {
+			return nil, err
+		}
+
+		mode, err := readGenerationMode(variableMap)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := readCAName(generatorType, variableMap["options"]); err != nil {
+			return nil, err
+		}
+
+		definitions = append(definitions, variableDefinition{
+			Name:    name,
+			Type:    generatorType,
+			Options: variableMap["options"],
+			Mode:    mode,
+		})
+	}
+
+	return definitions, nil
+}
+
+// orderVariableDefinitions sorts definitions so that every certificate comes
+// after the CA it references through its `ca` option. Definitions without
+// dependencies keep their relative order.
+func orderVariableDefinitions(definitions []variableDefinition) ([]variableDefinition, error) {
+	byName := map[string]variableDefinition{}
+	for _, definition := range definitions {
+		byName[definition.Name] = definition
+	}
+
+	var ordered []variableDefinition
+	visited := map[string]bool{}
+	visiting := map[string]bool{}
+
+	var visit func(definition variableDefinition, path []string) error
+	visit = func(definition variableDefinition, path []string) error {
+		if visited[definition.Name] {
+			return nil
+		}
+
+		path = append(path, definition.Name)
+		if visiting[definition.Name] {
+			return errors.Errorf("Variables have a circular dependency: %s", strings.Join(path, " -> "))
+		}
+		visiting[definition.Name] = true
+
+		if caName := definition.caName(); caName != "" {
+			if ca, exists := byName[caName]; exists {
+				if err := visit(ca, path); err != nil {
+					return err
+				}
+			}
+		}
+
+		visiting[definition.Name] = false
+		visited[definition.Name] = true
+		ordered = append(ordered, definition)
+		return nil
+	}
+
+	for _, definition := range definitions {
+		if err := visit(definition, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
+
+func (definition variableDefinition) caName() string {
+	caName, _ := readCAName(definition.Type, definition.Options)
+	return caName
+}
+
+// readCAName returns the name of the CA a value of generatorType is signed
+// with according to its parameters, or an empty string for the default CA.
+func readCAName(generatorType string, parameters interface{}) (string, error) {
+	if generatorType != "certificate" {
+		return "", nil
+	}
+
+	options, ok := parameters.(map[string]interface{})
+	if !ok {
+		return "", nil
+	}
+
+	value, exists := options["ca"]
+	if !exists {
+		return "", nil
+	}
+
+	caName, ok := value.(string)
+	if !ok {
+		return "", errors.Error("Parameter 'ca' must be a string")
+	}
+
+	return caName, nil
+}
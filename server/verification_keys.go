@@ -0,0 +1,235 @@
+package server
+
+import (
+	"crypto/rsa"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/dgrijalva/jwt-go"
+)
+
+const verificationKeysLogTag = "VerificationKeys"
+
+// VerificationKeys finds the key a token was signed with by the key ID in
+// its header
+type VerificationKeys interface {
+	Key(kid string) (*rsa.PublicKey, error)
+}
+
+// KeyLoader returns verification keys by key ID. A key with an empty ID
+// verifies tokens naming any key.
+type KeyLoader func() (map[string]*rsa.PublicKey, error)
+
+type staticVerificationKeys struct {
+	key *rsa.PublicKey
+}
+
+// NewStaticVerificationKeys returns VerificationKeys verifying every token
+// with key
+func NewStaticVerificationKeys(key *rsa.PublicKey) VerificationKeys {
+	return staticVerificationKeys{key: key}
+}
+
+func (k staticVerificationKeys) Key(string) (*rsa.PublicKey, error) {
+	return k.key, nil
+}
+
+// RefreshingVerificationKeys keeps the keys returned by a loader. They are
+// reloaded periodically by Run and, at most once per minimum reload interval,
+// when a token names a key that is not known yet.
+type RefreshingVerificationKeys struct {
+	load              KeyLoader
+	minReloadInterval time.Duration
+	keys              map[string]*rsa.PublicKey
+	loadedAt          *time.Time
+	mutex             *sync.RWMutex
+	logger            boshlog.Logger
+}
+
+func NewRefreshingVerificationKeys(load KeyLoader, minReloadInterval time.Duration, logger boshlog.Logger) (RefreshingVerificationKeys, error) {
+	keys := RefreshingVerificationKeys{
+		load:              load,
+		minReloadInterval: minReloadInterval,
+		keys:              map[string]*rsa.PublicKey{},
+		loadedAt:          &time.Time{},
+		mutex:             &sync.RWMutex{},
+		logger:            logger,
+	}
+
+	if err := keys.Reload(); err != nil {
+		return RefreshingVerificationKeys{}, err
+	}
+
+	return keys, nil
+}
+
+func (k RefreshingVerificationKeys) Key(kid string) (*rsa.PublicKey, error) {
+	k.mutex.RLock()
+	key, found := k.lookup(kid)
+	k.mutex.RUnlock()
+
+	if found {
+		return key, nil
+	}
+
+	if k.reserveReload() {
+		if err := k.replaceKeys(); err != nil {
+			k.logger.Error(verificationKeysLogTag, "Failed to reload verification keys: %s", err.Error())
+		}
+
+		k.mutex.RLock()
+		key, found = k.lookup(kid)
+		k.mutex.RUnlock()
+
+		if found {
+			return key, nil
+		}
+	}
+
+	return nil, errors.Errorf("Unknown verification key '%s'", kid)
+}
+
+// Len returns the number of keys loaded
+func (k RefreshingVerificationKeys) Len() int {
+	k.mutex.RLock()
+	defer k.mutex.RUnlock()
+
+	return len(k.keys)
+}
+
+// Reload replaces the keys with those returned by the loader. Keys are kept
+// when loading fails.
+func (k RefreshingVerificationKeys) Reload() error {
+	k.mutex.Lock()
+	*k.loadedAt = time.Now()
+	k.mutex.Unlock()
+
+	return k.replaceKeys()
+}
+
+// reserveReload reports whether keys may be reloaded for an unknown key ID,
+// keeping other requests from reloading them until the interval passed
+func (k RefreshingVerificationKeys) reserveReload() bool {
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	if time.Since(*k.loadedAt) < k.minReloadInterval {
+		return false
+	}
+
+	*k.loadedAt = time.Now()
+	return true
+}
+
+func (k RefreshingVerificationKeys) replaceKeys() error {
+	keys, err := k.load()
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		return errors.Error("No verification keys found")
+	}
+
+	k.mutex.Lock()
+	defer k.mutex.Unlock()
+
+	for kid := range k.keys {
+		delete(k.keys, kid)
+	}
+	for kid, key := range keys {
+		k.keys[kid] = key
+	}
+
+	return nil
+}
+
+// Run reloads the keys every interval until stop is closed
+func (k RefreshingVerificationKeys) Run(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := k.Reload(); err != nil {
+				k.logger.Error(verificationKeysLogTag, "Failed to reload verification keys: %s", err.Error())
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (k RefreshingVerificationKeys) lookup(kid string) (*rsa.PublicKey, bool) {
+	if key, found := k.keys[kid]; found {
+		return key, true
+	}
+
+	if key, found := k.keys[""]; found {
+		return key, true
+	}
+
+	if kid == "" && len(k.keys) == 1 {
+		for _, key := range k.keys {
+			return key, true
+		}
+	}
+
+	return nil, false
+}
+
+// NewPEMFileKeyLoader returns a KeyLoader reading one RSA public key that
+// verifies every token
+func NewPEMFileKeyLoader(path string) KeyLoader {
+	return func() (map[string]*rsa.PublicKey, error) {
+		key, err := readRSAPublicKey(path)
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]*rsa.PublicKey{"": key}, nil
+	}
+}
+
+// NewDirectoryKeyLoader returns a KeyLoader reading every '.pem' file of a
+// directory. Each key's ID is its file name without the extension.
+func NewDirectoryKeyLoader(dir string) KeyLoader {
+	return func() (map[string]*rsa.PublicKey, error) {
+		paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+		if err != nil {
+			return nil, errors.WrapError(err, "Failed to list JWT Verification keys")
+		}
+
+		keys := map[string]*rsa.PublicKey{}
+		for _, path := range paths {
+			key, err := readRSAPublicKey(path)
+			if err != nil {
+				return nil, err
+			}
+
+			keys[strings.TrimSuffix(filepath.Base(path), ".pem")] = key
+		}
+
+		return keys, nil
+	}
+}
+
+func readRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.WrapError(err, "Failed to read JWT Verification key")
+	}
+
+	key, err := jwt.ParseRSAPublicKeyFromPEM(bytes)
+	if err != nil {
+		return nil, errors.WrapErrorf(err, "Failed to parse RSA public key from PEM '%s'", filepath.Base(path))
+	}
+
+	return key, nil
+}
@@ -0,0 +1,203 @@
+package server_test
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	. "github.com/cloudfoundry/config-server/server"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func publicKeyPEM(key *rsa.PublicKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	Expect(err).ToNot(HaveOccurred())
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+var _ = Describe("VerificationKeys", func() {
+	var (
+		tempDir string
+		logger  boshlog.Logger
+		keyA    *rsa.PublicKey
+		keyB    *rsa.PublicKey
+	)
+
+	writeKey := func(name string, key *rsa.PublicKey) string {
+		path := filepath.Join(tempDir, name)
+		Expect(ioutil.WriteFile(path, publicKeyPEM(key), 0600)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "verification-keys")
+		Expect(err).ToNot(HaveOccurred())
+
+		logger = boshlog.NewLogger(boshlog.LevelNone)
+		_, keyA = generateRSAKeyPair()
+		_, keyB = generateRSAKeyPair()
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	Describe("PEMFileKeyLoader", func() {
+		It("verifies tokens naming any key with the key of the file", func() {
+			keys, err := NewRefreshingVerificationKeys(NewPEMFileKeyLoader(writeKey("key.pem", keyA)), time.Hour, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(keys.Key("")).To(Equal(keyA))
+			Expect(keys.Key("some-key")).To(Equal(keyA))
+		})
+
+		It("returns an error when file cannot be read", func() {
+			_, err := NewRefreshingVerificationKeys(NewPEMFileKeyLoader(filepath.Join(tempDir, "missing.pem")), time.Hour, logger)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Failed to read JWT Verification key"))
+		})
+	})
+
+	Describe("DirectoryKeyLoader", func() {
+		var keys RefreshingVerificationKeys
+
+		BeforeEach(func() {
+			writeKey("key-a.pem", keyA)
+			writeKey("key-b.pem", keyB)
+			Expect(ioutil.WriteFile(filepath.Join(tempDir, "README"), []byte("not a key"), 0600)).To(Succeed())
+
+			var err error
+			keys, err = NewRefreshingVerificationKeys(NewDirectoryKeyLoader(tempDir), time.Hour, logger)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("selects keys by their file name", func() {
+			Expect(keys.Key("key-a")).To(Equal(keyA))
+			Expect(keys.Key("key-b")).To(Equal(keyB))
+		})
+
+		It("returns an error for unknown keys", func() {
+			_, err := keys.Key("key-c")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Unknown verification key 'key-c'"))
+
+			_, err = keys.Key("")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("picks up added and removed keys when reloaded", func() {
+			Expect(os.Remove(filepath.Join(tempDir, "key-a.pem"))).To(Succeed())
+			writeKey("key-c.pem", keyA)
+
+			Expect(keys.Reload()).To(Succeed())
+
+			_, err := keys.Key("key-a")
+			Expect(err).To(HaveOccurred())
+			Expect(keys.Key("key-c")).To(Equal(keyA))
+		})
+
+		It("returns an error when directory has no keys", func() {
+			emptyDir := filepath.Join(tempDir, "empty")
+			Expect(os.Mkdir(emptyDir, 0700)).To(Succeed())
+
+			_, err := NewRefreshingVerificationKeys(NewDirectoryKeyLoader(emptyDir), time.Hour, logger)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("No verification keys found"))
+		})
+	})
+
+	Describe("RefreshingVerificationKeys", func() {
+		var loads int
+
+		loader := func(results ...map[string]*rsa.PublicKey) KeyLoader {
+			return func() (map[string]*rsa.PublicKey, error) {
+				result := results[loads]
+				if loads < len(results)-1 {
+					loads++
+				}
+				if result == nil {
+					return nil, errors.New("Kaboom!")
+				}
+				return result, nil
+			}
+		}
+
+		BeforeEach(func() {
+			loads = 0
+		})
+
+		It("reloads keys when a token names an unknown key", func() {
+			keys, err := NewRefreshingVerificationKeys(loader(
+				map[string]*rsa.PublicKey{"key-a": keyA},
+				map[string]*rsa.PublicKey{"key-a": keyA, "key-b": keyB},
+			), 0, logger)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(keys.Len()).To(Equal(1))
+
+			Expect(keys.Key("key-b")).To(Equal(keyB))
+			Expect(keys.Len()).To(Equal(2))
+		})
+
+		It("does not reload keys more often than the minimum reload interval", func() {
+			keys, err := NewRefreshingVerificationKeys(loader(
+				map[string]*rsa.PublicKey{"key-a": keyA},
+				map[string]*rsa.PublicKey{"key-a": keyA, "key-b": keyB},
+			), time.Hour, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = keys.Key("key-b")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("keeps keys when reloading fails", func() {
+			keys, err := NewRefreshingVerificationKeys(loader(
+				map[string]*rsa.PublicKey{"key-a": keyA},
+				nil,
+			), 0, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(keys.Reload()).ToNot(Succeed())
+			Expect(keys.Key("key-a")).To(Equal(keyA))
+		})
+
+		It("uses the only key for tokens naming no key", func() {
+			keys, err := NewRefreshingVerificationKeys(loader(map[string]*rsa.PublicKey{"key-a": keyA}), time.Hour, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(keys.Key("")).To(Equal(keyA))
+		})
+
+		It("reloads keys periodically until stopped", func() {
+			keys, err := NewRefreshingVerificationKeys(loader(
+				map[string]*rsa.PublicKey{"key-a": keyA},
+				map[string]*rsa.PublicKey{"key-b": keyB},
+			), time.Hour, logger)
+			Expect(err).ToNot(HaveOccurred())
+
+			stop := make(chan struct{})
+			done := make(chan struct{})
+			go func() {
+				keys.Run(10*time.Millisecond, stop)
+				close(done)
+			}()
+
+			Eventually(func() error {
+				_, err := keys.Key("key-b")
+				return err
+			}).Should(Succeed())
+
+			close(stop)
+			Eventually(done).Should(BeClosed())
+		})
+	})
+})
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// watchKeepAliveInterval keeps idle streams from being closed by proxies
+const watchKeepAliveInterval = 15 * time.Second
+
+type watchHandler struct {
+	broker     EventBroker
+	authorizer Authorizer
+}
+
+// NewWatchHandler returns a handler streaming events published to broker as
+// Server-Sent Events
+func NewWatchHandler(broker EventBroker, authorizer Authorizer) http.Handler {
+	return watchHandler{broker: broker, authorizer: authorizer}
+}
+
+func (handler watchHandler) ServeHTTP(resWriter http.ResponseWriter, req *http.Request) {
+	if req.Method != "GET" {
+		http.Error(resWriter, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, err := readWatchFilter(req)
+	if err != nil {
+		http.Error(resWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if !authorizeRequest(handler.authorizer, resWriter, req, filter.names(), filter.paths()) {
+		return
+	}
+
+	flusher, ok := resWriter.(http.Flusher)
+	if !ok {
+		http.Error(resWriter, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	events, unsubscribe := handler.broker.Subscribe(filter.matches)
+	defer unsubscribe()
+
+	resWriter.Header().Set("Content-Type", "text/event-stream")
+	resWriter.Header().Set("Cache-Control", "no-cache")
+	resWriter.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(watchKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+
+			data, err := json.Marshal(event)
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(resWriter, "event: %s\ndata: %s\n\n", event.Type, data)
+		case <-keepAlive.C:
+			fmt.Fprint(resWriter, ": keep-alive\n\n")
+		case <-req.Context().Done():
+			return
+		}
+
+		flusher.Flush()
+	}
+}
+
+// watchFilter selects the events of either a name or the names under a path
+type watchFilter struct {
+	name string
+	path string
+}
+
+// readWatchFilter accepts either a name, matched exactly, or a path, matched
+// as a prefix of names
+func readWatchFilter(req *http.Request) (watchFilter, error) {
+	query := req.URL.Query()
+	filter := watchFilter{name: query.Get("name"), path: query.Get("path")}
+
+	if (filter.name == "") == (filter.path == "") {
+		return filter, errors.Error("Query parameter 'name' or 'path' is required, but not both")
+	}
+
+	if filter.name != "" {
+		if isNameValid, nameError := isValidName(filter.name); !isNameValid {
+			return filter, nameError
+		}
+		return filter, nil
+	}
+
+	if isPathValid, pathError := isValidPath(filter.path); !isPathValid {
+		return filter, pathError
+	}
+	return filter, nil
+}
+
+func (f watchFilter) matches(eventName string) bool {
+	if f.name != "" {
+		return eventName == f.name
+	}
+	return pathCovers(f.path, eventName)
+}
+
+func (f watchFilter) names() []string {
+	if f.name == "" {
+		return nil
+	}
+	return []string{f.name}
+}
+
+func (f watchFilter) paths() []string {
+	if f.path == "" {
+		return nil
+	}
+	return []string{f.path}
+}
@@ -0,0 +1,99 @@
+package server_test
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/cloudfoundry/config-server/server"
+	"github.com/cloudfoundry/config-server/store"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WatchHandler", func() {
+	var (
+		broker EventBroker
+		server *httptest.Server
+	)
+
+	BeforeEach(func() {
+		broker = NewEventBroker()
+		server = httptest.NewServer(NewWatchHandler(broker, allowingAuthorizer()))
+	})
+
+	AfterEach(func() {
+		server.CloseClientConnections()
+		server.Close()
+	})
+
+	watch := func(query string) *http.Response {
+		res, err := http.Get(server.URL + "/v1/watch?" + query)
+		Expect(err).ToNot(HaveOccurred())
+		return res
+	}
+
+	readEvent := func(reader *bufio.Reader) string {
+		var event string
+		for {
+			line, err := reader.ReadString('\n')
+			Expect(err).ToNot(HaveOccurred())
+			if line == "\n" {
+				return event
+			}
+			event += line
+		}
+	}
+
+	It("streams events for names under the path", func() {
+		res := watch("path=/deployments/cf/")
+		defer res.Body.Close()
+
+		Expect(res.StatusCode).To(Equal(http.StatusOK))
+		Expect(res.Header.Get("Content-Type")).To(Equal("text/event-stream"))
+
+		broker.Publish(store.Event{Type: store.EventPut, ID: "1", Name: "/deployments/bosh/password"})
+		broker.Publish(store.Event{Type: store.EventPut, ID: "2", Name: "/deployments/cf/password"})
+		broker.Publish(store.Event{Type: store.EventDelete, Name: "/deployments/cf/password"})
+
+		reader := bufio.NewReader(res.Body)
+		Expect(readEvent(reader)).To(Equal("event: put\ndata: {\"type\":\"put\",\"id\":\"2\",\"name\":\"/deployments/cf/password\"}\n"))
+		Expect(readEvent(reader)).To(Equal("event: delete\ndata: {\"type\":\"delete\",\"name\":\"/deployments/cf/password\"}\n"))
+	})
+
+	It("does not stream events for names that only share a prefix with the path", func() {
+		res := watch("path=/team")
+		defer res.Body.Close()
+
+		broker.Publish(store.Event{Type: store.EventPut, ID: "1", Name: "/team-b/secret"})
+		broker.Publish(store.Event{Type: store.EventPut, ID: "2", Name: "/team/secret"})
+
+		Expect(readEvent(bufio.NewReader(res.Body))).To(ContainSubstring(`"id":"2"`))
+	})
+
+	It("streams events for a single name", func() {
+		res := watch("name=/deployments/cf/password")
+		defer res.Body.Close()
+
+		broker.Publish(store.Event{Type: store.EventPut, ID: "1", Name: "/deployments/cf/password_old"})
+		broker.Publish(store.Event{Type: store.EventPut, ID: "2", Name: "/deployments/cf/password"})
+
+		Expect(readEvent(bufio.NewReader(res.Body))).To(ContainSubstring(`"id":"2"`))
+	})
+
+	It("should return 405 Method Not Allowed for methods other than GET", func() {
+		res, err := http.Post(server.URL+"/v1/watch?path=/", "application/json", nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(res.StatusCode).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("should return 400 Bad Request unless exactly one of name or path is given", func() {
+		for _, query := range []string{"", "name=a&path=/b", "path=/deployments/c%20f/", "name=a!"} {
+			res := watch(query)
+			res.Body.Close()
+
+			Expect(res.StatusCode).To(Equal(http.StatusBadRequest))
+		}
+	})
+})
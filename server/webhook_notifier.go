@@ -0,0 +1,240 @@
+package server
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/cloudfoundry/config-server/config"
+	"github.com/cloudfoundry/config-server/store"
+)
+
+const (
+	webhookLogTag          = "WebhookNotifier"
+	webhookQueueSize       = 256
+	webhookTimeout         = 10 * time.Second
+	WebhookSignatureHeader = "X-Config-Server-Signature"
+	WebhookTimestampHeader = "X-Config-Server-Timestamp"
+)
+
+var errWebhookNotifierStopped = errors.Error("Notifier stopped")
+
+type webhook struct {
+	config        config.WebhookConfig
+	notifications chan store.Event
+}
+
+type deadLetter struct {
+	URL      string      `json:"url"`
+	Event    store.Event `json:"event"`
+	Attempts int         `json:"attempts"`
+	Error    string      `json:"error"`
+	FailedAt string      `json:"failed_at"`
+}
+
+// WebhookNotifier POSTs events to the webhooks registered for their names.
+// Each webhook is notified in order, independently of the others. Failed
+// notifications are retried with exponential backoff and, once attempts run
+// out or the notifier stops, appended to the dead-letter file.
+type WebhookNotifier struct {
+	webhooks       []webhook
+	client         *http.Client
+	retryBackoff   time.Duration
+	deadLetterPath string
+	deadLetterLock *sync.Mutex
+	logger         boshlog.Logger
+}
+
+func NewWebhookNotifier(webhookConfigs []config.WebhookConfig, retryBackoff time.Duration, deadLetterPath string, logger boshlog.Logger) WebhookNotifier {
+	var webhooks []webhook
+	for _, webhookConfig := range webhookConfigs {
+		webhooks = append(webhooks, webhook{
+			config:        webhookConfig,
+			notifications: make(chan store.Event, webhookQueueSize),
+		})
+	}
+
+	return WebhookNotifier{
+		webhooks:       webhooks,
+		client:         &http.Client{Timeout: webhookTimeout},
+		retryBackoff:   retryBackoff,
+		deadLetterPath: deadLetterPath,
+		deadLetterLock: &sync.Mutex{},
+		logger:         logger,
+	}
+}
+
+// Publish queues event for every webhook registered for its name. Events
+// that do not fit in a webhook's queue are dead-lettered right away.
+func (n WebhookNotifier) Publish(event store.Event) error {
+	for _, webhook := range n.webhooks {
+		if !strings.HasPrefix(event.Name, webhook.config.Prefix) {
+			continue
+		}
+
+		select {
+		case webhook.notifications <- event:
+		default:
+			n.recordDeadLetter(webhook, event, 0, errors.Error("Notification queue is full"))
+		}
+	}
+
+	return nil
+}
+
+// Run sends queued notifications until stop is closed. It returns once the
+// notifications that were still queued are recorded as dead letters.
+func (n WebhookNotifier) Run(stop <-chan struct{}) {
+	var workers sync.WaitGroup
+
+	for i := range n.webhooks {
+		workers.Add(1)
+		go func(webhook webhook) {
+			defer workers.Done()
+			n.work(webhook, stop)
+		}(n.webhooks[i])
+	}
+
+	workers.Wait()
+}
+
+func (n WebhookNotifier) work(webhook webhook, stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			n.deadLetterQueued(webhook)
+			return
+		default:
+		}
+
+		select {
+		case event := <-webhook.notifications:
+			n.notify(webhook, event, stop)
+		case <-stop:
+			n.deadLetterQueued(webhook)
+			return
+		}
+	}
+}
+
+func (n WebhookNotifier) deadLetterQueued(webhook webhook) {
+	for {
+		select {
+		case event := <-webhook.notifications:
+			n.recordDeadLetter(webhook, event, 0, errWebhookNotifierStopped)
+		default:
+			return
+		}
+	}
+}
+
+func (n WebhookNotifier) notify(webhook webhook, event store.Event, stop <-chan struct{}) {
+	backoff := n.retryBackoff
+	attempts := webhook.config.Attempts()
+
+	for attempt := 1; ; attempt++ {
+		err := n.send(webhook, event)
+		if err == nil {
+			return
+		}
+
+		n.logger.Warn(webhookLogTag, "Attempt %d of notifying '%s' of %s event for '%s' failed: %s", attempt, webhook.config.URL, event.Type, event.Name, err.Error())
+
+		if attempt >= attempts {
+			n.recordDeadLetter(webhook, event, attempt, err)
+			return
+		}
+
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-stop:
+			n.recordDeadLetter(webhook, event, attempt, err)
+			return
+		}
+	}
+}
+
+func (n WebhookNotifier) send(webhook webhook, event store.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", webhook.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookBody(webhook.config.Secret, timestamp, body))
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return errors.Errorf("Unexpected response status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+func (n WebhookNotifier) recordDeadLetter(webhook webhook, event store.Event, attempts int, cause error) {
+	n.logger.Error(webhookLogTag, "Giving up notifying '%s' of %s event for '%s': %s", webhook.config.URL, event.Type, event.Name, cause.Error())
+
+	if n.deadLetterPath == "" {
+		return
+	}
+
+	line, err := json.Marshal(deadLetter{
+		URL:      webhook.config.URL,
+		Event:    event,
+		Attempts: attempts,
+		Error:    cause.Error(),
+		FailedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	if err != nil {
+		n.logger.Error(webhookLogTag, "Failed to encode dead letter: %s", err.Error())
+		return
+	}
+
+	n.deadLetterLock.Lock()
+	defer n.deadLetterLock.Unlock()
+
+	file, err := os.OpenFile(n.deadLetterPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		n.logger.Error(webhookLogTag, "Failed to open dead-letter file: %s", err.Error())
+		return
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		n.logger.Error(webhookLogTag, "Failed to write dead letter: %s", err.Error())
+	}
+}
+
+// SignWebhookBody returns the signature header value of a notification sent
+// at timestamp: the hex encoded HMAC-SHA256 of the timestamp, a dot and the
+// body, keyed with the webhook's secret. Signing the timestamp lets receivers
+// reject replayed notifications.
+func SignWebhookBody(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
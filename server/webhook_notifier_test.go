@@ -0,0 +1,184 @@
+package server_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/cloudfoundry/config-server/config"
+	. "github.com/cloudfoundry/config-server/server"
+	"github.com/cloudfoundry/config-server/store"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type receivedNotification struct {
+	body      string
+	timestamp string
+	signature string
+}
+
+var _ = Describe("WebhookNotifier", func() {
+	var (
+		receiver       *httptest.Server
+		mutex          sync.Mutex
+		received       []receivedNotification
+		failures       int
+		tempDir        string
+		deadLetterPath string
+		stop           chan struct{}
+	)
+
+	receivedBodies := func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		var bodies []string
+		for _, notification := range received {
+			bodies = append(bodies, notification.body)
+		}
+		return bodies
+	}
+
+	startNotifier := func(webhooks ...config.WebhookConfig) WebhookNotifier {
+		notifier := NewWebhookNotifier(webhooks, time.Millisecond, deadLetterPath, boshlog.NewLogger(boshlog.LevelNone))
+		go notifier.Run(stop)
+		return notifier
+	}
+
+	BeforeEach(func() {
+		received = nil
+		failures = 0
+		stop = make(chan struct{})
+
+		receiver = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if failures > 0 {
+				failures--
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			received = append(received, receivedNotification{
+				body:      string(body),
+				timestamp: r.Header.Get(WebhookTimestampHeader),
+				signature: r.Header.Get(WebhookSignatureHeader),
+			})
+		}))
+
+		var err error
+		tempDir, err = ioutil.TempDir("", "webhooks")
+		Expect(err).ToNot(HaveOccurred())
+		deadLetterPath = filepath.Join(tempDir, "dead-letters.jsonl")
+	})
+
+	AfterEach(func() {
+		close(stop)
+		receiver.Close()
+		os.RemoveAll(tempDir)
+	})
+
+	It("posts signed notifications of events under the prefix", func() {
+		notifier := startNotifier(config.WebhookConfig{URL: receiver.URL, Prefix: "/deployments/cf/", Secret: "shh"})
+
+		notifier.Publish(store.Event{Type: store.EventPut, ID: "1", Name: "/deployments/bosh/password"})
+		notifier.Publish(store.Event{Type: store.EventPut, ID: "2", Name: "/deployments/cf/password"})
+		notifier.Publish(store.Event{Type: store.EventDelete, Name: "/deployments/cf/password"})
+
+		Eventually(receivedBodies).Should(Equal([]string{
+			`{"type":"put","id":"2","name":"/deployments/cf/password"}`,
+			`{"type":"delete","name":"/deployments/cf/password"}`,
+		}))
+
+		mutex.Lock()
+		defer mutex.Unlock()
+		Expect(received[0].signature).To(Equal(SignWebhookBody("shh", received[0].timestamp, []byte(received[0].body))))
+		Expect(received[0].signature).To(HavePrefix("sha256="))
+
+		timestamp, err := strconv.ParseInt(received[0].timestamp, 10, 64)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(time.Unix(timestamp, 0)).To(BeTemporally("~", time.Now(), time.Minute))
+	})
+
+	It("retries failed notifications", func() {
+		failures = 2
+		notifier := startNotifier(config.WebhookConfig{URL: receiver.URL, Secret: "shh", MaxAttempts: 3})
+
+		notifier.Publish(store.Event{Type: store.EventPut, ID: "2", Name: "/deployments/cf/password"})
+
+		Eventually(receivedBodies).Should(HaveLen(1))
+		_, err := os.Stat(deadLetterPath)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("records a dead letter when attempts run out", func() {
+		failures = 3
+		notifier := startNotifier(config.WebhookConfig{URL: receiver.URL, Secret: "shh", MaxAttempts: 3})
+
+		notifier.Publish(store.Event{Type: store.EventPut, ID: "2", Name: "/deployments/cf/password"})
+
+		Eventually(func() string {
+			contents, _ := ioutil.ReadFile(deadLetterPath)
+			return string(contents)
+		}).Should(HaveSuffix("\n"))
+
+		contents, _ := ioutil.ReadFile(deadLetterPath)
+		var deadLetter map[string]interface{}
+		Expect(json.Unmarshal([]byte(strings.TrimSpace(string(contents))), &deadLetter)).To(Succeed())
+		Expect(deadLetter["url"]).To(Equal(receiver.URL))
+		Expect(deadLetter["event"]).To(Equal(map[string]interface{}{"type": "put", "id": "2", "name": "/deployments/cf/password"}))
+		Expect(deadLetter["attempts"]).To(BeNumerically("==", 3))
+		Expect(deadLetter["error"]).To(Equal("Unexpected response status 503"))
+		Expect(receivedBodies()).To(BeEmpty())
+	})
+
+	It("records notifications that are pending when stopped as dead letters", func() {
+		failures = 100
+		notifierStop := make(chan struct{})
+		notifier := NewWebhookNotifier([]config.WebhookConfig{{URL: receiver.URL, Secret: "shh", MaxAttempts: 3}}, time.Hour, deadLetterPath, boshlog.NewLogger(boshlog.LevelNone))
+
+		stopped := make(chan struct{})
+		go func() {
+			notifier.Run(notifierStop)
+			close(stopped)
+		}()
+
+		notifier.Publish(store.Event{Type: store.EventPut, ID: "1", Name: "/deployments/cf/password"})
+		Eventually(func() int {
+			mutex.Lock()
+			defer mutex.Unlock()
+			return failures
+		}).Should(Equal(99))
+
+		notifier.Publish(store.Event{Type: store.EventPut, ID: "2", Name: "/deployments/cf/password"})
+		close(notifierStop)
+		Eventually(stopped).Should(BeClosed())
+
+		contents, _ := ioutil.ReadFile(deadLetterPath)
+		lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+		Expect(lines).To(HaveLen(2))
+
+		var deadLetter map[string]interface{}
+		Expect(json.Unmarshal([]byte(lines[0]), &deadLetter)).To(Succeed())
+		Expect(deadLetter["event"]).To(HaveKeyWithValue("id", "1"))
+		Expect(deadLetter["attempts"]).To(BeNumerically("==", 1))
+		Expect(deadLetter["error"]).To(Equal("Unexpected response status 503"))
+
+		Expect(json.Unmarshal([]byte(lines[1]), &deadLetter)).To(Succeed())
+		Expect(deadLetter["event"]).To(HaveKeyWithValue("id", "2"))
+		Expect(deadLetter["attempts"]).To(BeNumerically("==", 0))
+		Expect(deadLetter["error"]).To(Equal("Notifier stopped"))
+	})
+})
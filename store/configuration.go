@@ -2,22 +2,46 @@ package store
 
 import (
 	"encoding/json"
+	"time"
 )
 
 type Configuration struct {
-	ID    string
-	Name  string
-	Value string
+	ID           string
+	Name         string
+	Value        string
+	CreatedAt    time.Time
+	CreatedBy    string
+	Type         string
+	Parameters   string
+	RestoredFrom string
 }
 
 func (rv Configuration) StringifiedJSON() (string, error) {
 	var val map[string]interface{}
 
 	err := json.Unmarshal([]byte(rv.Value), &val)
+	if err != nil {
+		return "", err
+	}
 
-	val["id"] = rv.ID
-	val["name"] = rv.Name
-	bytes, err := json.Marshal(&val)
+	result := map[string]interface{}{
+		"id":    rv.ID,
+		"name":  rv.Name,
+		"value": val["value"],
+	}
+	if rv.RestoredFrom != "" {
+		result["restored_from"] = rv.RestoredFrom
+	}
+	if !rv.CreatedAt.IsZero() {
+		result["created_at"] = rv.CreatedAt.UTC().Format(time.RFC3339)
+	}
+	if rv.CreatedBy != "" {
+		result["created_by"] = rv.CreatedBy
+	}
+	if rv.Type != "" {
+		result["type"] = rv.Type
+	}
+	bytes, err := json.Marshal(&result)
 
 	return string(bytes), err
 }
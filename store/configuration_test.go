@@ -1,6 +1,8 @@
 package store_test
 
 import (
+	"time"
+
 	"github.com/cloudfoundry/config-server/store"
 
 	. "github.com/onsi/ginkgo"
@@ -52,5 +54,48 @@ var _ = Describe("Configuration", func() {
 			})
 		})
 
+		Context("When stored value contains other keys", func() {
+			It("returns only id, name and value", func() {
+				configuration := store.Configuration{
+					ID:    "123",
+					Name:  "smurf",
+					Value: `{"value": "blue", "type": "password", "parameters": {}}`,
+				}
+
+				jsonString, _ := configuration.StringifiedJSON()
+
+				Expect(jsonString).To(Equal(`{"id":"123","name":"smurf","value":"blue"}`))
+			})
+		})
+		Context("When value was restored from a previous version", func() {
+			It("returns the id it was restored from", func() {
+				configuration := store.Configuration{
+					ID:           "124",
+					Name:         "smurf",
+					Value:        `{"value": "blue"}`,
+					RestoredFrom: "123",
+				}
+
+				jsonString, _ := configuration.StringifiedJSON()
+
+				Expect(jsonString).To(Equal(`{"id":"124","name":"smurf","restored_from":"123","value":"blue"}`))
+			})
+		})
+		Context("When metadata was recorded", func() {
+			It("returns the creation time, creator and type", func() {
+				configuration := store.Configuration{
+					ID:        "123",
+					Name:      "smurf",
+					Value:     `{"value": "blue"}`,
+					CreatedAt: time.Date(2016, 11, 2, 10, 30, 0, 0, time.FixedZone("EST", -5*60*60)),
+					CreatedBy: "admin",
+					Type:      "password",
+				}
+
+				jsonString, _ := configuration.StringifiedJSON()
+
+				Expect(jsonString).To(Equal(`{"created_at":"2016-11-02T15:30:00Z","created_by":"admin","id":"123","name":"smurf","type":"password","value":"blue"}`))
+			})
+		})
 	})
 })
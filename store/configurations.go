@@ -2,6 +2,7 @@ package store
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -19,6 +20,19 @@ func (c Configurations) StringifiedJSON() (string, error) {
 	return fmt.Sprintf(`{"data":[%s]}`, strings.Join(stringifiedConfigs, ", ")), nil
 }
 
-func (c Configurations) Len() int           { return len(c) }
-func (c Configurations) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
-func (c Configurations) Less(i, j int) bool { return c[i].ID > c[j].ID }
+func (c Configurations) Len() int      { return len(c) }
+func (c Configurations) Swap(i, j int) { c[i], c[j] = c[j], c[i] }
+func (c Configurations) Less(i, j int) bool {
+	iID, iErr := strconv.Atoi(c[i].ID)
+	jID, jErr := strconv.Atoi(c[j].ID)
+	if iErr != nil || jErr != nil {
+		return c[i].ID > c[j].ID
+	}
+	return iID > jID
+}
+
+type configurationsByName Configurations
+
+func (c configurationsByName) Len() int           { return len(c) }
+func (c configurationsByName) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c configurationsByName) Less(i, j int) bool { return c[i].Name < c[j].Name }
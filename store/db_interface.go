@@ -6,7 +6,10 @@ type IDb interface {
 	Exec(query string, args ...interface{}) (sql.Result, error)
 	Query(query string, args ...interface{}) (IRows, error)
 	QueryRow(query string, args ...interface{}) IRow
+	Begin() (ITx, error)
 	SetMaxOpenConns(n int)
 	SetMaxIdleConns(n int)
-	Close()
+	Close() error
+	Ping() error
+	Stats() sql.DBStats
 }
@@ -17,6 +17,7 @@ func GetMigrations(adapter string) []migration.Migrator {
 	result := []migration.Migrator{}
 
 	for _, mig := range migrations {
+		mig := mig
 		query := func(tx migration.LimitedTx) error {
 			_, err := tx.Exec(mig)
 			return err
@@ -3,6 +3,15 @@ package db_migrations
 func PostgresMigrations() []string {
 	migrations := []string{
 		"CREATE TABLE configurations (id SERIAL NOT NULL PRIMARY KEY, name VARCHAR(255) NOT NULL, value TEXT NOT NULL)",
+		"CREATE INDEX configurations_name_idx ON configurations (name)",
+		"ALTER TABLE configurations ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE NULL",
+		"ALTER TABLE configurations ADD COLUMN created_at TIMESTAMP WITH TIME ZONE NULL",
+		"ALTER TABLE configurations ADD COLUMN created_by VARCHAR(255) NOT NULL DEFAULT ''",
+		"ALTER TABLE configurations ADD COLUMN type VARCHAR(255) NOT NULL DEFAULT ''",
+		"CREATE TABLE permissions (id SERIAL NOT NULL PRIMARY KEY, actor VARCHAR(255) NOT NULL, name VARCHAR(255) NOT NULL DEFAULT '', path VARCHAR(255) NOT NULL DEFAULT '', operations VARCHAR(255) NOT NULL)",
+		"CREATE INDEX permissions_actor_idx ON permissions (actor)",
+		"ALTER TABLE configurations ADD COLUMN parameters TEXT NOT NULL DEFAULT ''",
+		"ALTER TABLE configurations ADD COLUMN restored_from VARCHAR(255) NOT NULL DEFAULT ''",
 	}
 
 	return migrations
@@ -11,6 +20,15 @@ func PostgresMigrations() []string {
 func MysqlMigrations() []string {
 	migrations := []string{
 		"CREATE TABLE configurations (id INT NOT NULL AUTO_INCREMENT PRIMARY KEY, name VARCHAR(255) NOT NULL, value TEXT NOT NULL)",
+		"CREATE INDEX configurations_name_idx ON configurations (name)",
+		"ALTER TABLE configurations ADD COLUMN deleted_at DATETIME NULL",
+		"ALTER TABLE configurations ADD COLUMN created_at DATETIME NULL",
+		"ALTER TABLE configurations ADD COLUMN created_by VARCHAR(255) NOT NULL DEFAULT ''",
+		"ALTER TABLE configurations ADD COLUMN type VARCHAR(255) NOT NULL DEFAULT ''",
+		"CREATE TABLE permissions (id INT NOT NULL AUTO_INCREMENT PRIMARY KEY, actor VARCHAR(255) NOT NULL, name VARCHAR(255) NOT NULL DEFAULT '', path VARCHAR(255) NOT NULL DEFAULT '', operations VARCHAR(255) NOT NULL)",
+		"CREATE INDEX permissions_actor_idx ON permissions (actor)",
+		"ALTER TABLE configurations ADD COLUMN parameters TEXT NOT NULL",
+		"ALTER TABLE configurations ADD COLUMN restored_from VARCHAR(255) NOT NULL DEFAULT ''",
 	}
 
 	return migrations
@@ -53,7 +53,7 @@ func connectionString(config config.DBConfig) (string, error) {
 		connectionString = fmt.Sprintf("user=%s password=%s dbname=%s sslmode=disable",
 			config.User, config.Password, config.Name)
 	case "mysql":
-		connectionString = fmt.Sprintf("%s:%s@tcp(%s:%d)/%s",
+		connectionString = fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
 			config.User, config.Password, config.Host, config.Port, config.Name)
 	default:
 		err = errors.Errorf("Unsupported adapter: %s", config.Adapter)
@@ -61,7 +61,7 @@ var _ = Describe("DbProviderConcrete", func() {
 
 		driverName, dataSourceName, _ := fakeSQL.OpenArgsForCall(0)
 		Expect(driverName).To(Equal(dbConfig.Adapter))
-		Expect(dataSourceName).To(Equal("bosh:somethingsafe@tcp(host:0)/dbconfig"))
+		Expect(dataSourceName).To(Equal("bosh:somethingsafe@tcp(host:0)/dbconfig?parseTime=true"))
 	})
 
 	It("returns correct connection string for postgres", func() {
@@ -25,8 +25,24 @@ func (w DBWrapper) QueryRow(query string, args ...interface{}) IRow {
 	return NewRowWrapper(w.db.QueryRow(query, args...))
 }
 
-func (w DBWrapper) Close() {
-	w.db.Close()
+func (w DBWrapper) Begin() (ITx, error) {
+	tx, err := w.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return NewTxWrapper(tx), nil
+}
+
+func (w DBWrapper) Close() error {
+	return w.db.Close()
+}
+
+func (w DBWrapper) Ping() error {
+	return w.db.Ping()
+}
+
+func (w DBWrapper) Stats() sql.DBStats {
+	return w.db.Stats()
 }
 
 func (w DBWrapper) SetMaxOpenConns(n int) {
@@ -0,0 +1,37 @@
+package store
+
+const (
+	EventPut    = "put"
+	EventDelete = "delete"
+)
+
+// Event announces that a write touched a name. ID is the version that was
+// written, or the version that was removed when deleting by ID.
+type Event struct {
+	Type string `json:"type"`
+	ID   string `json:"id,omitempty"`
+	Name string `json:"name"`
+}
+
+// EventPublisher announces events to watchers
+type EventPublisher interface {
+	Publish(event Event) error
+}
+
+type eventPublishers []EventPublisher
+
+// NewEventPublishers returns a publisher announcing events to every one of
+// publishers. The first error is returned after all of them were called.
+func NewEventPublishers(publishers ...EventPublisher) EventPublisher {
+	return eventPublishers(publishers)
+}
+
+func (p eventPublishers) Publish(event Event) error {
+	var firstErr error
+	for _, publisher := range p {
+		if err := publisher.Publish(event); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/lib/pq"
+
+	"github.com/cloudfoundry/config-server/config"
+)
+
+// PostgresEventsChannel is the NOTIFY channel events are published on, so
+// that every config server sharing a database sees every write
+const PostgresEventsChannel = "configuration_events"
+
+const (
+	postgresListenerLogTag            = "PostgresEventListener"
+	postgresListenerMinReconnectDelay = 10 * time.Second
+	postgresListenerMaxReconnectDelay = time.Minute
+)
+
+// PostgresEventListener relays events published through the database to a
+// local publisher
+type PostgresEventListener struct {
+	connectionString string
+	publisher        EventPublisher
+	logger           boshlog.Logger
+}
+
+func NewPostgresEventListener(dbConfig config.DBConfig, publisher EventPublisher, logger boshlog.Logger) (PostgresEventListener, error) {
+	connectionString, err := connectionString(dbConfig)
+	if err != nil {
+		return PostgresEventListener{}, errors.WrapError(err, "Failed to generate DB connection string")
+	}
+
+	return PostgresEventListener{
+		connectionString: connectionString,
+		publisher:        publisher,
+		logger:           logger,
+	}, nil
+}
+
+// Run relays notifications until stop is closed. Notifications sent while
+// the connection is being re-established are lost.
+func (l PostgresEventListener) Run(stop <-chan struct{}) {
+	listener := pq.NewListener(l.connectionString, postgresListenerMinReconnectDelay, postgresListenerMaxReconnectDelay,
+		func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				l.logger.Error(postgresListenerLogTag, "Connection to database failed: %s", err.Error())
+			}
+		})
+	defer listener.Close()
+
+	if err := listener.Listen(PostgresEventsChannel); err != nil {
+		l.logger.Error(postgresListenerLogTag, "Failed to listen on '%s': %s", PostgresEventsChannel, err.Error())
+		return
+	}
+
+	for {
+		select {
+		case notification := <-listener.Notify:
+			if notification != nil {
+				l.relay(notification.Extra)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (l PostgresEventListener) relay(payload string) {
+	var event Event
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		l.logger.Error(postgresListenerLogTag, "Failed to parse event '%s': %s", payload, err.Error())
+		return
+	}
+
+	if err := l.publisher.Publish(event); err != nil {
+		l.logger.Error(postgresListenerLogTag, "Failed to publish %s event for '%s': %s", event.Type, event.Name, err.Error())
+	}
+}
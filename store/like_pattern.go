@@ -0,0 +1,11 @@
+package store
+
+import "strings"
+
+var likePatternReplacer = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLikePattern escapes LIKE wildcards in value so it only matches
+// literally. Both Postgres and MySQL use backslash as the default escape.
+func escapeLikePattern(value string) string {
+	return likePatternReplacer.Replace(value)
+}
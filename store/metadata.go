@@ -0,0 +1,11 @@
+package store
+
+// Metadata describes who wrote a version and what kind of value it holds.
+// Parameters holds the JSON encoded parameters of generated values and
+// RestoredFrom the id of the version a restored value was copied from.
+type Metadata struct {
+	CreatedBy    string
+	Type         string
+	Parameters   string
+	RestoredFrom string
+}
@@ -0,0 +1,26 @@
+package store
+
+import (
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// nullableTime scans a nullable timestamp column, leaving the zero time for
+// NULL. Versions written before timestamps were recorded have NULL.
+type nullableTime struct {
+	time *time.Time
+}
+
+func (t nullableTime) Scan(value interface{}) error {
+	switch typedValue := value.(type) {
+	case nil:
+		*t.time = time.Time{}
+	case time.Time:
+		*t.time = typedValue.UTC()
+	default:
+		return errors.Errorf("Cannot scan %T into a timestamp", value)
+	}
+
+	return nil
+}
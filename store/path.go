@@ -0,0 +1,24 @@
+package store
+
+import "strings"
+
+// isUnderPath returns whether name is path or is under it, matching whole
+// segments so that "/cf" and "/cf/" cover "/cf/password" but not
+// "/cf-other/password". Every name is under the empty path.
+func isUnderPath(name string, path string) bool {
+	if path == "" {
+		return true
+	}
+	path = strings.TrimSuffix(path, "/")
+	return name == path || strings.HasPrefix(name, path+"/")
+}
+
+// pathQueryArgs returns the name and the LIKE pattern that the names under
+// path are equal to or match, as isUnderPath does
+func pathQueryArgs(path string) (string, string) {
+	if path == "" {
+		return "", "%"
+	}
+	path = strings.TrimSuffix(path, "/")
+	return path, escapeLikePattern(path+"/") + "%"
+}
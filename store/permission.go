@@ -0,0 +1,66 @@
+package store
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Permission allows Actor the Operations on Name, or on every name starting
+// with Path
+type Permission struct {
+	ID         string   `json:"id"`
+	Actor      string   `json:"actor"`
+	Name       string   `json:"name,omitempty"`
+	Path       string   `json:"path,omitempty"`
+	Operations []string `json:"operations"`
+}
+
+// PermissionStore keeps permissions granted at runtime
+type PermissionStore interface {
+	GrantPermission(permission Permission) (string, error)
+	GetPermissions(actor string) ([]Permission, error)
+	RevokePermission(id string) (int, error)
+}
+
+// joinOperations and splitOperations convert operations to and from the
+// column they are stored in
+func joinOperations(operations []string) string {
+	return strings.Join(operations, ",")
+}
+
+func splitOperations(operations string) []string {
+	if operations == "" {
+		return []string{}
+	}
+	return strings.Split(operations, ",")
+}
+
+type permissionsByID []Permission
+
+func (p permissionsByID) Len() int      { return len(p) }
+func (p permissionsByID) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p permissionsByID) Less(i, j int) bool {
+	iID, _ := strconv.Atoi(p[i].ID)
+	jID, _ := strconv.Atoi(p[j].ID)
+	return iID < jID
+}
+
+func scanPermissions(rows IRows) ([]Permission, error) {
+	results := []Permission{}
+
+	for rows.Next() {
+		var permission Permission
+		var operations string
+		if err := rows.Scan(&permission.ID, &permission.Actor, &permission.Name, &permission.Path, &operations); err != nil {
+			return nil, err
+		}
+		permission.Operations = splitOperations(operations)
+		results = append(results, permission)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -4,4 +4,5 @@ type IRows interface {
 	Next() bool
 	Close() error
 	Scan(dest ...interface{}) error
+	Err() error
 }
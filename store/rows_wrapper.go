@@ -20,6 +20,10 @@ func (w RowsWrapper) Scan(dest ...interface{}) error {
 	return w.rows.Scan(dest...)
 }
 
+func (w RowsWrapper) Err() error {
+	return w.rows.Err()
+}
+
 func (w RowsWrapper) Close() error {
 	return w.rows.Close()
 }
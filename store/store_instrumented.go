@@ -0,0 +1,97 @@
+package store
+
+import (
+	"time"
+)
+
+// OperationObserver is told how long an operation of a store took and the
+// error it returned, if any
+type OperationObserver func(operation string, duration time.Duration, err error)
+
+// instrumentedStore reports every operation of the wrapped store to an
+// observer
+type instrumentedStore struct {
+	store   Store
+	observe OperationObserver
+}
+
+func NewInstrumentedStore(store Store, observe OperationObserver) Store {
+	return instrumentedStore{store: store, observe: observe}
+}
+
+func (s instrumentedStore) Put(name string, value string, metadata Metadata) (string, error) {
+	start := time.Now()
+	id, err := s.store.Put(name, value, metadata)
+	s.observe("put", time.Since(start), err)
+	return id, err
+}
+
+func (s instrumentedStore) PutIfLatest(name string, value string, expectedID string, metadata Metadata) (string, error) {
+	start := time.Now()
+	id, err := s.store.PutIfLatest(name, value, expectedID, metadata)
+	s.observe("put_if_latest", time.Since(start), err)
+	return id, err
+}
+
+func (s instrumentedStore) GetByName(name string) (Configurations, error) {
+	start := time.Now()
+	configurations, err := s.store.GetByName(name)
+	s.observe("get_by_name", time.Since(start), err)
+	return configurations, err
+}
+
+func (s instrumentedStore) GetPageByName(name string, limit int, offset int) (Configurations, error) {
+	start := time.Now()
+	configurations, err := s.store.GetPageByName(name, limit, offset)
+	s.observe("get_page_by_name", time.Since(start), err)
+	return configurations, err
+}
+
+func (s instrumentedStore) GetCurrentByName(name string) (Configuration, error) {
+	start := time.Now()
+	configuration, err := s.store.GetCurrentByName(name)
+	s.observe("get_current_by_name", time.Since(start), err)
+	return configuration, err
+}
+
+func (s instrumentedStore) GetByPath(prefix string) (Configurations, error) {
+	start := time.Now()
+	configurations, err := s.store.GetByPath(prefix)
+	s.observe("get_by_path", time.Since(start), err)
+	return configurations, err
+}
+
+func (s instrumentedStore) GetByID(id string) (Configuration, error) {
+	start := time.Now()
+	configuration, err := s.store.GetByID(id)
+	s.observe("get_by_id", time.Since(start), err)
+	return configuration, err
+}
+
+func (s instrumentedStore) Delete(name string) (int, error) {
+	start := time.Now()
+	deleted, err := s.store.Delete(name)
+	s.observe("delete", time.Since(start), err)
+	return deleted, err
+}
+
+func (s instrumentedStore) DeleteByID(id string) (int, error) {
+	start := time.Now()
+	deleted, err := s.store.DeleteByID(id)
+	s.observe("delete_by_id", time.Since(start), err)
+	return deleted, err
+}
+
+func (s instrumentedStore) Undelete(name string, retention time.Duration) (int, error) {
+	start := time.Now()
+	undeleted, err := s.store.Undelete(name, retention)
+	s.observe("undelete", time.Since(start), err)
+	return undeleted, err
+}
+
+func (s instrumentedStore) PurgeDeleted(retention time.Duration) (int, error) {
+	start := time.Now()
+	purged, err := s.store.PurgeDeleted(retention)
+	s.observe("purge_deleted", time.Since(start), err)
+	return purged, err
+}
@@ -0,0 +1,73 @@
+package store_test
+
+import (
+	. "github.com/cloudfoundry/config-server/store"
+
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry/config-server/store/storefakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InstrumentedStore", func() {
+	type observation struct {
+		operation string
+		err       error
+	}
+
+	var (
+		fakeStore    *storefakes.FakeStore
+		observations []observation
+		store        Store
+	)
+
+	BeforeEach(func() {
+		fakeStore = &storefakes.FakeStore{}
+		observations = nil
+		store = NewInstrumentedStore(fakeStore, func(operation string, duration time.Duration, err error) {
+			Expect(duration).To(BeNumerically(">=", 0))
+			observations = append(observations, observation{operation, err})
+		})
+	})
+
+	It("reports every operation with its result", func() {
+		fakeStore.PutReturns("1", nil)
+		fakeStore.GetByNameReturns(Configurations{{ID: "1", Name: "luke"}}, nil)
+		fakeStore.DeleteReturns(0, errors.New("Kaboom!"))
+
+		Expect(store.Put("luke", "skywalker", Metadata{})).To(Equal("1"))
+		Expect(store.GetByName("luke")).To(Equal(Configurations{{ID: "1", Name: "luke"}}))
+		_, err := store.Delete("luke")
+		Expect(err).To(HaveOccurred())
+
+		Expect(fakeStore.PutCallCount()).To(Equal(1))
+		Expect(observations).To(Equal([]observation{
+			{"put", nil},
+			{"get_by_name", nil},
+			{"delete", errors.New("Kaboom!")},
+		}))
+	})
+
+	It("reports the remaining operations by their name", func() {
+		store.PutIfLatest("luke", "vader", "1", Metadata{})
+		store.GetPageByName("luke", 10, 0)
+		store.GetCurrentByName("luke")
+		store.GetByPath("/")
+		store.GetByID("1")
+		store.DeleteByID("1")
+		store.Undelete("luke", time.Hour)
+		store.PurgeDeleted(time.Hour)
+
+		var operations []string
+		for _, observation := range observations {
+			operations = append(operations, observation.operation)
+		}
+		Expect(operations).To(Equal([]string{
+			"put_if_latest", "get_page_by_name", "get_current_by_name", "get_by_path",
+			"get_by_id", "delete_by_id", "undelete", "purge_deleted",
+		}))
+	})
+})
@@ -1,8 +1,42 @@
 package store
 
+import (
+	"database/sql"
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// ErrPreconditionFailed is returned by PutIfLatest when the latest
+// configuration stored under the name does not have the expected ID.
+var ErrPreconditionFailed = errors.Error("Latest ID does not match expected ID")
+
+// Store keeps every version of a name. Deleting a name only marks its versions
+// as deleted; they can be undeleted until they are purged. GetByPath returns
+// the latest version of the path itself and of every name under it, matching
+// whole segments, or of every name for an empty path.
 type Store interface {
-	Put(key string, value string) (string, error)
+	Put(key string, value string, metadata Metadata) (string, error)
+	PutIfLatest(key string, value string, expectedID string, metadata Metadata) (string, error)
 	GetByName(name string) (Configurations, error)
+	GetPageByName(name string, limit int, offset int) (Configurations, error)
+	GetCurrentByName(name string) (Configuration, error)
+	GetByPath(path string) (Configurations, error)
 	GetByID(id string) (Configuration, error)
 	Delete(key string) (int, error)
+	DeleteByID(id string) (int, error)
+	Undelete(name string, retention time.Duration) (int, error)
+	PurgeDeleted(retention time.Duration) (int, error)
+}
+
+// Pinger is implemented by stores keeping their data in a database, to check
+// that it can be reached
+type Pinger interface {
+	Ping() error
+}
+
+// DBStatsReporter is implemented by stores keeping their data in a database,
+// to report the statistics of its connection pool
+type DBStatsReporter interface {
+	DBStats() (sql.DBStats, error)
 }
@@ -3,58 +3,263 @@ package store
 import (
 	"sort"
 	"strconv"
+	"sync"
+	"time"
 )
 
 type MemoryStore struct {
-	db map[string]Configuration
+	db          map[string]Configuration
+	deletedAt   map[string]time.Time
+	permissions map[string]Permission
+	nextGrantID *int
+	mutex       *sync.Mutex
 }
 
 var dbCounter int
 
 func NewMemoryStore() MemoryStore {
 	dbCounter = 0
-	return MemoryStore{db: make(map[string]Configuration)}
+	return MemoryStore{
+		db:          make(map[string]Configuration),
+		deletedAt:   make(map[string]time.Time),
+		permissions: make(map[string]Permission),
+		nextGrantID: new(int),
+		mutex:       &sync.Mutex{},
+	}
 }
 
-func (store MemoryStore) Put(name string, value string) (string, error) {
-	config := Configuration{
-		Name:  name,
-		Value: value,
-		ID:    strconv.Itoa(dbCounter),
+func (store MemoryStore) Put(name string, value string, metadata Metadata) (string, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.put(name, value, metadata), nil
+}
+
+func (store MemoryStore) PutIfLatest(name string, value string, expectedID string, metadata Metadata) (string, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	var currentID string
+	if results := store.getByName(name); len(results) > 0 {
+		currentID = results[0].ID
 	}
-	dbCounter++
 
-	store.db[config.ID] = config
-	return config.ID, nil
+	if currentID != expectedID {
+		return "", ErrPreconditionFailed
+	}
+
+	return store.put(name, value, metadata), nil
 }
 
 func (store MemoryStore) GetByName(name string) (Configurations, error) {
-	var results Configurations
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	return store.getByName(name), nil
+}
+
+func (store MemoryStore) GetPageByName(name string, limit int, offset int) (Configurations, error) {
+	results, err := store.GetByName(name)
+	if err != nil || offset >= len(results) {
+		return nil, err
+	}
+
+	results = results[offset:]
+	if limit < len(results) {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+func (store MemoryStore) GetCurrentByName(name string) (Configuration, error) {
+	results, err := store.GetByName(name)
+	if err != nil || len(results) == 0 {
+		return Configuration{}, err
+	}
+
+	return results[0], nil
+}
+
+func (store MemoryStore) GetByPath(path string) (Configurations, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	var matches Configurations
 
 	for _, config := range store.db {
-		if config.Name == name {
+		if isUnderPath(config.Name, path) && !store.isDeleted(config.ID) {
+			matches = append(matches, config)
+		}
+	}
+
+	sort.Sort(matches)
+
+	var results Configurations
+	seenNames := map[string]bool{}
+
+	for _, config := range matches {
+		if !seenNames[config.Name] {
+			seenNames[config.Name] = true
 			results = append(results, config)
 		}
 	}
 
-	sort.Sort(results)
+	sort.Sort(configurationsByName(results))
 
 	return results, nil
 }
 
 func (store MemoryStore) GetByID(id string) (Configuration, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if store.isDeleted(id) {
+		return Configuration{}, nil
+	}
+
 	return store.db[id], nil
 }
 
 func (store MemoryStore) Delete(name string) (int, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
 	deletedCount := 0
+	now := time.Now()
 
 	for _, config := range store.db {
-		if config.Name == name {
-			delete(store.db, config.ID)
+		if config.Name == name && !store.isDeleted(config.ID) {
+			store.deletedAt[config.ID] = now
 			deletedCount++
 		}
 	}
 
 	return deletedCount, nil
 }
+
+func (store MemoryStore) DeleteByID(id string) (int, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, found := store.db[id]; !found {
+		return 0, nil
+	}
+
+	delete(store.db, id)
+	delete(store.deletedAt, id)
+	return 1, nil
+}
+
+// Undelete restores the versions of name deleted within retention. Older
+// versions are left to be purged, whether or not the purger removed them yet.
+func (store MemoryStore) Undelete(name string, retention time.Duration) (int, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	undeletedCount := 0
+	cutoff := time.Now().Add(-retention)
+
+	for _, config := range store.db {
+		if config.Name == name && store.isDeleted(config.ID) && !store.deletedAt[config.ID].Before(cutoff) {
+			delete(store.deletedAt, config.ID)
+			undeletedCount++
+		}
+	}
+
+	return undeletedCount, nil
+}
+
+func (store MemoryStore) PurgeDeleted(retention time.Duration) (int, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	purgedCount := 0
+	cutoff := time.Now().Add(-retention)
+
+	for id, deletedAt := range store.deletedAt {
+		if deletedAt.Before(cutoff) {
+			delete(store.db, id)
+			delete(store.deletedAt, id)
+			purgedCount++
+		}
+	}
+
+	return purgedCount, nil
+}
+
+func (store MemoryStore) GrantPermission(permission Permission) (string, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	*store.nextGrantID++
+	permission.ID = strconv.Itoa(*store.nextGrantID)
+	permission.Operations = append([]string{}, permission.Operations...)
+
+	store.permissions[permission.ID] = permission
+	return permission.ID, nil
+}
+
+func (store MemoryStore) GetPermissions(actor string) ([]Permission, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	results := []Permission{}
+	for _, permission := range store.permissions {
+		if actor == "" || permission.Actor == actor {
+			results = append(results, permission)
+		}
+	}
+
+	sort.Sort(permissionsByID(results))
+
+	return results, nil
+}
+
+func (store MemoryStore) RevokePermission(id string) (int, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	if _, found := store.permissions[id]; !found {
+		return 0, nil
+	}
+
+	delete(store.permissions, id)
+	return 1, nil
+}
+
+func (store MemoryStore) put(name string, value string, metadata Metadata) string {
+	config := Configuration{
+		Name:         name,
+		Value:        value,
+		ID:           strconv.Itoa(dbCounter),
+		CreatedAt:    time.Now().UTC(),
+		CreatedBy:    metadata.CreatedBy,
+		Type:         metadata.Type,
+		Parameters:   metadata.Parameters,
+		RestoredFrom: metadata.RestoredFrom,
+	}
+	dbCounter++
+
+	store.db[config.ID] = config
+	return config.ID
+}
+
+func (store MemoryStore) getByName(name string) Configurations {
+	var results Configurations
+
+	for _, config := range store.db {
+		if config.Name == name && !store.isDeleted(config.ID) {
+			results = append(results, config)
+		}
+	}
+
+	sort.Sort(results)
+
+	return results
+}
+
+func (store MemoryStore) isDeleted(id string) bool {
+	_, deleted := store.deletedAt[id]
+	return deleted
+}
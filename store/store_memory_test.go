@@ -3,10 +3,27 @@ package store_test
 import (
 	. "github.com/cloudfoundry/config-server/store"
 
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 )
 
+// withoutCreatedAt clears the creation time so configurations can be compared
+// with literals
+func withoutCreatedAt(configuration Configuration) Configuration {
+	configuration.CreatedAt = time.Time{}
+	return configuration
+}
+
+func allWithoutCreatedAt(configurations Configurations) Configurations {
+	var result Configurations
+	for _, configuration := range configurations {
+		result = append(result, withoutCreatedAt(configuration))
+	}
+	return result
+}
+
 var _ = Describe("StoreMemory", func() {
 
 	Describe("Given a properly initialized MemoryStore", func() {
@@ -18,37 +35,48 @@ var _ = Describe("StoreMemory", func() {
 
 		Context("Put", func() {
 			It("should not return error when adding a string type value", func() {
-				_, err := store.Put("key", "value")
+				_, err := store.Put("key", "value", Metadata{})
 				Expect(err).To(BeNil())
 			})
 
 			It("returns the ID of the created configuration", func() {
-				id, _ := store.Put("key", "value")
+				id, _ := store.Put("key", "value", Metadata{})
 				Expect(id).To(Equal("0"))
 			})
 
 			It("generates a unique id for new record", func() {
-				store.Put("key1", "value1")
+				store.Put("key1", "value1", Metadata{})
 				values1, _ := store.GetByName("key1")
 
 				Expect(values1).ToNot(BeNil())
 				Expect(len(values1)).To(Equal(1))
-				Expect(values1[0]).To(Equal(Configuration{ID: "0", Name: "key1", Value: "value1"}))
+				Expect(withoutCreatedAt(values1[0])).To(Equal(Configuration{ID: "0", Name: "key1", Value: "value1"}))
 
-				store.Put("key2", "value2")
+				store.Put("key2", "value2", Metadata{})
 				values2, _ := store.GetByName("key2")
 
 				Expect(values2).ToNot(BeNil())
 				Expect(len(values2)).To(Equal(1))
-				Expect(values2[0]).To(Equal(Configuration{ID: "1", Name: "key2", Value: "value2"}))
+				Expect(withoutCreatedAt(values2[0])).To(Equal(Configuration{ID: "1", Name: "key2", Value: "value2"}))
+			})
+
+			It("records the creation time and metadata of the new record", func() {
+				before := time.Now().UTC()
+				id, _ := store.Put("key", "value", Metadata{CreatedBy: "admin", Type: "password"})
+
+				configuration, _ := store.GetByID(id)
+				Expect(configuration.CreatedAt).To(BeTemporally(">=", before))
+				Expect(configuration.CreatedAt.Location()).To(Equal(time.UTC))
+				Expect(configuration.CreatedBy).To(Equal("admin"))
+				Expect(configuration.Type).To(Equal("password"))
 			})
 
 			It("generates unique ids for duplicate entries", func() {
-				id1, err := store.Put("key1", "value1")
+				id1, err := store.Put("key1", "value1", Metadata{})
 				Expect(err).To(BeNil())
 				Expect(id1).ToNot(BeNil())
 
-				id2, err := store.Put("key1", "value1")
+				id2, err := store.Put("key1", "value1", Metadata{})
 				Expect(err).To(BeNil())
 				Expect(id2).ToNot(BeNil())
 
@@ -56,42 +84,180 @@ var _ = Describe("StoreMemory", func() {
 			})
 		})
 
+		Context("PutIfLatest", func() {
+			BeforeEach(func() {
+				store.Put("some_name", "some_value", Metadata{})
+			})
+
+			It("adds a new value when latest id matches", func() {
+				id, err := store.PutIfLatest("some_name", "some_other_value", "0", Metadata{})
+				Expect(err).To(BeNil())
+				Expect(id).To(Equal("1"))
+
+				configuration, _ := store.GetCurrentByName("some_name")
+				Expect(configuration.Value).To(Equal("some_other_value"))
+			})
+
+			It("returns ErrPreconditionFailed when latest id does not match", func() {
+				store.Put("some_name", "concurrent_value", Metadata{})
+
+				_, err := store.PutIfLatest("some_name", "some_other_value", "0", Metadata{})
+				Expect(err).To(Equal(ErrPreconditionFailed))
+
+				configuration, _ := store.GetCurrentByName("some_name")
+				Expect(configuration.Value).To(Equal("concurrent_value"))
+			})
+
+			It("returns ErrPreconditionFailed when name does not exist", func() {
+				_, err := store.PutIfLatest("other_name", "some_value", "0", Metadata{})
+				Expect(err).To(Equal(ErrPreconditionFailed))
+			})
+		})
+
 		Context("GetByName", func() {
 			It("should return ALL associated values sorted by ID", func() {
-				store.Put("some_name", "some_value")
-				store.Put("some_name", "some_value")
-				store.Put("some_name", "some_other_value")
+				store.Put("some_name", "some_value", Metadata{})
+				store.Put("some_name", "some_value", Metadata{})
+				store.Put("some_name", "some_other_value", Metadata{})
 
 				returnedValues, err := store.GetByName("some_name")
 				Expect(err).To(BeNil())
 
-				Expect(returnedValues[0]).To(Equal(Configuration{
+				Expect(withoutCreatedAt(returnedValues[0])).To(Equal(Configuration{
 					ID:    "2",
 					Name:  "some_name",
 					Value: "some_other_value",
 				}))
 
-				Expect(returnedValues[1]).To(Equal(Configuration{
+				Expect(withoutCreatedAt(returnedValues[1])).To(Equal(Configuration{
 					ID:    "1",
 					Name:  "some_name",
 					Value: "some_value",
 				}))
 
-				Expect(returnedValues[2]).To(Equal(Configuration{
+				Expect(withoutCreatedAt(returnedValues[2])).To(Equal(Configuration{
 					ID:    "0",
 					Name:  "some_name",
 					Value: "some_value",
 				}))
 			})
+			It("should sort IDs numerically", func() {
+				for i := 0; i < 11; i++ {
+					store.Put("some_name", "some_value", Metadata{})
+				}
+
+				returnedValues, err := store.GetByName("some_name")
+				Expect(err).To(BeNil())
+				Expect(returnedValues[0].ID).To(Equal("10"))
+				Expect(returnedValues[1].ID).To(Equal("9"))
+				Expect(returnedValues[10].ID).To(Equal("0"))
+			})
+		})
+
+		Context("GetPageByName", func() {
+			BeforeEach(func() {
+				for i := 0; i < 5; i++ {
+					store.Put("some_name", "some_value", Metadata{})
+				}
+			})
+
+			It("should return the requested page of values sorted by ID", func() {
+				returnedValues, err := store.GetPageByName("some_name", 2, 1)
+				Expect(err).To(BeNil())
+				Expect(len(returnedValues)).To(Equal(2))
+				Expect(returnedValues[0].ID).To(Equal("3"))
+				Expect(returnedValues[1].ID).To(Equal("2"))
+			})
+
+			It("should return remaining values when limit exceeds them", func() {
+				returnedValues, err := store.GetPageByName("some_name", 10, 3)
+				Expect(err).To(BeNil())
+				Expect(len(returnedValues)).To(Equal(2))
+			})
+
+			It("should return no values when offset is past the last value", func() {
+				returnedValues, err := store.GetPageByName("some_name", 10, 5)
+				Expect(err).To(BeNil())
+				Expect(len(returnedValues)).To(Equal(0))
+			})
+		})
+
+		Context("GetCurrentByName", func() {
+			It("should return the latest value", func() {
+				store.Put("some_name", "some_value", Metadata{})
+				store.Put("some_name", "some_other_value", Metadata{})
+
+				configuration, err := store.GetCurrentByName("some_name")
+				Expect(err).To(BeNil())
+				Expect(withoutCreatedAt(configuration)).To(Equal(Configuration{
+					ID:    "1",
+					Name:  "some_name",
+					Value: "some_other_value",
+				}))
+			})
+
+			It("should return empty configuration when name does not exist", func() {
+				configuration, err := store.GetCurrentByName("some_name")
+				Expect(err).To(BeNil())
+				Expect(configuration).To(Equal(Configuration{}))
+			})
+		})
+
+		Context("GetByPath", func() {
+			It("should return the latest value of every name under the path sorted by name", func() {
+				store.Put("/deployments/cf/b", "b1", Metadata{})
+				store.Put("/deployments/cf/a", "a1", Metadata{})
+				store.Put("/deployments/cf/b", "b2", Metadata{})
+				store.Put("/deployments/diego/a", "other", Metadata{})
+
+				returnedValues, err := store.GetByPath("/deployments/cf/")
+				Expect(err).To(BeNil())
+				Expect(allWithoutCreatedAt(returnedValues)).To(Equal(Configurations{
+					{ID: "1", Name: "/deployments/cf/a", Value: "a1"},
+					{ID: "2", Name: "/deployments/cf/b", Value: "b2"},
+				}))
+			})
+
+			It("should match whole segments of names", func() {
+				store.Put("/team", "team", Metadata{})
+				store.Put("/team/secret", "secret", Metadata{})
+				store.Put("/team-b/secret", "other", Metadata{})
+
+				for _, path := range []string{"/team", "/team/"} {
+					returnedValues, err := store.GetByPath(path)
+					Expect(err).To(BeNil())
+					Expect(allWithoutCreatedAt(returnedValues)).To(Equal(Configurations{
+						{ID: "0", Name: "/team", Value: "team"},
+						{ID: "1", Name: "/team/secret", Value: "secret"},
+					}), path)
+				}
+			})
+
+			It("should return every name for an empty path", func() {
+				store.Put("some_name", "some_value", Metadata{})
+				store.Put("/team/secret", "secret", Metadata{})
+
+				returnedValues, err := store.GetByPath("")
+				Expect(err).To(BeNil())
+				Expect(len(returnedValues)).To(Equal(2))
+			})
+
+			It("should return no values when nothing matches the path", func() {
+				store.Put("/deployments/cf/a", "a1", Metadata{})
+
+				returnedValues, err := store.GetByPath("/deployments/diego/")
+				Expect(err).To(BeNil())
+				Expect(len(returnedValues)).To(Equal(0))
+			})
 		})
 
 		Context("GetById", func() {
 			It("should return associated value", func() {
-				store.Put("some_name", "some_value")
+				store.Put("some_name", "some_value", Metadata{})
 
 				configuration, err := store.GetByID("0")
 				Expect(err).To(BeNil())
-				Expect(configuration).To(Equal(Configuration{
+				Expect(withoutCreatedAt(configuration)).To(Equal(Configuration{
 					ID:    "0",
 					Name:  "some_name",
 					Value: "some_value",
@@ -99,20 +265,40 @@ var _ = Describe("StoreMemory", func() {
 			})
 		})
 
+		Context("DeleteByID", func() {
+			It("removes only the version with the given id", func() {
+				store.Put("some_name", "some_value", Metadata{})
+				store.Put("some_name", "some_other_value", Metadata{})
+
+				deleted, err := store.DeleteByID("0")
+				Expect(err).To(BeNil())
+				Expect(deleted).To(Equal(1))
+
+				values, _ := store.GetByName("some_name")
+				Expect(allWithoutCreatedAt(values)).To(Equal(Configurations{{ID: "1", Name: "some_name", Value: "some_other_value"}}))
+			})
+
+			It("returns 0 when id does not exist", func() {
+				deleted, err := store.DeleteByID("5")
+				Expect(err).To(BeNil())
+				Expect(deleted).To(Equal(0))
+			})
+		})
+
 		Context("Delete", func() {
 			Context("Name exists", func() {
 				BeforeEach(func() {
-					store.Put("some_name", "some_value")
-					store.Put("some_name", "some_value")
+					store.Put("some_name", "some_value", Metadata{})
+					store.Put("some_name", "some_value", Metadata{})
 
 					values, err := store.GetByName("some_name")
 					Expect(err).To(BeNil())
-					Expect(values[0]).To(Equal(Configuration{
+					Expect(withoutCreatedAt(values[0])).To(Equal(Configuration{
 						ID:    "1",
 						Name:  "some_name",
 						Value: "some_value",
 					}))
-					Expect(values[1]).To(Equal(Configuration{
+					Expect(withoutCreatedAt(values[1])).To(Equal(Configuration{
 						ID:    "0",
 						Name:  "some_name",
 						Value: "some_value",
@@ -131,6 +317,19 @@ var _ = Describe("StoreMemory", func() {
 					Expect(err).To(BeNil())
 					Expect(deleted).To(Equal(2))
 				})
+
+				It("hides deleted values from every read", func() {
+					store.Delete("some_name")
+
+					configuration, _ := store.GetByID("0")
+					Expect(configuration).To(Equal(Configuration{}))
+
+					configuration, _ = store.GetCurrentByName("some_name")
+					Expect(configuration).To(Equal(Configuration{}))
+
+					values, _ := store.GetByPath("some_name")
+					Expect(len(values)).To(Equal(0))
+				})
 			})
 
 			Context("Name does not exist", func() {
@@ -141,5 +340,112 @@ var _ = Describe("StoreMemory", func() {
 				})
 			})
 		})
+
+		Context("Undelete", func() {
+			BeforeEach(func() {
+				store.Put("some_name", "some_value", Metadata{})
+				store.Put("some_name", "some_other_value", Metadata{})
+				store.Delete("some_name")
+			})
+
+			It("restores every deleted value", func() {
+				undeleted, err := store.Undelete("some_name", time.Hour)
+				Expect(err).To(BeNil())
+				Expect(undeleted).To(Equal(2))
+
+				values, _ := store.GetByName("some_name")
+				Expect(len(values)).To(Equal(2))
+			})
+
+			It("returns 0 when name has no deleted values", func() {
+				undeleted, err := store.Undelete("other_name", time.Hour)
+				Expect(err).To(BeNil())
+				Expect(undeleted).To(Equal(0))
+			})
+
+			It("does not restore values deleted longer than retention ago that are not purged yet", func() {
+				time.Sleep(10 * time.Millisecond)
+
+				undeleted, err := store.Undelete("some_name", time.Millisecond)
+				Expect(err).To(BeNil())
+				Expect(undeleted).To(Equal(0))
+
+				values, _ := store.GetByName("some_name")
+				Expect(values).To(BeEmpty())
+			})
+		})
+
+		Context("PurgeDeleted", func() {
+			BeforeEach(func() {
+				store.Put("some_name", "some_value", Metadata{})
+				store.Put("other_name", "other_value", Metadata{})
+				store.Delete("some_name")
+			})
+
+			It("removes values deleted longer than retention ago", func() {
+				purged, err := store.PurgeDeleted(0)
+				Expect(err).To(BeNil())
+				Expect(purged).To(Equal(1))
+
+				undeleted, _ := store.Undelete("some_name", time.Hour)
+				Expect(undeleted).To(Equal(0))
+
+				values, _ := store.GetByName("other_name")
+				Expect(len(values)).To(Equal(1))
+			})
+
+			It("keeps values deleted within retention", func() {
+				purged, err := store.PurgeDeleted(time.Hour)
+				Expect(err).To(BeNil())
+				Expect(purged).To(Equal(0))
+
+				undeleted, _ := store.Undelete("some_name", time.Hour)
+				Expect(undeleted).To(Equal(1))
+			})
+		})
+
+		Context("Permissions", func() {
+			It("grants permissions with increasing ids", func() {
+				firstID, err := store.GrantPermission(Permission{Actor: "director", Name: "/cf/password", Operations: []string{"read"}})
+				Expect(err).To(BeNil())
+				secondID, err := store.GrantPermission(Permission{Actor: "ci", Path: "/cf/", Operations: []string{"read", "write"}})
+				Expect(err).To(BeNil())
+
+				permissions, err := store.GetPermissions("")
+				Expect(err).To(BeNil())
+				Expect(permissions).To(Equal([]Permission{
+					{ID: firstID, Actor: "director", Name: "/cf/password", Operations: []string{"read"}},
+					{ID: secondID, Actor: "ci", Path: "/cf/", Operations: []string{"read", "write"}},
+				}))
+			})
+
+			It("lists permissions of an actor", func() {
+				store.GrantPermission(Permission{Actor: "director", Name: "/cf/password", Operations: []string{"read"}})
+				id, _ := store.GrantPermission(Permission{Actor: "ci", Path: "/cf/", Operations: []string{"read"}})
+
+				permissions, err := store.GetPermissions("ci")
+				Expect(err).To(BeNil())
+				Expect(permissions).To(Equal([]Permission{{ID: id, Actor: "ci", Path: "/cf/", Operations: []string{"read"}}}))
+
+				permissions, err = store.GetPermissions("nobody")
+				Expect(err).To(BeNil())
+				Expect(permissions).To(BeEmpty())
+			})
+
+			It("revokes permissions by id", func() {
+				id, _ := store.GrantPermission(Permission{Actor: "director", Name: "/cf/password", Operations: []string{"read"}})
+
+				revoked, err := store.RevokePermission(id)
+				Expect(err).To(BeNil())
+				Expect(revoked).To(Equal(1))
+
+				revoked, err = store.RevokePermission(id)
+				Expect(err).To(BeNil())
+				Expect(revoked).To(Equal(0))
+
+				permissions, _ := store.GetPermissions("")
+				Expect(permissions).To(BeEmpty())
+			})
+		})
 	})
 })
@@ -3,6 +3,14 @@ package store
 import (
 	"database/sql"
 	"strconv"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+const (
+	mysqlErrLockDeadlock  = 1213
+	mysqlDeadlockAttempts = 3
 )
 
 type mysqlStore struct {
@@ -13,50 +21,107 @@ func NewMysqlStore(dbProvider DbProvider) Store {
 	return mysqlStore{dbProvider}
 }
 
-func (ms mysqlStore) Put(name string, value string) (string, error) {
+// Put appends a version of name. It takes the same lock as PutIfLatest so
+// that it cannot slip in between the check and the write of a conditional write.
+func (ms mysqlStore) Put(name string, value string, metadata Metadata) (string, error) {
+	return ms.insertLocked(name, value, metadata, func(currentID string) error { return nil })
+}
+
+func (ms mysqlStore) PutIfLatest(name string, value string, expectedID string, metadata Metadata) (string, error) {
+	return ms.insertLocked(name, value, metadata, func(currentID string) error {
+		if currentID != expectedID {
+			return ErrPreconditionFailed
+		}
+
+		return nil
+	})
+}
+
+// insertLocked inserts a version of name within a transaction that locks the
+// latest version of the name, provided check accepts the id of that version.
+// Concurrent first writes to a name deadlock on the gap lock taken when no
+// version exists yet; the losing transaction is retried, so that it sees the
+// version written by the other.
+func (ms mysqlStore) insertLocked(name string, value string, metadata Metadata, check func(currentID string) error) (string, error) {
+	for attempt := 1; ; attempt++ {
+		id, err := ms.insertLockedOnce(name, value, metadata, check)
+		if !isMySQLDeadlock(err) || attempt == mysqlDeadlockAttempts {
+			return id, err
+		}
+	}
+}
+
+func (ms mysqlStore) insertLockedOnce(name string, value string, metadata Metadata, check func(currentID string) error) (string, error) {
 
 	db, err := ms.dbProvider.Db()
 	if err != nil {
 		return "", err
 	}
 
-	result, err := db.Exec("INSERT INTO configurations (name, value) VALUES(?,?)", name, value)
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	// Locks the name index range so concurrent writes to the name wait for this transaction
+	var currentID string
+	err = tx.QueryRow("SELECT id FROM configurations WHERE name = ? AND deleted_at IS NULL ORDER BY id DESC LIMIT 1 FOR UPDATE", name).Scan(&currentID)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+
+	if err := check(currentID); err != nil {
+		return "", err
+	}
+
+	result, err := tx.Exec("INSERT INTO configurations (name, value, created_at, created_by, type, parameters, restored_from) VALUES(?,?,UTC_TIMESTAMP(),?,?,?,?)", name, value, metadata.CreatedBy, metadata.Type, metadata.Parameters, metadata.RestoredFrom)
+	if err != nil {
+		return "", err
+	}
 
 	id, err := result.LastInsertId()
 	if err != nil {
 		return "", err
 	}
 
-	return strconv.Itoa(int(id)), err
+	return strconv.Itoa(int(id)), tx.Commit()
+}
+
+// isMySQLDeadlock returns whether err is MySQL's ER_LOCK_DEADLOCK, after
+// which the transaction was rolled back and can be retried
+func isMySQLDeadlock(err error) bool {
+	mysqlErr, ok := err.(*mysql.MySQLError)
+	return ok && mysqlErr.Number == mysqlErrLockDeadlock
 }
 
 func (ms mysqlStore) GetByName(name string) (Configurations, error) {
-	var results Configurations
+	return ms.queryConfigurations("SELECT id, name, value, created_at, created_by, type, parameters, restored_from FROM configurations WHERE name = ? AND deleted_at IS NULL ORDER BY id DESC", name)
+}
+
+func (ms mysqlStore) GetPageByName(name string, limit int, offset int) (Configurations, error) {
+	return ms.queryConfigurations("SELECT id, name, value, created_at, created_by, type, parameters, restored_from FROM configurations WHERE name = ? AND deleted_at IS NULL ORDER BY id DESC LIMIT ? OFFSET ?", name, limit, offset)
+}
+
+func (ms mysqlStore) GetCurrentByName(name string) (Configuration, error) {
+	result := Configuration{}
 
 	db, err := ms.dbProvider.Db()
 	if err != nil {
-		return results, err
+		return result, err
 	}
 
-	rows, err := db.Query("SELECT id, name, value FROM configurations WHERE name = ? ORDER BY id DESC", name)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return results, nil
-		}
-		return results, err
+	err = db.QueryRow("SELECT id, name, value, created_at, created_by, type, parameters, restored_from FROM configurations WHERE name = ? AND deleted_at IS NULL ORDER BY id DESC LIMIT 1", name).Scan(&result.ID, &result.Name, &result.Value, nullableTime{&result.CreatedAt}, &result.CreatedBy, &result.Type, &result.Parameters, &result.RestoredFrom)
+	if err == sql.ErrNoRows {
+		return result, nil
 	}
 
-	defer rows.Close()
-
-	for rows.Next() {
-		var config Configuration
-		if err := rows.Scan(&config.ID, &config.Name, &config.Value); err != nil {
-			return results, err
-		}
-		results = append(results, config)
-	}
+	return result, err
+}
 
-	return results, err
+func (ms mysqlStore) GetByPath(path string) (Configurations, error) {
+	name, pattern := pathQueryArgs(path)
+	return ms.queryConfigurations("SELECT c.id, c.name, c.value, c.created_at, c.created_by, c.type, c.parameters, c.restored_from FROM configurations c INNER JOIN (SELECT MAX(id) AS id FROM configurations WHERE (name = ? OR name LIKE ?) AND deleted_at IS NULL GROUP BY name) latest ON c.id = latest.id ORDER BY c.name", name, pattern)
 }
 
 func (ms mysqlStore) GetByID(id string) (Configuration, error) {
@@ -67,7 +132,7 @@ func (ms mysqlStore) GetByID(id string) (Configuration, error) {
 		return result, err
 	}
 
-	err = db.QueryRow("SELECT id, name, value FROM configurations WHERE id = ?", id).Scan(&result.ID, &result.Name, &result.Value)
+	err = db.QueryRow("SELECT id, name, value, created_at, created_by, type, parameters, restored_from FROM configurations WHERE id = ? AND deleted_at IS NULL", id).Scan(&result.ID, &result.Name, &result.Value, nullableTime{&result.CreatedAt}, &result.CreatedBy, &result.Type, &result.Parameters, &result.RestoredFrom)
 	if err == sql.ErrNoRows {
 		return result, nil
 	}
@@ -83,7 +148,7 @@ func (ms mysqlStore) Delete(name string) (int, error) {
 		return deletedCount, err
 	}
 
-	result, err := db.Exec("DELETE FROM configurations WHERE name = ?", name)
+	result, err := db.Exec("UPDATE configurations SET deleted_at = NOW() WHERE name = ? AND deleted_at IS NULL", name)
 	if err != nil {
 		return 0, err
 	}
@@ -95,3 +160,146 @@ func (ms mysqlStore) Delete(name string) (int, error) {
 
 	return 0, err
 }
+
+func (ms mysqlStore) DeleteByID(id string) (int, error) {
+	_, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, nil
+	}
+
+	return ms.execCount("DELETE FROM configurations WHERE id = ?", id)
+}
+
+// Undelete restores the versions of name deleted within retention. Older
+// versions are left to be purged, whether or not the purger removed them yet.
+func (ms mysqlStore) Undelete(name string, retention time.Duration) (int, error) {
+	return ms.execCount("UPDATE configurations SET deleted_at = NULL WHERE name = ? AND deleted_at >= NOW() - INTERVAL ? SECOND", name, int(retention.Seconds()))
+}
+
+func (ms mysqlStore) PurgeDeleted(retention time.Duration) (int, error) {
+	return ms.execCount("DELETE FROM configurations WHERE deleted_at < NOW() - INTERVAL ? SECOND", int(retention.Seconds()))
+}
+
+func (ms mysqlStore) GrantPermission(permission Permission) (string, error) {
+	db, err := ms.dbProvider.Db()
+	if err != nil {
+		return "", err
+	}
+
+	result, err := db.Exec("INSERT INTO permissions (actor, name, path, operations) VALUES(?,?,?,?)", permission.Actor, permission.Name, permission.Path, joinOperations(permission.Operations))
+	if err != nil {
+		return "", err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(int(id)), nil
+}
+
+func (ms mysqlStore) GetPermissions(actor string) ([]Permission, error) {
+	if actor == "" {
+		return ms.queryPermissions("SELECT id, actor, name, path, operations FROM permissions ORDER BY id")
+	}
+	return ms.queryPermissions("SELECT id, actor, name, path, operations FROM permissions WHERE actor = ? ORDER BY id", actor)
+}
+
+func (ms mysqlStore) RevokePermission(id string) (int, error) {
+	_, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, nil
+	}
+
+	return ms.execCount("DELETE FROM permissions WHERE id = ?", id)
+}
+
+func (ms mysqlStore) queryPermissions(query string, args ...interface{}) ([]Permission, error) {
+	db, err := ms.dbProvider.Db()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPermissions(rows)
+}
+
+func (ms mysqlStore) queryConfigurations(query string, args ...interface{}) (Configurations, error) {
+	var results Configurations
+
+	db, err := ms.dbProvider.Db()
+	if err != nil {
+		return results, err
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return results, nil
+		}
+		return results, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var config Configuration
+		if err := rows.Scan(&config.ID, &config.Name, &config.Value, nullableTime{&config.CreatedAt}, &config.CreatedBy, &config.Type, &config.Parameters, &config.RestoredFrom); err != nil {
+			return results, err
+		}
+		results = append(results, config)
+	}
+
+	return results, rows.Err()
+}
+
+func (ms mysqlStore) execCount(query string, args ...interface{}) (int, error) {
+	db, err := ms.dbProvider.Db()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
+
+// Close closes the connections to the database
+func (ms mysqlStore) Close() error {
+	db, err := ms.dbProvider.Db()
+	if err != nil {
+		return err
+	}
+
+	return db.Close()
+}
+
+// Ping checks that the database can be reached
+func (ms mysqlStore) Ping() error {
+	db, err := ms.dbProvider.Db()
+	if err != nil {
+		return err
+	}
+
+	return db.Ping()
+}
+
+// DBStats returns the statistics of the connection pool of the database
+func (ms mysqlStore) DBStats() (sql.DBStats, error) {
+	db, err := ms.dbProvider.Db()
+	if err != nil {
+		return sql.DBStats{}, err
+	}
+
+	return db.Stats(), nil
+}
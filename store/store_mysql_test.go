@@ -6,6 +6,9 @@ import (
 	"database/sql"
 	"errors"
 	fakes "github.com/cloudfoundry/config-server/store/storefakes"
+	"github.com/go-sql-driver/mysql"
+	"io"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -42,7 +45,7 @@ var _ = Describe("StoreMysql", func() {
 			Expect(err).To(BeNil())
 			query, _ := fakeDb.QueryArgsForCall(0)
 
-			Expect(query).To(Equal("SELECT id, name, value FROM configurations WHERE name = ? ORDER BY id DESC"))
+			Expect(query).To(Equal("SELECT id, name, value, created_at, created_by, type, parameters, restored_from FROM configurations WHERE name = ? AND deleted_at IS NULL ORDER BY id DESC"))
 		})
 
 		It("returns ALL values from db query", func() {
@@ -121,6 +124,130 @@ var _ = Describe("StoreMysql", func() {
 			Expect(err).ToNot(BeNil())
 			Expect(err).To(Equal(queryError))
 		})
+
+		It("returns an error when reading rows fails partway", func() {
+			fakeRows.NextStub = func() bool {
+				return fakeRows.NextCallCount() == 1
+			}
+			fakeRows.ErrReturns(errors.New("connection reset"))
+
+			fakeDb.QueryReturns(fakeRows, nil)
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			_, err := store.GetByName("luke")
+			Expect(err).To(MatchError("connection reset"))
+		})
+	})
+
+	Describe("GetPageByName", func() {
+		It("queries the database for a page of entries for a given name", func() {
+			fakeDb.QueryReturns(fakeRows, nil)
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			_, err := store.GetPageByName("Luke", 10, 20)
+			Expect(err).To(BeNil())
+			query, args := fakeDb.QueryArgsForCall(0)
+
+			Expect(query).To(Equal("SELECT id, name, value, created_at, created_by, type, parameters, restored_from FROM configurations WHERE name = ? AND deleted_at IS NULL ORDER BY id DESC LIMIT ? OFFSET ?"))
+			Expect(args).To(Equal([]interface{}{"Luke", 10, 20}))
+		})
+
+		It("returns values from db query", func() {
+			fakeRows.NextStub = func() bool {
+				return fakeRows.NextCallCount() == 1
+			}
+
+			fakeRows.ScanStub = func(dest ...interface{}) error {
+				*dest[0].(*string) = "10"
+				*dest[1].(*string) = "someName"
+				*dest[2].(*string) = "someValue"
+				return nil
+			}
+
+			fakeDb.QueryReturns(fakeRows, nil)
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			values, err := store.GetPageByName("someName", 1, 0)
+			Expect(err).To(BeNil())
+			Expect(values).To(Equal(Configurations{{ID: "10", Name: "someName", Value: "someValue"}}))
+		})
+
+		It("returns an error when db query fails", func() {
+			fakeDb.QueryReturns(nil, errors.New("query failure"))
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			_, err := store.GetPageByName("luke", 1, 0)
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Describe("GetCurrentByName", func() {
+		It("queries the database for the latest entry for a given name", func() {
+			fakeDb.QueryRowReturns(fakeRow)
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			_, err := store.GetCurrentByName("Luke")
+			Expect(err).To(BeNil())
+			query, args := fakeDb.QueryRowArgsForCall(0)
+
+			Expect(query).To(Equal("SELECT id, name, value, created_at, created_by, type, parameters, restored_from FROM configurations WHERE name = ? AND deleted_at IS NULL ORDER BY id DESC LIMIT 1"))
+			Expect(args).To(Equal([]interface{}{"Luke"}))
+		})
+
+		It("returns value from db query", func() {
+			fakeRow.ScanStub = func(dest ...interface{}) error {
+				*dest[0].(*string) = "10"
+				*dest[1].(*string) = "someName"
+				*dest[2].(*string) = "someValue"
+				return nil
+			}
+
+			fakeDb.QueryRowReturns(fakeRow)
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			value, err := store.GetCurrentByName("someName")
+			Expect(err).To(BeNil())
+			Expect(value).To(Equal(Configuration{ID: "10", Name: "someName", Value: "someValue"}))
+		})
+
+		It("returns empty configuration when no result is found", func() {
+			fakeRow.ScanReturns(sql.ErrNoRows)
+			fakeDb.QueryRowReturns(fakeRow)
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			value, err := store.GetCurrentByName("luke")
+			Expect(err).To(BeNil())
+			Expect(value).To(Equal(Configuration{}))
+		})
+
+		It("returns an error when db provider fails to return db", func() {
+			fakeDbProvider.DbReturns(nil, errors.New("connection failure"))
+
+			_, err := store.GetCurrentByName("luke")
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Describe("GetByPath", func() {
+		It("queries the database for the latest entry of the path and every name under it", func() {
+			fakeDb.QueryReturns(fakeRows, nil)
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			_, err := store.GetByPath("/deployments/cf_1/")
+			Expect(err).To(BeNil())
+			query, args := fakeDb.QueryArgsForCall(0)
+
+			Expect(query).To(Equal("SELECT c.id, c.name, c.value, c.created_at, c.created_by, c.type, c.parameters, c.restored_from FROM configurations c INNER JOIN (SELECT MAX(id) AS id FROM configurations WHERE (name = ? OR name LIKE ?) AND deleted_at IS NULL GROUP BY name) latest ON c.id = latest.id ORDER BY c.name"))
+			Expect(args).To(Equal([]interface{}{"/deployments/cf_1", `/deployments/cf\_1/%`}))
+		})
+
+		It("returns an error when db query fails", func() {
+			fakeDb.QueryReturns(nil, errors.New("query failure"))
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			_, err := store.GetByPath("/deployments/")
+			Expect(err).ToNot(BeNil())
+		})
 	})
 
 	Describe("GetById", func() {
@@ -132,7 +259,7 @@ var _ = Describe("StoreMysql", func() {
 			Expect(err).To(BeNil())
 			query, _ := fakeDb.QueryRowArgsForCall(0)
 
-			Expect(query).To(Equal("SELECT id, name, value FROM configurations WHERE id = ?"))
+			Expect(query).To(Equal("SELECT id, name, value, created_at, created_by, type, parameters, restored_from FROM configurations WHERE id = ? AND deleted_at IS NULL"))
 		})
 
 		It("returns value from db query", func() {
@@ -199,33 +326,149 @@ var _ = Describe("StoreMysql", func() {
 	})
 
 	Describe("Put", func() {
-		It("does an insert to the database", func() {
+		var fakeTx *fakes.FakeITx
+
+		BeforeEach(func() {
+			fakeTx = &fakes.FakeITx{}
+
 			fakeDbProvider.DbReturns(fakeDb, nil)
-			fakeDb.ExecReturns(fakeResult, nil)
+			fakeDb.BeginReturns(fakeTx, nil)
 
-			_, err := store.Put("Luke", "Skywalker")
+			fakeRow.ScanStub = func(dest ...interface{}) error {
+				*dest[0].(*string) = "5"
+				return nil
+			}
+			fakeTx.QueryRowReturns(fakeRow)
+
+			fakeResult.LastInsertIdReturns(9, nil)
+			fakeTx.ExecReturns(fakeResult, nil)
+		})
+
+		It("locks the latest row and inserts within a transaction", func() {
+			_, err := store.Put("Luke", "Skywalker", Metadata{CreatedBy: "admin", Type: "password", Parameters: `{"length":20}`, RestoredFrom: "3"})
 			Expect(err).To(BeNil())
 
-			Expect(fakeDb.ExecCallCount()).To(Equal(1))
+			query, args := fakeTx.QueryRowArgsForCall(0)
+			Expect(query).To(Equal("SELECT id FROM configurations WHERE name = ? AND deleted_at IS NULL ORDER BY id DESC LIMIT 1 FOR UPDATE"))
+			Expect(args).To(Equal([]interface{}{"Luke"}))
 
-			query, values := fakeDb.ExecArgsForCall(0)
-			Expect(query).To(Equal("INSERT INTO configurations (name, value) VALUES(?,?)"))
+			Expect(fakeTx.ExecCallCount()).To(Equal(1))
+
+			query, values := fakeTx.ExecArgsForCall(0)
+			Expect(query).To(Equal("INSERT INTO configurations (name, value, created_at, created_by, type, parameters, restored_from) VALUES(?,?,UTC_TIMESTAMP(),?,?,?,?)"))
 
 			Expect(values[0]).To(Equal("Luke"))
 			Expect(values[1]).To(Equal("Skywalker"))
+			Expect(values[2]).To(Equal("admin"))
+			Expect(values[3]).To(Equal("password"))
+			Expect(values[4]).To(Equal(`{"length":20}`))
+			Expect(values[5]).To(Equal("3"))
+
+			Expect(fakeTx.CommitCallCount()).To(Equal(1))
 		})
 
 		It("returns id of new record", func() {
-			fakeDbProvider.DbReturns(fakeDb, nil)
-			fakeDb.ExecReturns(fakeResult, nil)
-			fakeResult.LastInsertIdReturns(9, nil)
+			id, err := store.Put("Luke", "Skywalker", Metadata{})
+			Expect(err).To(BeNil())
+			Expect(id).To(Equal("9"))
+		})
 
-			id, err := store.Put("Luke", "Skywalker")
+		It("inserts when name does not exist", func() {
+			fakeRow.ScanStub = nil
+			fakeRow.ScanReturns(sql.ErrNoRows)
+
+			id, err := store.Put("Luke", "Skywalker", Metadata{})
 			Expect(err).To(BeNil())
 			Expect(id).To(Equal("9"))
 		})
 	})
 
+	Describe("PutIfLatest", func() {
+		var fakeTx *fakes.FakeITx
+
+		BeforeEach(func() {
+			fakeTx = &fakes.FakeITx{}
+
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			fakeDb.BeginReturns(fakeTx, nil)
+
+			fakeRow.ScanStub = func(dest ...interface{}) error {
+				*dest[0].(*string) = "5"
+				return nil
+			}
+			fakeTx.QueryRowReturns(fakeRow)
+
+			fakeResult.LastInsertIdReturns(6, nil)
+			fakeTx.ExecReturns(fakeResult, nil)
+		})
+
+		It("locks the latest row, checks its id and inserts within a transaction", func() {
+			id, err := store.PutIfLatest("Luke", "Skywalker", "5", Metadata{})
+			Expect(err).To(BeNil())
+			Expect(id).To(Equal("6"))
+
+			query, args := fakeTx.QueryRowArgsForCall(0)
+			Expect(query).To(Equal("SELECT id FROM configurations WHERE name = ? AND deleted_at IS NULL ORDER BY id DESC LIMIT 1 FOR UPDATE"))
+			Expect(args).To(Equal([]interface{}{"Luke"}))
+
+			query, args = fakeTx.ExecArgsForCall(0)
+			Expect(query).To(Equal("INSERT INTO configurations (name, value, created_at, created_by, type, parameters, restored_from) VALUES(?,?,UTC_TIMESTAMP(),?,?,?,?)"))
+			Expect(args).To(Equal([]interface{}{"Luke", "Skywalker", "", "", "", ""}))
+
+			Expect(fakeTx.CommitCallCount()).To(Equal(1))
+		})
+
+		It("returns ErrPreconditionFailed without inserting when latest id does not match", func() {
+			_, err := store.PutIfLatest("Luke", "Skywalker", "4", Metadata{})
+			Expect(err).To(Equal(ErrPreconditionFailed))
+
+			Expect(fakeTx.ExecCallCount()).To(Equal(0))
+			Expect(fakeTx.CommitCallCount()).To(Equal(0))
+			Expect(fakeTx.RollbackCallCount()).To(Equal(1))
+		})
+
+		It("returns ErrPreconditionFailed when name does not exist", func() {
+			fakeRow.ScanStub = nil
+			fakeRow.ScanReturns(sql.ErrNoRows)
+
+			_, err := store.PutIfLatest("Luke", "Skywalker", "4", Metadata{})
+			Expect(err).To(Equal(ErrPreconditionFailed))
+		})
+
+		It("retries when concurrent first writes to a name deadlock", func() {
+			fakeRow.ScanStub = func(dest ...interface{}) error {
+				if fakeTx.QueryRowCallCount() == 1 {
+					return sql.ErrNoRows
+				}
+				*dest[0].(*string) = "5"
+				return nil
+			}
+			fakeTx.ExecStub = func(query string, args ...interface{}) (sql.Result, error) {
+				return nil, &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+			}
+
+			_, err := store.PutIfLatest("Luke", "Skywalker", "", Metadata{})
+			Expect(err).To(Equal(ErrPreconditionFailed))
+			Expect(fakeTx.ExecCallCount()).To(Equal(1))
+			Expect(fakeTx.RollbackCallCount()).To(Equal(2))
+		})
+
+		It("returns the deadlock after retrying", func() {
+			fakeTx.ExecReturns(nil, &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"})
+
+			_, err := store.PutIfLatest("Luke", "Skywalker", "5", Metadata{})
+			Expect(err).To(HaveOccurred())
+			Expect(fakeTx.ExecCallCount()).To(Equal(3))
+		})
+
+		It("returns an error when transaction cannot be started", func() {
+			fakeDb.BeginReturns(nil, errors.New("connection failure"))
+
+			_, err := store.PutIfLatest("Luke", "Skywalker", "5", Metadata{})
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
 	Describe("Delete", func() {
 		Context("Name exists", func() {
 
@@ -241,7 +484,7 @@ var _ = Describe("StoreMysql", func() {
 
 				Expect(fakeDb.ExecCallCount()).To(Equal(1))
 				query, value := fakeDb.ExecArgsForCall(0)
-				Expect(query).To(Equal("DELETE FROM configurations WHERE name = ?"))
+				Expect(query).To(Equal("UPDATE configurations SET deleted_at = NOW() WHERE name = ? AND deleted_at IS NULL"))
 				Expect(value[0]).To(Equal("Luke"))
 			})
 
@@ -269,4 +512,179 @@ var _ = Describe("StoreMysql", func() {
 			})
 		})
 	})
+
+	Describe("DeleteByID", func() {
+		BeforeEach(func() {
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			fakeDb.ExecReturns(fakeResult, nil)
+		})
+
+		It("removes the single version with the given id", func() {
+			fakeResult.RowsAffectedReturns(1, nil)
+
+			deleted, err := store.DeleteByID("5")
+			Expect(err).To(BeNil())
+			Expect(deleted).To(Equal(1))
+
+			query, args := fakeDb.ExecArgsForCall(0)
+			Expect(query).To(Equal("DELETE FROM configurations WHERE id = ?"))
+			Expect(args).To(Equal([]interface{}{"5"}))
+		})
+
+		It("returns 0 without querying when id cannot be converted to a int", func() {
+			deleted, err := store.DeleteByID("abc")
+			Expect(err).To(BeNil())
+			Expect(deleted).To(Equal(0))
+			Expect(fakeDb.ExecCallCount()).To(Equal(0))
+		})
+
+		It("returns an error when db query fails", func() {
+			fakeDb.ExecReturns(nil, errors.New("query failure"))
+
+			_, err := store.DeleteByID("5")
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Describe("Undelete", func() {
+		It("clears the deletion mark of the versions of a name deleted within retention", func() {
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			fakeDb.ExecReturns(fakeResult, nil)
+			fakeResult.RowsAffectedReturns(2, nil)
+
+			undeleted, err := store.Undelete("Luke", time.Hour)
+			Expect(err).To(BeNil())
+			Expect(undeleted).To(Equal(2))
+
+			query, args := fakeDb.ExecArgsForCall(0)
+			Expect(query).To(Equal("UPDATE configurations SET deleted_at = NULL WHERE name = ? AND deleted_at >= NOW() - INTERVAL ? SECOND"))
+			Expect(args).To(Equal([]interface{}{"Luke", 3600}))
+		})
+	})
+
+	Describe("PurgeDeleted", func() {
+		It("removes versions deleted longer than retention ago", func() {
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			fakeDb.ExecReturns(fakeResult, nil)
+			fakeResult.RowsAffectedReturns(3, nil)
+
+			purged, err := store.PurgeDeleted(2 * time.Hour)
+			Expect(err).To(BeNil())
+			Expect(purged).To(Equal(3))
+
+			query, args := fakeDb.ExecArgsForCall(0)
+			Expect(query).To(Equal("DELETE FROM configurations WHERE deleted_at < NOW() - INTERVAL ? SECOND"))
+			Expect(args).To(Equal([]interface{}{7200}))
+		})
+
+		It("returns an error when db provider fails to return db", func() {
+			fakeDbProvider.DbReturns(nil, errors.New("connection failure"))
+
+			_, err := store.PurgeDeleted(time.Hour)
+			Expect(err).ToNot(BeNil())
+		})
+	})
+
+	Describe("GrantPermission", func() {
+		It("inserts the permission and returns its id", func() {
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			fakeDb.ExecReturns(fakeResult, nil)
+			fakeResult.LastInsertIdReturns(4, nil)
+
+			id, err := store.(PermissionStore).GrantPermission(Permission{Actor: "director", Name: "/cf/password", Operations: []string{"read"}})
+			Expect(err).To(BeNil())
+			Expect(id).To(Equal("4"))
+
+			query, values := fakeDb.ExecArgsForCall(0)
+			Expect(query).To(Equal("INSERT INTO permissions (actor, name, path, operations) VALUES(?,?,?,?)"))
+			Expect(values).To(Equal([]interface{}{"director", "/cf/password", "", "read"}))
+		})
+	})
+
+	Describe("GetPermissions", func() {
+		It("queries permissions of an actor", func() {
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			fakeDb.QueryReturns(fakeRows, nil)
+
+			_, err := store.(PermissionStore).GetPermissions("director")
+			Expect(err).To(BeNil())
+
+			query, values := fakeDb.QueryArgsForCall(0)
+			Expect(query).To(Equal("SELECT id, actor, name, path, operations FROM permissions WHERE actor = ? ORDER BY id"))
+			Expect(values).To(Equal([]interface{}{"director"}))
+		})
+	})
+
+	Describe("RevokePermission", func() {
+		It("deletes the permission", func() {
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			fakeDb.ExecReturns(fakeResult, nil)
+			fakeResult.RowsAffectedReturns(1, nil)
+
+			revoked, err := store.(PermissionStore).RevokePermission("4")
+			Expect(err).To(BeNil())
+			Expect(revoked).To(Equal(1))
+
+			query, _ := fakeDb.ExecArgsForCall(0)
+			Expect(query).To(Equal("DELETE FROM permissions WHERE id = ?"))
+		})
+	})
+
+	Describe("Close", func() {
+		It("closes the database", func() {
+			fakeDbProvider.DbReturns(fakeDb, nil)
+
+			Expect(store.(io.Closer).Close()).To(Succeed())
+			Expect(fakeDb.CloseCallCount()).To(Equal(1))
+		})
+
+		It("returns an error when database is not available", func() {
+			fakeDbProvider.DbReturns(nil, errors.New("Database not initialized"))
+
+			Expect(store.(io.Closer).Close()).ToNot(Succeed())
+		})
+
+		It("returns an error when closing the database fails", func() {
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			fakeDb.CloseReturns(errors.New("Kaboom!"))
+
+			Expect(store.(io.Closer).Close()).To(MatchError("Kaboom!"))
+		})
+	})
+
+	Describe("Ping", func() {
+		It("pings the database", func() {
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			fakeDb.PingReturns(errors.New("connection refused"))
+
+			err := store.(Pinger).Ping()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("connection refused"))
+			Expect(fakeDb.PingCallCount()).To(Equal(1))
+		})
+
+		It("returns an error when database is not available", func() {
+			fakeDbProvider.DbReturns(nil, errors.New("Database not initialized"))
+
+			Expect(store.(Pinger).Ping()).ToNot(Succeed())
+		})
+	})
+
+	Describe("DBStats", func() {
+		It("returns the statistics of the database", func() {
+			fakeDbProvider.DbReturns(fakeDb, nil)
+			fakeDb.StatsReturns(sql.DBStats{OpenConnections: 3, InUse: 1, Idle: 2})
+
+			stats, err := store.(DBStatsReporter).DBStats()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats).To(Equal(sql.DBStats{OpenConnections: 3, InUse: 1, Idle: 2}))
+		})
+
+		It("returns an error when database is not available", func() {
+			fakeDbProvider.DbReturns(nil, errors.New("Database not initialized"))
+
+			_, err := store.(DBStatsReporter).DBStats()
+			Expect(err).To(HaveOccurred())
+		})
+	})
 })
@@ -2,7 +2,9 @@ package store
 
 import (
 	"database/sql"
+	"encoding/json"
 	"strconv"
+	"time"
 )
 
 type postgresStore struct {
@@ -13,50 +15,89 @@ func NewPostgresStore(dbProvider DbProvider) Store {
 	return postgresStore{dbProvider}
 }
 
-func (ps postgresStore) Put(name string, value string) (string, error) {
+// Put appends a version of name. It takes the same lock as PutIfLatest so
+// that it cannot slip in between the check and the write of a conditional write.
+func (ps postgresStore) Put(name string, value string, metadata Metadata) (string, error) {
+	return ps.insertLocked(name, value, metadata, func(currentID string) error { return nil })
+}
+
+func (ps postgresStore) PutIfLatest(name string, value string, expectedID string, metadata Metadata) (string, error) {
+	return ps.insertLocked(name, value, metadata, func(currentID string) error {
+		if currentID != expectedID {
+			return ErrPreconditionFailed
+		}
+
+		return nil
+	})
+}
+
+// insertLocked inserts a version of name within a transaction that holds the
+// lock of the name, provided check accepts the id of the latest version.
+func (ps postgresStore) insertLocked(name string, value string, metadata Metadata, check func(currentID string) error) (string, error) {
 
 	db, err := ps.dbProvider.Db()
 	if err != nil {
 		return "", err
 	}
 
-	var id int
-	err = db.QueryRow("INSERT INTO configurations (name, value) VALUES($1, $2) RETURNING id", name, value).Scan(&id)
+	tx, err := db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	// Serializes writes to the same name until the transaction ends
+	_, err = tx.Exec("SELECT pg_advisory_xact_lock(hashtext($1))", name)
+	if err != nil {
+		return "", err
+	}
+
+	var currentID string
+	err = tx.QueryRow("SELECT id FROM configurations WHERE name = $1 AND deleted_at IS NULL ORDER BY id DESC LIMIT 1", name).Scan(&currentID)
+	if err != nil && err != sql.ErrNoRows {
+		return "", err
+	}
+
+	if err := check(currentID); err != nil {
+		return "", err
+	}
 
+	var id int
+	err = tx.QueryRow("INSERT INTO configurations (name, value, created_at, created_by, type, parameters, restored_from) VALUES($1, $2, CURRENT_TIMESTAMP, $3, $4, $5, $6) RETURNING id", name, value, metadata.CreatedBy, metadata.Type, metadata.Parameters, metadata.RestoredFrom).Scan(&id)
 	if err != nil {
 		return "", err
 	}
 
-	return strconv.Itoa(int(id)), err
+	return strconv.Itoa(id), tx.Commit()
 }
 
 func (ps postgresStore) GetByName(name string) (Configurations, error) {
-	var results Configurations
+	return ps.queryConfigurations("SELECT id, name, value, created_at, created_by, type, parameters, restored_from FROM configurations WHERE name = $1 AND deleted_at IS NULL ORDER BY id DESC", name)
+}
+
+func (ps postgresStore) GetPageByName(name string, limit int, offset int) (Configurations, error) {
+	return ps.queryConfigurations("SELECT id, name, value, created_at, created_by, type, parameters, restored_from FROM configurations WHERE name = $1 AND deleted_at IS NULL ORDER BY id DESC LIMIT $2 OFFSET $3", name, limit, offset)
+}
+
+func (ps postgresStore) GetCurrentByName(name string) (Configuration, error) {
+	result := Configuration{}
 
 	db, err := ps.dbProvider.Db()
 	if err != nil {
-		return results, err
+		return result, err
 	}
 
-	rows, err := db.Query("SELECT id, name, value FROM configurations WHERE name = $1 ORDER BY id DESC", name)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return results, nil
-		}
-		return results, err
+	err = db.QueryRow("SELECT id, name, value, created_at, created_by, type, parameters, restored_from FROM configurations WHERE name = $1 AND deleted_at IS NULL ORDER BY id DESC LIMIT 1", name).Scan(&result.ID, &result.Name, &result.Value, nullableTime{&result.CreatedAt}, &result.CreatedBy, &result.Type, &result.Parameters, &result.RestoredFrom)
+	if err == sql.ErrNoRows {
+		return result, nil
 	}
 
-	defer rows.Close()
-
-	for rows.Next() {
-		var config Configuration
-		if err := rows.Scan(&config.ID, &config.Name, &config.Value); err != nil {
-			return results, err
-		}
-		results = append(results, config)
-	}
+	return result, err
+}
 
-	return results, err
+func (ps postgresStore) GetByPath(path string) (Configurations, error) {
+	name, pattern := pathQueryArgs(path)
+	return ps.queryConfigurations("SELECT c.id, c.name, c.value, c.created_at, c.created_by, c.type, c.parameters, c.restored_from FROM configurations c INNER JOIN (SELECT MAX(id) AS id FROM configurations WHERE (name = $1 OR name LIKE $2) AND deleted_at IS NULL GROUP BY name) latest ON c.id = latest.id ORDER BY c.name", name, pattern)
 }
 
 func (ps postgresStore) GetByID(id string) (Configuration, error) {
@@ -72,7 +113,7 @@ func (ps postgresStore) GetByID(id string) (Configuration, error) {
 		return result, err
 	}
 
-	err = db.QueryRow("SELECT id, name, value FROM configurations WHERE id = $1", id).Scan(&result.ID, &result.Name, &result.Value)
+	err = db.QueryRow("SELECT id, name, value, created_at, created_by, type, parameters, restored_from FROM configurations WHERE id = $1 AND deleted_at IS NULL", id).Scan(&result.ID, &result.Name, &result.Value, nullableTime{&result.CreatedAt}, &result.CreatedBy, &result.Type, &result.Parameters, &result.RestoredFrom)
 	if err == sql.ErrNoRows {
 		return result, nil
 	}
@@ -87,7 +128,7 @@ func (ps postgresStore) Delete(name string) (int, error) {
 		return 0, err
 	}
 
-	result, err := db.Exec("DELETE FROM configurations WHERE name = $1", name)
+	result, err := db.Exec("UPDATE configurations SET deleted_at = CURRENT_TIMESTAMP WHERE name = $1 AND deleted_at IS NULL", name)
 	if err != nil {
 		return 0, err
 	}
@@ -99,3 +140,158 @@ func (ps postgresStore) Delete(name string) (int, error) {
 
 	return 0, err
 }
+
+func (ps postgresStore) DeleteByID(id string) (int, error) {
+	_, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, nil
+	}
+
+	return ps.execCount("DELETE FROM configurations WHERE id = $1", id)
+}
+
+// Undelete restores the versions of name deleted within retention. Older
+// versions are left to be purged, whether or not the purger removed them yet.
+func (ps postgresStore) Undelete(name string, retention time.Duration) (int, error) {
+	return ps.execCount("UPDATE configurations SET deleted_at = NULL WHERE name = $1 AND deleted_at >= CURRENT_TIMESTAMP - $2 * INTERVAL '1 second'", name, int(retention.Seconds()))
+}
+
+func (ps postgresStore) PurgeDeleted(retention time.Duration) (int, error) {
+	return ps.execCount("DELETE FROM configurations WHERE deleted_at < CURRENT_TIMESTAMP - $1 * INTERVAL '1 second'", int(retention.Seconds()))
+}
+
+// Publish announces event to every config server listening on the database
+func (ps postgresStore) Publish(event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	db, err := ps.dbProvider.Db()
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec("SELECT pg_notify($1, $2)", PostgresEventsChannel, string(payload))
+	return err
+}
+
+func (ps postgresStore) GrantPermission(permission Permission) (string, error) {
+	db, err := ps.dbProvider.Db()
+	if err != nil {
+		return "", err
+	}
+
+	var id int
+	err = db.QueryRow("INSERT INTO permissions (actor, name, path, operations) VALUES($1, $2, $3, $4) RETURNING id", permission.Actor, permission.Name, permission.Path, joinOperations(permission.Operations)).Scan(&id)
+	if err != nil {
+		return "", err
+	}
+
+	return strconv.Itoa(id), nil
+}
+
+func (ps postgresStore) GetPermissions(actor string) ([]Permission, error) {
+	if actor == "" {
+		return ps.queryPermissions("SELECT id, actor, name, path, operations FROM permissions ORDER BY id")
+	}
+	return ps.queryPermissions("SELECT id, actor, name, path, operations FROM permissions WHERE actor = $1 ORDER BY id", actor)
+}
+
+func (ps postgresStore) RevokePermission(id string) (int, error) {
+	_, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, nil
+	}
+
+	return ps.execCount("DELETE FROM permissions WHERE id = $1", id)
+}
+
+func (ps postgresStore) queryPermissions(query string, args ...interface{}) ([]Permission, error) {
+	db, err := ps.dbProvider.Db()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPermissions(rows)
+}
+
+func (ps postgresStore) queryConfigurations(query string, args ...interface{}) (Configurations, error) {
+	var results Configurations
+
+	db, err := ps.dbProvider.Db()
+	if err != nil {
+		return results, err
+	}
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return results, nil
+		}
+		return results, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var config Configuration
+		if err := rows.Scan(&config.ID, &config.Name, &config.Value, nullableTime{&config.CreatedAt}, &config.CreatedBy, &config.Type, &config.Parameters, &config.RestoredFrom); err != nil {
+			return results, err
+		}
+		results = append(results, config)
+	}
+
+	return results, rows.Err()
+}
+
+func (ps postgresStore) execCount(query string, args ...interface{}) (int, error) {
+	db, err := ps.dbProvider.Db()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
+
+// Close closes the connections to the database
+func (ps postgresStore) Close() error {
+	db, err := ps.dbProvider.Db()
+	if err != nil {
+		return err
+	}
+
+	return db.Close()
+}
+
+// Ping checks that the database can be reached
+func (ps postgresStore) Ping() error {
+	db, err := ps.dbProvider.Db()
+	if err != nil {
+		return err
+	}
+
+	return db.Ping()
+}
+
+// DBStats returns the statistics of the connection pool of the database
+func (ps postgresStore) DBStats() (sql.DBStats, error) {
+	db, err := ps.dbProvider.Db()
+	if err != nil {
+		return sql.DBStats{}, err
+	}
+
+	return db.Stats(), nil
+}
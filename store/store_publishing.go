@@ -0,0 +1,86 @@
+package store
+
+import (
+	"time"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+const publishingStoreLogTag = "PublishingStore"
+
+// publishingStore announces every successful write of the wrapped store.
+// Failing to publish does not fail the write; it is logged instead.
+type publishingStore struct {
+	Store
+	publisher EventPublisher
+	logger    boshlog.Logger
+}
+
+func NewPublishingStore(store Store, publisher EventPublisher, logger boshlog.Logger) Store {
+	return publishingStore{Store: store, publisher: publisher, logger: logger}
+}
+
+func (s publishingStore) Put(name string, value string, metadata Metadata) (string, error) {
+	id, err := s.Store.Put(name, value, metadata)
+	if err == nil {
+		s.publish(Event{Type: EventPut, ID: id, Name: name})
+	}
+
+	return id, err
+}
+
+func (s publishingStore) PutIfLatest(name string, value string, expectedID string, metadata Metadata) (string, error) {
+	id, err := s.Store.PutIfLatest(name, value, expectedID, metadata)
+	if err == nil {
+		s.publish(Event{Type: EventPut, ID: id, Name: name})
+	}
+
+	return id, err
+}
+
+func (s publishingStore) Delete(name string) (int, error) {
+	deleted, err := s.Store.Delete(name)
+	if err == nil && deleted > 0 {
+		s.publish(Event{Type: EventDelete, Name: name})
+	}
+
+	return deleted, err
+}
+
+// DeleteByID announces the removal of versions that were visible. Versions of
+// deleted names were already announced when the name was deleted.
+func (s publishingStore) DeleteByID(id string) (int, error) {
+	configuration, err := s.Store.GetByID(id)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := s.Store.DeleteByID(id)
+	if err == nil && deleted > 0 && configuration != (Configuration{}) {
+		s.publish(Event{Type: EventDelete, ID: id, Name: configuration.Name})
+	}
+
+	return deleted, err
+}
+
+func (s publishingStore) Undelete(name string, retention time.Duration) (int, error) {
+	undeleted, err := s.Store.Undelete(name, retention)
+	if err != nil || undeleted == 0 {
+		return undeleted, err
+	}
+
+	configuration, err := s.Store.GetCurrentByName(name)
+	if err == nil {
+		s.publish(Event{Type: EventPut, ID: configuration.ID, Name: name})
+	} else {
+		s.logger.Error(publishingStoreLogTag, "Failed to load undeleted value of '%s': %s", name, err.Error())
+	}
+
+	return undeleted, nil
+}
+
+func (s publishingStore) publish(event Event) {
+	if err := s.publisher.Publish(event); err != nil {
+		s.logger.Error(publishingStoreLogTag, "Failed to publish %s event for '%s': %s", event.Type, event.Name, err.Error())
+	}
+}
@@ -0,0 +1,121 @@
+package store_test
+
+import (
+	. "github.com/cloudfoundry/config-server/store"
+
+	"errors"
+	"time"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/cloudfoundry/config-server/store/storefakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PublishingStore", func() {
+	var (
+		memoryStore   MemoryStore
+		fakeStore     *storefakes.FakeStore
+		fakePublisher *storefakes.FakeEventPublisher
+		store         Store
+	)
+
+	publishedEvents := func() []Event {
+		var events []Event
+		for i := 0; i < fakePublisher.PublishCallCount(); i++ {
+			events = append(events, fakePublisher.PublishArgsForCall(i))
+		}
+		return events
+	}
+
+	BeforeEach(func() {
+		memoryStore = NewMemoryStore()
+		fakeStore = &storefakes.FakeStore{}
+		fakePublisher = &storefakes.FakeEventPublisher{}
+		store = NewPublishingStore(memoryStore, fakePublisher, boshlog.NewLogger(boshlog.LevelNone))
+	})
+
+	It("publishes a put event for every new version", func() {
+		id1, _ := store.Put("luke", "skywalker", Metadata{})
+		id2, _ := store.PutIfLatest("luke", "vader", id1, Metadata{})
+
+		Expect(publishedEvents()).To(Equal([]Event{
+			{Type: EventPut, ID: id1, Name: "luke"},
+			{Type: EventPut, ID: id2, Name: "luke"},
+		}))
+	})
+
+	It("publishes a delete event when a name is deleted", func() {
+		store.Put("luke", "skywalker", Metadata{})
+		store.Delete("luke")
+		store.Delete("luke")
+
+		Expect(publishedEvents()[1:]).To(Equal([]Event{{Type: EventDelete, Name: "luke"}}))
+	})
+
+	It("publishes a delete event with the id when a version is deleted", func() {
+		id, _ := store.Put("luke", "skywalker", Metadata{})
+		store.DeleteByID(id)
+		store.DeleteByID("42")
+
+		Expect(publishedEvents()[1:]).To(Equal([]Event{{Type: EventDelete, ID: id, Name: "luke"}}))
+	})
+
+	It("does not publish when a version of a deleted name is removed", func() {
+		id, _ := store.Put("luke", "skywalker", Metadata{})
+		store.Delete("luke")
+
+		deleted, err := store.DeleteByID(id)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deleted).To(Equal(1))
+
+		Expect(publishedEvents()[2:]).To(BeEmpty())
+	})
+
+	It("publishes a put event with the current id when a name is undeleted", func() {
+		store.Put("luke", "skywalker", Metadata{})
+		id, _ := store.Put("luke", "vader", Metadata{})
+		store.Delete("luke")
+		store.Undelete("luke", time.Hour)
+		store.Undelete("luke", time.Hour)
+
+		Expect(publishedEvents()[3:]).To(Equal([]Event{{Type: EventPut, ID: id, Name: "luke"}}))
+	})
+
+	It("does not publish when the write fails", func() {
+		store = NewPublishingStore(fakeStore, fakePublisher, boshlog.NewLogger(boshlog.LevelNone))
+		fakeStore.PutReturns("", errors.New("Kaboom!"))
+		fakeStore.PutIfLatestReturns("", ErrPreconditionFailed)
+
+		store.Put("luke", "skywalker", Metadata{})
+		_, err := store.PutIfLatest("luke", "skywalker", "1", Metadata{})
+		Expect(err).To(Equal(ErrPreconditionFailed))
+
+		Expect(fakePublisher.PublishCallCount()).To(Equal(0))
+	})
+
+	It("does not fail the write when publishing fails", func() {
+		fakePublisher.PublishReturns(errors.New("Kaboom!"))
+
+		id, err := store.Put("luke", "skywalker", Metadata{})
+		Expect(err).ToNot(HaveOccurred())
+
+		configuration, _ := memoryStore.GetByID(id)
+		Expect(configuration.Value).To(Equal("skywalker"))
+	})
+})
+
+var _ = Describe("EventPublishers", func() {
+	It("publishes to every publisher and returns the first error", func() {
+		failing := &storefakes.FakeEventPublisher{}
+		failing.PublishReturns(errors.New("Kaboom!"))
+		succeeding := &storefakes.FakeEventPublisher{}
+
+		err := NewEventPublishers(failing, succeeding).Publish(Event{Type: EventPut, ID: "1", Name: "luke"})
+		Expect(err).To(MatchError("Kaboom!"))
+
+		Expect(failing.PublishCallCount()).To(Equal(1))
+		Expect(succeeding.PublishArgsForCall(0)).To(Equal(Event{Type: EventPut, ID: "1", Name: "luke"}))
+	})
+})
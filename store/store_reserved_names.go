@@ -0,0 +1,51 @@
+package store
+
+import (
+	"time"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// ErrNameReserved is returned when writing to a name that is reserved, e.g.
+// for a CA configured in the server configuration.
+var ErrNameReserved = errors.Error("Name is reserved")
+
+// reservedNamesStore refuses to write versions of reserved names. Everything
+// else is left to the wrapped store.
+type reservedNamesStore struct {
+	Store
+	reserved map[string]bool
+}
+
+func NewReservedNamesStore(store Store, names []string) Store {
+	reserved := map[string]bool{}
+	for _, name := range names {
+		reserved[name] = true
+	}
+
+	return reservedNamesStore{Store: store, reserved: reserved}
+}
+
+func (s reservedNamesStore) Put(name string, value string, metadata Metadata) (string, error) {
+	if s.reserved[name] {
+		return "", ErrNameReserved
+	}
+
+	return s.Store.Put(name, value, metadata)
+}
+
+func (s reservedNamesStore) PutIfLatest(name string, value string, expectedID string, metadata Metadata) (string, error) {
+	if s.reserved[name] {
+		return "", ErrNameReserved
+	}
+
+	return s.Store.PutIfLatest(name, value, expectedID, metadata)
+}
+
+func (s reservedNamesStore) Undelete(name string, retention time.Duration) (int, error) {
+	if s.reserved[name] {
+		return 0, ErrNameReserved
+	}
+
+	return s.Store.Undelete(name, retention)
+}
@@ -0,0 +1,50 @@
+package store_test
+
+import (
+	"time"
+
+	. "github.com/cloudfoundry/config-server/store"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReservedNamesStore", func() {
+	var (
+		memoryStore MemoryStore
+		store       Store
+	)
+
+	BeforeEach(func() {
+		memoryStore = NewMemoryStore()
+		store = NewReservedNamesStore(memoryStore, []string{"nats"})
+	})
+
+	It("refuses to write versions of reserved names", func() {
+		_, err := store.Put("nats", "value", Metadata{})
+		Expect(err).To(Equal(ErrNameReserved))
+
+		_, err = store.PutIfLatest("nats", "value", "", Metadata{})
+		Expect(err).To(Equal(ErrNameReserved))
+
+		Expect(memoryStore.GetByName("nats")).To(BeEmpty())
+	})
+
+	It("refuses to undelete reserved names", func() {
+		memoryStore.Put("nats", "value", Metadata{})
+		memoryStore.Delete("nats")
+
+		_, err := store.Undelete("nats", time.Hour)
+		Expect(err).To(Equal(ErrNameReserved))
+		Expect(memoryStore.GetByName("nats")).To(BeEmpty())
+	})
+
+	It("writes other names to the wrapped store", func() {
+		id, err := store.Put("/nats", "value", Metadata{})
+		Expect(err).ToNot(HaveOccurred())
+
+		configuration, err := memoryStore.GetByID(id)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(configuration.Name).To(Equal("/nats"))
+	})
+})
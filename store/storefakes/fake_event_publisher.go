@@ -0,0 +1,75 @@
+// This file was generated by counterfeiter
+package storefakes
+
+import (
+	"github.com/cloudfoundry/config-server/store"
+	"sync"
+)
+
+type FakeEventPublisher struct {
+	PublishStub        func(event store.Event) error
+	publishMutex       sync.RWMutex
+	publishArgsForCall []struct {
+		event store.Event
+	}
+	publishReturns struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeEventPublisher) Publish(event store.Event) error {
+	fake.publishMutex.Lock()
+	fake.publishArgsForCall = append(fake.publishArgsForCall, struct {
+		event store.Event
+	}{event})
+	fake.recordInvocation("Publish", []interface{}{event})
+	fake.publishMutex.Unlock()
+	if fake.PublishStub != nil {
+		return fake.PublishStub(event)
+	} else {
+		return fake.publishReturns.result1
+	}
+}
+
+func (fake *FakeEventPublisher) PublishCallCount() int {
+	fake.publishMutex.RLock()
+	defer fake.publishMutex.RUnlock()
+	return len(fake.publishArgsForCall)
+}
+
+func (fake *FakeEventPublisher) PublishArgsForCall(i int) store.Event {
+	fake.publishMutex.RLock()
+	defer fake.publishMutex.RUnlock()
+	return fake.publishArgsForCall[i].event
+}
+
+func (fake *FakeEventPublisher) PublishReturns(result1 error) {
+	fake.PublishStub = nil
+	fake.publishReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeEventPublisher) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.publishMutex.RLock()
+	defer fake.publishMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeEventPublisher) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ store.EventPublisher = new(FakeEventPublisher)
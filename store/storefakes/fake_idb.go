@@ -37,6 +37,13 @@ type FakeIDb struct {
 	queryRowReturns struct {
 		result1 store.IRow
 	}
+	BeginStub        func() (store.ITx, error)
+	beginMutex       sync.RWMutex
+	beginArgsForCall []struct{}
+	beginReturns     struct {
+		result1 store.ITx
+		result2 error
+	}
 	SetMaxOpenConnsStub        func(n int)
 	setMaxOpenConnsMutex       sync.RWMutex
 	setMaxOpenConnsArgsForCall []struct {
@@ -47,9 +54,24 @@ type FakeIDb struct {
 	setMaxIdleConnsArgsForCall []struct {
 		n int
 	}
-	CloseStub        func()
+	CloseStub        func() error
 	closeMutex       sync.RWMutex
 	closeArgsForCall []struct{}
+	closeReturns     struct {
+		result1 error
+	}
+	PingStub        func() error
+	pingMutex       sync.RWMutex
+	pingArgsForCall []struct{}
+	pingReturns     struct {
+		result1 error
+	}
+	StatsStub        func() sql.DBStats
+	statsMutex       sync.RWMutex
+	statsArgsForCall []struct{}
+	statsReturns     struct {
+		result1 sql.DBStats
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -158,6 +180,32 @@ func (fake *FakeIDb) QueryRowReturns(result1 store.IRow) {
 	}{result1}
 }
 
+func (fake *FakeIDb) Begin() (store.ITx, error) {
+	fake.beginMutex.Lock()
+	fake.beginArgsForCall = append(fake.beginArgsForCall, struct{}{})
+	fake.recordInvocation("Begin", []interface{}{})
+	fake.beginMutex.Unlock()
+	if fake.BeginStub != nil {
+		return fake.BeginStub()
+	} else {
+		return fake.beginReturns.result1, fake.beginReturns.result2
+	}
+}
+
+func (fake *FakeIDb) BeginCallCount() int {
+	fake.beginMutex.RLock()
+	defer fake.beginMutex.RUnlock()
+	return len(fake.beginArgsForCall)
+}
+
+func (fake *FakeIDb) BeginReturns(result1 store.ITx, result2 error) {
+	fake.BeginStub = nil
+	fake.beginReturns = struct {
+		result1 store.ITx
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeIDb) SetMaxOpenConns(n int) {
 	fake.setMaxOpenConnsMutex.Lock()
 	fake.setMaxOpenConnsArgsForCall = append(fake.setMaxOpenConnsArgsForCall, struct {
@@ -206,13 +254,15 @@ func (fake *FakeIDb) SetMaxIdleConnsArgsForCall(i int) int {
 	return fake.setMaxIdleConnsArgsForCall[i].n
 }
 
-func (fake *FakeIDb) Close() {
+func (fake *FakeIDb) Close() error {
 	fake.closeMutex.Lock()
 	fake.closeArgsForCall = append(fake.closeArgsForCall, struct{}{})
 	fake.recordInvocation("Close", []interface{}{})
 	fake.closeMutex.Unlock()
 	if fake.CloseStub != nil {
-		fake.CloseStub()
+		return fake.CloseStub()
+	} else {
+		return fake.closeReturns.result1
 	}
 }
 
@@ -222,6 +272,63 @@ func (fake *FakeIDb) CloseCallCount() int {
 	return len(fake.closeArgsForCall)
 }
 
+func (fake *FakeIDb) CloseReturns(result1 error) {
+	fake.CloseStub = nil
+	fake.closeReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIDb) Ping() error {
+	fake.pingMutex.Lock()
+	fake.pingArgsForCall = append(fake.pingArgsForCall, struct{}{})
+	fake.recordInvocation("Ping", []interface{}{})
+	fake.pingMutex.Unlock()
+	if fake.PingStub != nil {
+		return fake.PingStub()
+	} else {
+		return fake.pingReturns.result1
+	}
+}
+
+func (fake *FakeIDb) PingCallCount() int {
+	fake.pingMutex.RLock()
+	defer fake.pingMutex.RUnlock()
+	return len(fake.pingArgsForCall)
+}
+
+func (fake *FakeIDb) PingReturns(result1 error) {
+	fake.PingStub = nil
+	fake.pingReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeIDb) Stats() sql.DBStats {
+	fake.statsMutex.Lock()
+	fake.statsArgsForCall = append(fake.statsArgsForCall, struct{}{})
+	fake.recordInvocation("Stats", []interface{}{})
+	fake.statsMutex.Unlock()
+	if fake.StatsStub != nil {
+		return fake.StatsStub()
+	} else {
+		return fake.statsReturns.result1
+	}
+}
+
+func (fake *FakeIDb) StatsCallCount() int {
+	fake.statsMutex.RLock()
+	defer fake.statsMutex.RUnlock()
+	return len(fake.statsArgsForCall)
+}
+
+func (fake *FakeIDb) StatsReturns(result1 sql.DBStats) {
+	fake.StatsStub = nil
+	fake.statsReturns = struct {
+		result1 sql.DBStats
+	}{result1}
+}
+
 func (fake *FakeIDb) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -231,12 +338,18 @@ func (fake *FakeIDb) Invocations() map[string][][]interface{} {
 	defer fake.queryMutex.RUnlock()
 	fake.queryRowMutex.RLock()
 	defer fake.queryRowMutex.RUnlock()
+	fake.beginMutex.RLock()
+	defer fake.beginMutex.RUnlock()
 	fake.setMaxOpenConnsMutex.RLock()
 	defer fake.setMaxOpenConnsMutex.RUnlock()
 	fake.setMaxIdleConnsMutex.RLock()
 	defer fake.setMaxIdleConnsMutex.RUnlock()
 	fake.closeMutex.RLock()
 	defer fake.closeMutex.RUnlock()
+	fake.pingMutex.RLock()
+	defer fake.pingMutex.RUnlock()
+	fake.statsMutex.RLock()
+	defer fake.statsMutex.RUnlock()
 	return fake.invocations
 }
 
@@ -27,6 +27,12 @@ type FakeIRows struct {
 	scanReturns struct {
 		result1 error
 	}
+	ErrStub        func() error
+	errMutex       sync.RWMutex
+	errArgsForCall []struct{}
+	errReturns     struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -114,6 +120,31 @@ func (fake *FakeIRows) ScanReturns(result1 error) {
 	}{result1}
 }
 
+func (fake *FakeIRows) Err() error {
+	fake.errMutex.Lock()
+	fake.errArgsForCall = append(fake.errArgsForCall, struct{}{})
+	fake.recordInvocation("Err", []interface{}{})
+	fake.errMutex.Unlock()
+	if fake.ErrStub != nil {
+		return fake.ErrStub()
+	} else {
+		return fake.errReturns.result1
+	}
+}
+
+func (fake *FakeIRows) ErrCallCount() int {
+	fake.errMutex.RLock()
+	defer fake.errMutex.RUnlock()
+	return len(fake.errArgsForCall)
+}
+
+func (fake *FakeIRows) ErrReturns(result1 error) {
+	fake.ErrStub = nil
+	fake.errReturns = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeIRows) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -123,6 +154,8 @@ func (fake *FakeIRows) Invocations() map[string][][]interface{} {
 	defer fake.closeMutex.RUnlock()
 	fake.scanMutex.RLock()
 	defer fake.scanMutex.RUnlock()
+	fake.errMutex.RLock()
+	defer fake.errMutex.RUnlock()
 	return fake.invocations
 }
 
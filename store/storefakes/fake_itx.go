@@ -0,0 +1,238 @@
+// This file was generated by counterfeiter
+package storefakes
+
+import (
+	"database/sql"
+	"github.com/cloudfoundry/config-server/store"
+	"sync"
+)
+
+type FakeITx struct {
+	ExecStub        func(query string, args ...interface{}) (sql.Result, error)
+	execMutex       sync.RWMutex
+	execArgsForCall []struct {
+		query string
+		args  []interface{}
+	}
+	execReturns struct {
+		result1 sql.Result
+		result2 error
+	}
+	QueryStub        func(query string, args ...interface{}) (store.IRows, error)
+	queryMutex       sync.RWMutex
+	queryArgsForCall []struct {
+		query string
+		args  []interface{}
+	}
+	queryReturns struct {
+		result1 store.IRows
+		result2 error
+	}
+	QueryRowStub        func(query string, args ...interface{}) store.IRow
+	queryRowMutex       sync.RWMutex
+	queryRowArgsForCall []struct {
+		query string
+		args  []interface{}
+	}
+	queryRowReturns struct {
+		result1 store.IRow
+	}
+	CommitStub        func() error
+	commitMutex       sync.RWMutex
+	commitArgsForCall []struct{}
+	commitReturns     struct {
+		result1 error
+	}
+	RollbackStub        func() error
+	rollbackMutex       sync.RWMutex
+	rollbackArgsForCall []struct{}
+	rollbackReturns     struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeITx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	fake.execMutex.Lock()
+	fake.execArgsForCall = append(fake.execArgsForCall, struct {
+		query string
+		args  []interface{}
+	}{query, args})
+	fake.recordInvocation("Exec", []interface{}{query, args})
+	fake.execMutex.Unlock()
+	if fake.ExecStub != nil {
+		return fake.ExecStub(query, args...)
+	} else {
+		return fake.execReturns.result1, fake.execReturns.result2
+	}
+}
+
+func (fake *FakeITx) ExecCallCount() int {
+	fake.execMutex.RLock()
+	defer fake.execMutex.RUnlock()
+	return len(fake.execArgsForCall)
+}
+
+func (fake *FakeITx) ExecArgsForCall(i int) (string, []interface{}) {
+	fake.execMutex.RLock()
+	defer fake.execMutex.RUnlock()
+	return fake.execArgsForCall[i].query, fake.execArgsForCall[i].args
+}
+
+func (fake *FakeITx) ExecReturns(result1 sql.Result, result2 error) {
+	fake.ExecStub = nil
+	fake.execReturns = struct {
+		result1 sql.Result
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeITx) Query(query string, args ...interface{}) (store.IRows, error) {
+	fake.queryMutex.Lock()
+	fake.queryArgsForCall = append(fake.queryArgsForCall, struct {
+		query string
+		args  []interface{}
+	}{query, args})
+	fake.recordInvocation("Query", []interface{}{query, args})
+	fake.queryMutex.Unlock()
+	if fake.QueryStub != nil {
+		return fake.QueryStub(query, args...)
+	} else {
+		return fake.queryReturns.result1, fake.queryReturns.result2
+	}
+}
+
+func (fake *FakeITx) QueryCallCount() int {
+	fake.queryMutex.RLock()
+	defer fake.queryMutex.RUnlock()
+	return len(fake.queryArgsForCall)
+}
+
+func (fake *FakeITx) QueryArgsForCall(i int) (string, []interface{}) {
+	fake.queryMutex.RLock()
+	defer fake.queryMutex.RUnlock()
+	return fake.queryArgsForCall[i].query, fake.queryArgsForCall[i].args
+}
+
+func (fake *FakeITx) QueryReturns(result1 store.IRows, result2 error) {
+	fake.QueryStub = nil
+	fake.queryReturns = struct {
+		result1 store.IRows
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeITx) QueryRow(query string, args ...interface{}) store.IRow {
+	fake.queryRowMutex.Lock()
+	fake.queryRowArgsForCall = append(fake.queryRowArgsForCall, struct {
+		query string
+		args  []interface{}
+	}{query, args})
+	fake.recordInvocation("QueryRow", []interface{}{query, args})
+	fake.queryRowMutex.Unlock()
+	if fake.QueryRowStub != nil {
+		return fake.QueryRowStub(query, args...)
+	} else {
+		return fake.queryRowReturns.result1
+	}
+}
+
+func (fake *FakeITx) QueryRowCallCount() int {
+	fake.queryRowMutex.RLock()
+	defer fake.queryRowMutex.RUnlock()
+	return len(fake.queryRowArgsForCall)
+}
+
+func (fake *FakeITx) QueryRowArgsForCall(i int) (string, []interface{}) {
+	fake.queryRowMutex.RLock()
+	defer fake.queryRowMutex.RUnlock()
+	return fake.queryRowArgsForCall[i].query, fake.queryRowArgsForCall[i].args
+}
+
+func (fake *FakeITx) QueryRowReturns(result1 store.IRow) {
+	fake.QueryRowStub = nil
+	fake.queryRowReturns = struct {
+		result1 store.IRow
+	}{result1}
+}
+
+func (fake *FakeITx) Commit() error {
+	fake.commitMutex.Lock()
+	fake.commitArgsForCall = append(fake.commitArgsForCall, struct{}{})
+	fake.recordInvocation("Commit", []interface{}{})
+	fake.commitMutex.Unlock()
+	if fake.CommitStub != nil {
+		return fake.CommitStub()
+	} else {
+		return fake.commitReturns.result1
+	}
+}
+
+func (fake *FakeITx) CommitCallCount() int {
+	fake.commitMutex.RLock()
+	defer fake.commitMutex.RUnlock()
+	return len(fake.commitArgsForCall)
+}
+
+func (fake *FakeITx) CommitReturns(result1 error) {
+	fake.CommitStub = nil
+	fake.commitReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeITx) Rollback() error {
+	fake.rollbackMutex.Lock()
+	fake.rollbackArgsForCall = append(fake.rollbackArgsForCall, struct{}{})
+	fake.recordInvocation("Rollback", []interface{}{})
+	fake.rollbackMutex.Unlock()
+	if fake.RollbackStub != nil {
+		return fake.RollbackStub()
+	} else {
+		return fake.rollbackReturns.result1
+	}
+}
+
+func (fake *FakeITx) RollbackCallCount() int {
+	fake.rollbackMutex.RLock()
+	defer fake.rollbackMutex.RUnlock()
+	return len(fake.rollbackArgsForCall)
+}
+
+func (fake *FakeITx) RollbackReturns(result1 error) {
+	fake.RollbackStub = nil
+	fake.rollbackReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeITx) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.execMutex.RLock()
+	defer fake.execMutex.RUnlock()
+	fake.queryMutex.RLock()
+	defer fake.queryMutex.RUnlock()
+	fake.queryRowMutex.RLock()
+	defer fake.queryRowMutex.RUnlock()
+	fake.commitMutex.RLock()
+	defer fake.commitMutex.RUnlock()
+	fake.rollbackMutex.RLock()
+	defer fake.rollbackMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakeITx) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ store.ITx = new(FakeITx)
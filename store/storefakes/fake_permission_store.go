@@ -0,0 +1,167 @@
+// This file was generated by counterfeiter
+package storefakes
+
+import (
+	"github.com/cloudfoundry/config-server/store"
+	"sync"
+)
+
+type FakePermissionStore struct {
+	GrantPermissionStub        func(permission store.Permission) (string, error)
+	grantPermissionMutex       sync.RWMutex
+	grantPermissionArgsForCall []struct {
+		permission store.Permission
+	}
+	grantPermissionReturns struct {
+		result1 string
+		result2 error
+	}
+	GetPermissionsStub        func(actor string) ([]store.Permission, error)
+	getPermissionsMutex       sync.RWMutex
+	getPermissionsArgsForCall []struct {
+		actor string
+	}
+	getPermissionsReturns struct {
+		result1 []store.Permission
+		result2 error
+	}
+	RevokePermissionStub        func(id string) (int, error)
+	revokePermissionMutex       sync.RWMutex
+	revokePermissionArgsForCall []struct {
+		id string
+	}
+	revokePermissionReturns struct {
+		result1 int
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakePermissionStore) GrantPermission(permission store.Permission) (string, error) {
+	fake.grantPermissionMutex.Lock()
+	fake.grantPermissionArgsForCall = append(fake.grantPermissionArgsForCall, struct {
+		permission store.Permission
+	}{permission})
+	fake.recordInvocation("GrantPermission", []interface{}{permission})
+	fake.grantPermissionMutex.Unlock()
+	if fake.GrantPermissionStub != nil {
+		return fake.GrantPermissionStub(permission)
+	} else {
+		return fake.grantPermissionReturns.result1, fake.grantPermissionReturns.result2
+	}
+}
+
+func (fake *FakePermissionStore) GrantPermissionCallCount() int {
+	fake.grantPermissionMutex.RLock()
+	defer fake.grantPermissionMutex.RUnlock()
+	return len(fake.grantPermissionArgsForCall)
+}
+
+func (fake *FakePermissionStore) GrantPermissionArgsForCall(i int) store.Permission {
+	fake.grantPermissionMutex.RLock()
+	defer fake.grantPermissionMutex.RUnlock()
+	return fake.grantPermissionArgsForCall[i].permission
+}
+
+func (fake *FakePermissionStore) GrantPermissionReturns(result1 string, result2 error) {
+	fake.GrantPermissionStub = nil
+	fake.grantPermissionReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakePermissionStore) GetPermissions(actor string) ([]store.Permission, error) {
+	fake.getPermissionsMutex.Lock()
+	fake.getPermissionsArgsForCall = append(fake.getPermissionsArgsForCall, struct {
+		actor string
+	}{actor})
+	fake.recordInvocation("GetPermissions", []interface{}{actor})
+	fake.getPermissionsMutex.Unlock()
+	if fake.GetPermissionsStub != nil {
+		return fake.GetPermissionsStub(actor)
+	} else {
+		return fake.getPermissionsReturns.result1, fake.getPermissionsReturns.result2
+	}
+}
+
+func (fake *FakePermissionStore) GetPermissionsCallCount() int {
+	fake.getPermissionsMutex.RLock()
+	defer fake.getPermissionsMutex.RUnlock()
+	return len(fake.getPermissionsArgsForCall)
+}
+
+func (fake *FakePermissionStore) GetPermissionsArgsForCall(i int) string {
+	fake.getPermissionsMutex.RLock()
+	defer fake.getPermissionsMutex.RUnlock()
+	return fake.getPermissionsArgsForCall[i].actor
+}
+
+func (fake *FakePermissionStore) GetPermissionsReturns(result1 []store.Permission, result2 error) {
+	fake.GetPermissionsStub = nil
+	fake.getPermissionsReturns = struct {
+		result1 []store.Permission
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakePermissionStore) RevokePermission(id string) (int, error) {
+	fake.revokePermissionMutex.Lock()
+	fake.revokePermissionArgsForCall = append(fake.revokePermissionArgsForCall, struct {
+		id string
+	}{id})
+	fake.recordInvocation("RevokePermission", []interface{}{id})
+	fake.revokePermissionMutex.Unlock()
+	if fake.RevokePermissionStub != nil {
+		return fake.RevokePermissionStub(id)
+	} else {
+		return fake.revokePermissionReturns.result1, fake.revokePermissionReturns.result2
+	}
+}
+
+func (fake *FakePermissionStore) RevokePermissionCallCount() int {
+	fake.revokePermissionMutex.RLock()
+	defer fake.revokePermissionMutex.RUnlock()
+	return len(fake.revokePermissionArgsForCall)
+}
+
+func (fake *FakePermissionStore) RevokePermissionArgsForCall(i int) string {
+	fake.revokePermissionMutex.RLock()
+	defer fake.revokePermissionMutex.RUnlock()
+	return fake.revokePermissionArgsForCall[i].id
+}
+
+func (fake *FakePermissionStore) RevokePermissionReturns(result1 int, result2 error) {
+	fake.RevokePermissionStub = nil
+	fake.revokePermissionReturns = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakePermissionStore) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.grantPermissionMutex.RLock()
+	defer fake.grantPermissionMutex.RUnlock()
+	fake.getPermissionsMutex.RLock()
+	defer fake.getPermissionsMutex.RUnlock()
+	fake.revokePermissionMutex.RLock()
+	defer fake.revokePermissionMutex.RUnlock()
+	return fake.invocations
+}
+
+func (fake *FakePermissionStore) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ store.PermissionStore = new(FakePermissionStore)
@@ -4,19 +4,33 @@ package storefakes
 import (
 	"github.com/cloudfoundry/config-server/store"
 	"sync"
+	"time"
 )
 
 type FakeStore struct {
-	PutStub        func(key string, value string) (string, error)
+	PutStub        func(key string, value string, metadata store.Metadata) (string, error)
 	putMutex       sync.RWMutex
 	putArgsForCall []struct {
-		key   string
-		value string
+		key      string
+		value    string
+		metadata store.Metadata
 	}
 	putReturns struct {
 		result1 string
 		result2 error
 	}
+	PutIfLatestStub        func(key string, value string, expectedID string, metadata store.Metadata) (string, error)
+	putIfLatestMutex       sync.RWMutex
+	putIfLatestArgsForCall []struct {
+		key        string
+		value      string
+		expectedID string
+		metadata   store.Metadata
+	}
+	putIfLatestReturns struct {
+		result1 string
+		result2 error
+	}
 	GetByNameStub        func(name string) (store.Configurations, error)
 	getByNameMutex       sync.RWMutex
 	getByNameArgsForCall []struct {
@@ -26,6 +40,35 @@ type FakeStore struct {
 		result1 store.Configurations
 		result2 error
 	}
+	GetPageByNameStub        func(name string, limit int, offset int) (store.Configurations, error)
+	getPageByNameMutex       sync.RWMutex
+	getPageByNameArgsForCall []struct {
+		name   string
+		limit  int
+		offset int
+	}
+	getPageByNameReturns struct {
+		result1 store.Configurations
+		result2 error
+	}
+	GetCurrentByNameStub        func(name string) (store.Configuration, error)
+	getCurrentByNameMutex       sync.RWMutex
+	getCurrentByNameArgsForCall []struct {
+		name string
+	}
+	getCurrentByNameReturns struct {
+		result1 store.Configuration
+		result2 error
+	}
+	GetByPathStub        func(path string) (store.Configurations, error)
+	getByPathMutex       sync.RWMutex
+	getByPathArgsForCall []struct {
+		path string
+	}
+	getByPathReturns struct {
+		result1 store.Configurations
+		result2 error
+	}
 	GetByIDStub        func(id string) (store.Configuration, error)
 	getByIDMutex       sync.RWMutex
 	getByIDArgsForCall []struct {
@@ -44,20 +87,49 @@ type FakeStore struct {
 		result1 int
 		result2 error
 	}
+	DeleteByIDStub        func(id string) (int, error)
+	deleteByIDMutex       sync.RWMutex
+	deleteByIDArgsForCall []struct {
+		id string
+	}
+	deleteByIDReturns struct {
+		result1 int
+		result2 error
+	}
+	UndeleteStub        func(name string, retention time.Duration) (int, error)
+	undeleteMutex       sync.RWMutex
+	undeleteArgsForCall []struct {
+		name      string
+		retention time.Duration
+	}
+	undeleteReturns struct {
+		result1 int
+		result2 error
+	}
+	PurgeDeletedStub        func(retention time.Duration) (int, error)
+	purgeDeletedMutex       sync.RWMutex
+	purgeDeletedArgsForCall []struct {
+		retention time.Duration
+	}
+	purgeDeletedReturns struct {
+		result1 int
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeStore) Put(key string, value string) (string, error) {
+func (fake *FakeStore) Put(key string, value string, metadata store.Metadata) (string, error) {
 	fake.putMutex.Lock()
 	fake.putArgsForCall = append(fake.putArgsForCall, struct {
-		key   string
-		value string
-	}{key, value})
-	fake.recordInvocation("Put", []interface{}{key, value})
+		key      string
+		value    string
+		metadata store.Metadata
+	}{key, value, metadata})
+	fake.recordInvocation("Put", []interface{}{key, value, metadata})
 	fake.putMutex.Unlock()
 	if fake.PutStub != nil {
-		return fake.PutStub(key, value)
+		return fake.PutStub(key, value, metadata)
 	} else {
 		return fake.putReturns.result1, fake.putReturns.result2
 	}
@@ -69,10 +141,10 @@ func (fake *FakeStore) PutCallCount() int {
 	return len(fake.putArgsForCall)
 }
 
-func (fake *FakeStore) PutArgsForCall(i int) (string, string) {
+func (fake *FakeStore) PutArgsForCall(i int) (string, string, store.Metadata) {
 	fake.putMutex.RLock()
 	defer fake.putMutex.RUnlock()
-	return fake.putArgsForCall[i].key, fake.putArgsForCall[i].value
+	return fake.putArgsForCall[i].key, fake.putArgsForCall[i].value, fake.putArgsForCall[i].metadata
 }
 
 func (fake *FakeStore) PutReturns(result1 string, result2 error) {
@@ -83,6 +155,43 @@ func (fake *FakeStore) PutReturns(result1 string, result2 error) {
 	}{result1, result2}
 }
 
+func (fake *FakeStore) PutIfLatest(key string, value string, expectedID string, metadata store.Metadata) (string, error) {
+	fake.putIfLatestMutex.Lock()
+	fake.putIfLatestArgsForCall = append(fake.putIfLatestArgsForCall, struct {
+		key        string
+		value      string
+		expectedID string
+		metadata   store.Metadata
+	}{key, value, expectedID, metadata})
+	fake.recordInvocation("PutIfLatest", []interface{}{key, value, expectedID, metadata})
+	fake.putIfLatestMutex.Unlock()
+	if fake.PutIfLatestStub != nil {
+		return fake.PutIfLatestStub(key, value, expectedID, metadata)
+	} else {
+		return fake.putIfLatestReturns.result1, fake.putIfLatestReturns.result2
+	}
+}
+
+func (fake *FakeStore) PutIfLatestCallCount() int {
+	fake.putIfLatestMutex.RLock()
+	defer fake.putIfLatestMutex.RUnlock()
+	return len(fake.putIfLatestArgsForCall)
+}
+
+func (fake *FakeStore) PutIfLatestArgsForCall(i int) (string, string, string, store.Metadata) {
+	fake.putIfLatestMutex.RLock()
+	defer fake.putIfLatestMutex.RUnlock()
+	return fake.putIfLatestArgsForCall[i].key, fake.putIfLatestArgsForCall[i].value, fake.putIfLatestArgsForCall[i].expectedID, fake.putIfLatestArgsForCall[i].metadata
+}
+
+func (fake *FakeStore) PutIfLatestReturns(result1 string, result2 error) {
+	fake.PutIfLatestStub = nil
+	fake.putIfLatestReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeStore) GetByName(name string) (store.Configurations, error) {
 	fake.getByNameMutex.Lock()
 	fake.getByNameArgsForCall = append(fake.getByNameArgsForCall, struct {
@@ -117,6 +226,110 @@ func (fake *FakeStore) GetByNameReturns(result1 store.Configurations, result2 er
 	}{result1, result2}
 }
 
+func (fake *FakeStore) GetPageByName(name string, limit int, offset int) (store.Configurations, error) {
+	fake.getPageByNameMutex.Lock()
+	fake.getPageByNameArgsForCall = append(fake.getPageByNameArgsForCall, struct {
+		name   string
+		limit  int
+		offset int
+	}{name, limit, offset})
+	fake.recordInvocation("GetPageByName", []interface{}{name, limit, offset})
+	fake.getPageByNameMutex.Unlock()
+	if fake.GetPageByNameStub != nil {
+		return fake.GetPageByNameStub(name, limit, offset)
+	} else {
+		return fake.getPageByNameReturns.result1, fake.getPageByNameReturns.result2
+	}
+}
+
+func (fake *FakeStore) GetPageByNameCallCount() int {
+	fake.getPageByNameMutex.RLock()
+	defer fake.getPageByNameMutex.RUnlock()
+	return len(fake.getPageByNameArgsForCall)
+}
+
+func (fake *FakeStore) GetPageByNameArgsForCall(i int) (string, int, int) {
+	fake.getPageByNameMutex.RLock()
+	defer fake.getPageByNameMutex.RUnlock()
+	return fake.getPageByNameArgsForCall[i].name, fake.getPageByNameArgsForCall[i].limit, fake.getPageByNameArgsForCall[i].offset
+}
+
+func (fake *FakeStore) GetPageByNameReturns(result1 store.Configurations, result2 error) {
+	fake.GetPageByNameStub = nil
+	fake.getPageByNameReturns = struct {
+		result1 store.Configurations
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStore) GetCurrentByName(name string) (store.Configuration, error) {
+	fake.getCurrentByNameMutex.Lock()
+	fake.getCurrentByNameArgsForCall = append(fake.getCurrentByNameArgsForCall, struct {
+		name string
+	}{name})
+	fake.recordInvocation("GetCurrentByName", []interface{}{name})
+	fake.getCurrentByNameMutex.Unlock()
+	if fake.GetCurrentByNameStub != nil {
+		return fake.GetCurrentByNameStub(name)
+	} else {
+		return fake.getCurrentByNameReturns.result1, fake.getCurrentByNameReturns.result2
+	}
+}
+
+func (fake *FakeStore) GetCurrentByNameCallCount() int {
+	fake.getCurrentByNameMutex.RLock()
+	defer fake.getCurrentByNameMutex.RUnlock()
+	return len(fake.getCurrentByNameArgsForCall)
+}
+
+func (fake *FakeStore) GetCurrentByNameArgsForCall(i int) string {
+	fake.getCurrentByNameMutex.RLock()
+	defer fake.getCurrentByNameMutex.RUnlock()
+	return fake.getCurrentByNameArgsForCall[i].name
+}
+
+func (fake *FakeStore) GetCurrentByNameReturns(result1 store.Configuration, result2 error) {
+	fake.GetCurrentByNameStub = nil
+	fake.getCurrentByNameReturns = struct {
+		result1 store.Configuration
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStore) GetByPath(path string) (store.Configurations, error) {
+	fake.getByPathMutex.Lock()
+	fake.getByPathArgsForCall = append(fake.getByPathArgsForCall, struct {
+		path string
+	}{path})
+	fake.recordInvocation("GetByPath", []interface{}{path})
+	fake.getByPathMutex.Unlock()
+	if fake.GetByPathStub != nil {
+		return fake.GetByPathStub(path)
+	} else {
+		return fake.getByPathReturns.result1, fake.getByPathReturns.result2
+	}
+}
+
+func (fake *FakeStore) GetByPathCallCount() int {
+	fake.getByPathMutex.RLock()
+	defer fake.getByPathMutex.RUnlock()
+	return len(fake.getByPathArgsForCall)
+}
+
+func (fake *FakeStore) GetByPathArgsForCall(i int) string {
+	fake.getByPathMutex.RLock()
+	defer fake.getByPathMutex.RUnlock()
+	return fake.getByPathArgsForCall[i].path
+}
+
+func (fake *FakeStore) GetByPathReturns(result1 store.Configurations, result2 error) {
+	fake.GetByPathStub = nil
+	fake.getByPathReturns = struct {
+		result1 store.Configurations
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeStore) GetByID(id string) (store.Configuration, error) {
 	fake.getByIDMutex.Lock()
 	fake.getByIDArgsForCall = append(fake.getByIDArgsForCall, struct {
@@ -185,17 +398,134 @@ func (fake *FakeStore) DeleteReturns(result1 int, result2 error) {
 	}{result1, result2}
 }
 
+func (fake *FakeStore) DeleteByID(id string) (int, error) {
+	fake.deleteByIDMutex.Lock()
+	fake.deleteByIDArgsForCall = append(fake.deleteByIDArgsForCall, struct {
+		id string
+	}{id})
+	fake.recordInvocation("DeleteByID", []interface{}{id})
+	fake.deleteByIDMutex.Unlock()
+	if fake.DeleteByIDStub != nil {
+		return fake.DeleteByIDStub(id)
+	} else {
+		return fake.deleteByIDReturns.result1, fake.deleteByIDReturns.result2
+	}
+}
+
+func (fake *FakeStore) DeleteByIDCallCount() int {
+	fake.deleteByIDMutex.RLock()
+	defer fake.deleteByIDMutex.RUnlock()
+	return len(fake.deleteByIDArgsForCall)
+}
+
+func (fake *FakeStore) DeleteByIDArgsForCall(i int) string {
+	fake.deleteByIDMutex.RLock()
+	defer fake.deleteByIDMutex.RUnlock()
+	return fake.deleteByIDArgsForCall[i].id
+}
+
+func (fake *FakeStore) DeleteByIDReturns(result1 int, result2 error) {
+	fake.DeleteByIDStub = nil
+	fake.deleteByIDReturns = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStore) Undelete(name string, retention time.Duration) (int, error) {
+	fake.undeleteMutex.Lock()
+	fake.undeleteArgsForCall = append(fake.undeleteArgsForCall, struct {
+		name      string
+		retention time.Duration
+	}{name, retention})
+	fake.recordInvocation("Undelete", []interface{}{name, retention})
+	fake.undeleteMutex.Unlock()
+	if fake.UndeleteStub != nil {
+		return fake.UndeleteStub(name, retention)
+	} else {
+		return fake.undeleteReturns.result1, fake.undeleteReturns.result2
+	}
+}
+
+func (fake *FakeStore) UndeleteCallCount() int {
+	fake.undeleteMutex.RLock()
+	defer fake.undeleteMutex.RUnlock()
+	return len(fake.undeleteArgsForCall)
+}
+
+func (fake *FakeStore) UndeleteArgsForCall(i int) (string, time.Duration) {
+	fake.undeleteMutex.RLock()
+	defer fake.undeleteMutex.RUnlock()
+	return fake.undeleteArgsForCall[i].name, fake.undeleteArgsForCall[i].retention
+}
+
+func (fake *FakeStore) UndeleteReturns(result1 int, result2 error) {
+	fake.UndeleteStub = nil
+	fake.undeleteReturns = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeStore) PurgeDeleted(retention time.Duration) (int, error) {
+	fake.purgeDeletedMutex.Lock()
+	fake.purgeDeletedArgsForCall = append(fake.purgeDeletedArgsForCall, struct {
+		retention time.Duration
+	}{retention})
+	fake.recordInvocation("PurgeDeleted", []interface{}{retention})
+	fake.purgeDeletedMutex.Unlock()
+	if fake.PurgeDeletedStub != nil {
+		return fake.PurgeDeletedStub(retention)
+	} else {
+		return fake.purgeDeletedReturns.result1, fake.purgeDeletedReturns.result2
+	}
+}
+
+func (fake *FakeStore) PurgeDeletedCallCount() int {
+	fake.purgeDeletedMutex.RLock()
+	defer fake.purgeDeletedMutex.RUnlock()
+	return len(fake.purgeDeletedArgsForCall)
+}
+
+func (fake *FakeStore) PurgeDeletedArgsForCall(i int) time.Duration {
+	fake.purgeDeletedMutex.RLock()
+	defer fake.purgeDeletedMutex.RUnlock()
+	return fake.purgeDeletedArgsForCall[i].retention
+}
+
+func (fake *FakeStore) PurgeDeletedReturns(result1 int, result2 error) {
+	fake.PurgeDeletedStub = nil
+	fake.purgeDeletedReturns = struct {
+		result1 int
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeStore) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.putMutex.RLock()
 	defer fake.putMutex.RUnlock()
+	fake.putIfLatestMutex.RLock()
+	defer fake.putIfLatestMutex.RUnlock()
 	fake.getByNameMutex.RLock()
 	defer fake.getByNameMutex.RUnlock()
+	fake.getPageByNameMutex.RLock()
+	defer fake.getPageByNameMutex.RUnlock()
+	fake.getCurrentByNameMutex.RLock()
+	defer fake.getCurrentByNameMutex.RUnlock()
+	fake.getByPathMutex.RLock()
+	defer fake.getByPathMutex.RUnlock()
 	fake.getByIDMutex.RLock()
 	defer fake.getByIDMutex.RUnlock()
 	fake.deleteMutex.RLock()
 	defer fake.deleteMutex.RUnlock()
+	fake.deleteByIDMutex.RLock()
+	defer fake.deleteByIDMutex.RUnlock()
+	fake.undeleteMutex.RLock()
+	defer fake.undeleteMutex.RUnlock()
+	fake.purgeDeletedMutex.RLock()
+	defer fake.purgeDeletedMutex.RUnlock()
 	return fake.invocations
 }
 
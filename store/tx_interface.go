@@ -0,0 +1,11 @@
+package store
+
+import "database/sql"
+
+type ITx interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (IRows, error)
+	QueryRow(query string, args ...interface{}) IRow
+	Commit() error
+	Rollback() error
+}
@@ -0,0 +1,34 @@
+package store
+
+import (
+	"database/sql"
+)
+
+type TxWrapper struct {
+	tx *sql.Tx
+}
+
+func NewTxWrapper(tx *sql.Tx) TxWrapper {
+	return TxWrapper{tx}
+}
+
+func (w TxWrapper) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return w.tx.Exec(query, args...)
+}
+
+func (w TxWrapper) Query(query string, args ...interface{}) (IRows, error) {
+	rows, err := w.tx.Query(query, args...)
+	return NewRowsWrapper(rows), err
+}
+
+func (w TxWrapper) QueryRow(query string, args ...interface{}) IRow {
+	return NewRowWrapper(w.tx.QueryRow(query, args...))
+}
+
+func (w TxWrapper) Commit() error {
+	return w.tx.Commit()
+}
+
+func (w TxWrapper) Rollback() error {
+	return w.tx.Rollback()
+}
@@ -29,6 +29,13 @@ type certParams struct {
 	AlternativeNames []string `yaml:"alternative_names"`
 	IsCA             bool     `yaml:"is_ca"`
 	CAName           string   `yaml:"ca"`
+	ExtendedKeyUsage []string `yaml:"extended_key_usage"`
+}
+
+// extendedKeyUsages are the values of the extended_key_usage parameter
+var extendedKeyUsages = map[string]x509.ExtKeyUsage{
+	"server_auth": x509.ExtKeyUsageServerAuth,
+	"client_auth": x509.ExtKeyUsageClientAuth,
 }
 
 func NewCertificateGenerator(loader CertsLoader) CertificateGenerator {
@@ -89,7 +96,10 @@ func (cfg CertificateGenerator) generateCertificate(cParams certParams) (CertRes
 		rootCARaw = certificateRaw
 	} else {
 		template.KeyUsage = x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature
-		template.ExtKeyUsage = append(template.ExtKeyUsage, x509.ExtKeyUsageServerAuth)
+		template.ExtKeyUsage, err = extKeyUsages(cParams.ExtendedKeyUsage)
+		if err != nil {
+			return certResponse, err
+		}
 
 		if cfg.loader == nil {
 			panic("Expected CertificateGenerator to have Loader set")
@@ -128,6 +138,25 @@ func (cfg CertificateGenerator) generateCertificate(cParams certParams) (CertRes
 	return certResponse, nil
 }
 
+// extKeyUsages returns the extended key usages named by usages, defaulting to
+// server authentication
+func extKeyUsages(usages []string) ([]x509.ExtKeyUsage, error) {
+	if len(usages) == 0 {
+		return []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}, nil
+	}
+
+	var result []x509.ExtKeyUsage
+	for _, usage := range usages {
+		extKeyUsage, found := extendedKeyUsages[usage]
+		if !found {
+			return nil, errors.Errorf("Unknown extended key usage '%s'", usage)
+		}
+		result = append(result, extKeyUsage)
+	}
+
+	return result, nil
+}
+
 func objToStruct(input interface{}, str interface{}) error {
 	valBytes, err := yaml.Marshal(input)
 	if err != nil {
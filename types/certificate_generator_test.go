@@ -146,6 +146,21 @@ JQnj8h8DPalW3Dn7oQXZhjCCeY7qK+z+KvgqDwTyv8HpP6Eetwhm
 					Expect(certificate.ExtKeyUsage).To(Equal([]x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}))
 				})
 
+				It("sets ExtKeyUsage as passed in", func() {
+					params["extended_key_usage"] = []interface{}{"client_auth", "server_auth"}
+					certResp := getCertResp(generator, params)
+					certificate, _ := parseCertString(certResp.Certificate)
+
+					Expect(certificate.ExtKeyUsage).To(Equal([]x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth}))
+				})
+
+				It("returns an error for unknown extended key usages", func() {
+					params["extended_key_usage"] = []interface{}{"code_signing"}
+					_, err := generator.Generate(params)
+					Expect(err).ToNot(BeNil())
+					Expect(err.Error()).To(Equal("Unknown extended key usage 'code_signing'"))
+				})
+
 				It("sets common name and alternative name as passed in", func() {
 					altNames := []interface{}{"cloudfoundry.com", "example.com"}
 					params["alternative_names"] = altNames
@@ -0,0 +1,95 @@
+package types
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+
+	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cloudfoundry/config-server/config"
+	"github.com/cloudfoundry/config-server/store"
+)
+
+type storeCertsLoader struct {
+	store         store.Store
+	defaultLoader CertsLoader
+	configuredCAs map[string]config.CAConfig
+}
+
+// NewStoreCertsLoader returns a CertsLoader that loads named CAs from the
+// latest value stored under that name. Requests without a CA name, CAs in
+// configuredCAs and names that do not exist in the store are delegated to
+// defaultLoader. Configured CAs take precedence so that storing a value under
+// their name does not replace them.
+func NewStoreCertsLoader(store store.Store, defaultLoader CertsLoader, configuredCAs map[string]config.CAConfig) CertsLoader {
+	return storeCertsLoader{store: store, defaultLoader: defaultLoader, configuredCAs: configuredCAs}
+}
+
+func (l storeCertsLoader) LoadCerts(caName string) (*x509.Certificate, *rsa.PrivateKey, error) {
+	if _, configured := l.configuredCAs[caName]; caName == "" || configured {
+		return l.defaultLoader.LoadCerts(caName)
+	}
+
+	configuration, err := l.store.GetCurrentByName(caName)
+	if err != nil {
+		return nil, nil, errors.WrapErrorf(err, "Failed to load CA '%s'", caName)
+	}
+
+	if configuration == (store.Configuration{}) {
+		return l.defaultLoader.LoadCerts(caName)
+	}
+
+	var stored struct {
+		Value CertResponse `json:"value"`
+	}
+
+	err = json.Unmarshal([]byte(configuration.Value), &stored)
+	if err != nil {
+		return nil, nil, errors.WrapErrorf(err, "Failed to parse CA '%s'", caName)
+	}
+
+	crt, err := l.parseCertificate(caName, stored.Value.Certificate)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	key, err := l.parsePrivateKey(caName, stored.Value.PrivateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return crt, key, nil
+}
+
+func (l storeCertsLoader) parseCertificate(caName, certificate string) (*x509.Certificate, error) {
+	cpb, _ := pem.Decode([]byte(certificate))
+	if cpb == nil {
+		return nil, errors.Errorf("CA '%s' does not contain a PEM encoded certificate", caName)
+	}
+
+	crt, err := x509.ParseCertificate(cpb.Bytes)
+	if err != nil {
+		return nil, errors.WrapErrorf(err, "Failed to parse certificate of CA '%s'", caName)
+	}
+
+	if !crt.IsCA {
+		return nil, errors.Errorf("Certificate '%s' is not a CA", caName)
+	}
+
+	return crt, nil
+}
+
+func (l storeCertsLoader) parsePrivateKey(caName, privateKey string) (*rsa.PrivateKey, error) {
+	kpb, _ := pem.Decode([]byte(privateKey))
+	if kpb == nil {
+		return nil, errors.Errorf("CA '%s' does not contain a PEM encoded private key", caName)
+	}
+
+	key, err := x509.ParsePKCS1PrivateKey(kpb.Bytes)
+	if err != nil {
+		return nil, errors.WrapErrorf(err, "Failed to parse private key of CA '%s'", caName)
+	}
+
+	return key, nil
+}
@@ -0,0 +1,142 @@
+package types_test
+
+import (
+	. "github.com/cloudfoundry/config-server/types"
+
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+
+	"github.com/cloudfoundry/config-server/config"
+	"github.com/cloudfoundry/config-server/store"
+	"github.com/cloudfoundry/config-server/store/storefakes"
+	"github.com/cloudfoundry/config-server/types/typesfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func storedCertValue(certResp CertResponse) string {
+	bytes, err := json.Marshal(map[string]interface{}{"value": certResp})
+	Expect(err).ToNot(HaveOccurred())
+	return string(bytes)
+}
+
+var _ = Describe("StoreCertsLoader", func() {
+	var (
+		fakeStore         *storefakes.FakeStore
+		fakeDefaultLoader *typesfakes.FakeCertsLoader
+		loader            CertsLoader
+		caResp            CertResponse
+	)
+
+	BeforeEach(func() {
+		fakeStore = &storefakes.FakeStore{}
+		fakeDefaultLoader = &typesfakes.FakeCertsLoader{}
+		loader = NewStoreCertsLoader(fakeStore, fakeDefaultLoader, map[string]config.CAConfig{
+			"nats": {CertificateFilePath: "nats.crt", PrivateKeyFilePath: "nats.key"},
+		})
+
+		caResp = getCertResp(NewCertificateGenerator(fakeDefaultLoader), map[interface{}]interface{}{
+			"common_name": "my-ca",
+			"is_ca":       true,
+		})
+	})
+
+	Context("when CA name is empty", func() {
+		It("delegates to the default loader", func() {
+			defaultCA := &x509.Certificate{}
+			fakeDefaultLoader.LoadCertsReturns(defaultCA, nil, nil)
+
+			crt, _, err := loader.LoadCerts("")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(crt).To(Equal(defaultCA))
+			Expect(fakeStore.GetCurrentByNameCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when CA name is configured", func() {
+		It("delegates to the default loader even when a value is stored under that name", func() {
+			fakeStore.GetCurrentByNameReturns(store.Configuration{ID: "2", Name: "nats", Value: storedCertValue(caResp)}, nil)
+			natsCA := &x509.Certificate{}
+			fakeDefaultLoader.LoadCertsReturns(natsCA, nil, nil)
+
+			crt, _, err := loader.LoadCerts("nats")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(crt).To(Equal(natsCA))
+			Expect(fakeDefaultLoader.LoadCertsArgsForCall(0)).To(Equal("nats"))
+			Expect(fakeStore.GetCurrentByNameCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when CA name is given", func() {
+		It("loads the latest certificate and key stored under that name", func() {
+			fakeStore.GetCurrentByNameReturns(store.Configuration{ID: "2", Name: "/deployments/foo/my_ca", Value: storedCertValue(caResp)}, nil)
+
+			crt, key, err := loader.LoadCerts("/deployments/foo/my_ca")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeStore.GetCurrentByNameArgsForCall(0)).To(Equal("/deployments/foo/my_ca"))
+			Expect(fakeDefaultLoader.LoadCertsCallCount()).To(Equal(0))
+
+			expectedCrt, err := parseCertString(caResp.Certificate)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(crt.Equal(expectedCrt)).To(BeTrue())
+			Expect(key.PublicKey.N).To(Equal(expectedCrt.PublicKey.(*rsa.PublicKey).N))
+		})
+
+		It("can be used to sign certificates", func() {
+			fakeStore.GetCurrentByNameReturns(store.Configuration{ID: "2", Name: "my_ca", Value: storedCertValue(caResp)}, nil)
+
+			certResp := getCertResp(NewCertificateGenerator(loader), map[interface{}]interface{}{
+				"common_name": "leaf",
+				"ca":          "my_ca",
+			})
+			Expect(certResp.CA).To(Equal(caResp.Certificate))
+
+			caCrt, _ := parseCertString(caResp.Certificate)
+			crt, _ := parseCertString(certResp.Certificate)
+			Expect(crt.CheckSignatureFrom(caCrt)).To(Succeed())
+		})
+
+		It("delegates to the default loader when CA does not exist in the store", func() {
+			fakeStore.GetCurrentByNameReturns(store.Configuration{}, nil)
+			fakeDefaultLoader.LoadCertsReturns(nil, nil, errors.New("CA 'missing' not found"))
+
+			_, _, err := loader.LoadCerts("missing")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("CA 'missing' not found"))
+			Expect(fakeDefaultLoader.LoadCertsArgsForCall(0)).To(Equal("missing"))
+		})
+
+		It("returns an error when store fails", func() {
+			fakeStore.GetCurrentByNameReturns(store.Configuration{}, errors.New("Kaboom!"))
+
+			_, _, err := loader.LoadCerts("my_ca")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Failed to load CA 'my_ca'"))
+		})
+
+		It("returns an error when stored value is not a certificate", func() {
+			fakeStore.GetCurrentByNameReturns(store.Configuration{ID: "2", Name: "my_ca", Value: `{"value":"some-password"}`}, nil)
+
+			_, _, err := loader.LoadCerts("my_ca")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Failed to parse CA 'my_ca'"))
+		})
+
+		It("returns an error when stored certificate is not a CA", func() {
+			fakeStore.GetCurrentByNameReturns(store.Configuration{ID: "2", Name: "my_ca", Value: storedCertValue(caResp)}, nil)
+			leafResp := getCertResp(NewCertificateGenerator(loader), map[interface{}]interface{}{
+				"common_name": "leaf",
+				"ca":          "my_ca",
+			})
+
+			fakeStore.GetCurrentByNameReturns(store.Configuration{ID: "3", Name: "leaf", Value: storedCertValue(leafResp)}, nil)
+
+			_, _, err := loader.LoadCerts("leaf")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Certificate 'leaf' is not a CA"))
+		})
+	})
+})
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"time"
+)
+
+// GenerationObserver is told how long generating a value of a type took
+type GenerationObserver func(valueType string, duration time.Duration)
+
+type instrumentedValueGeneratorFactory struct {
+	factory ValueGeneratorFactory
+	observe GenerationObserver
+}
+
+type instrumentedValueGenerator struct {
+	generator ValueGenerator
+	valueType string
+	observe   GenerationObserver
+}
+
+// NewInstrumentedValueGeneratorFactory returns a ValueGeneratorFactory whose
+// generators report how long every generation took
+func NewInstrumentedValueGeneratorFactory(factory ValueGeneratorFactory, observe GenerationObserver) ValueGeneratorFactory {
+	return instrumentedValueGeneratorFactory{factory: factory, observe: observe}
+}
+
+func (f instrumentedValueGeneratorFactory) GetGenerator(valueType string) (ValueGenerator, error) {
+	generator, err := f.factory.GetGenerator(valueType)
+	if err != nil {
+		return nil, err
+	}
+
+	return instrumentedValueGenerator{generator: generator, valueType: valueType, observe: f.observe}, nil
+}
+
+func (g instrumentedValueGenerator) Generate(parameters interface{}) (interface{}, error) {
+	start := time.Now()
+	value, err := g.generator.Generate(parameters)
+	g.observe(g.valueType, time.Since(start))
+	return value, err
+}
@@ -0,0 +1,53 @@
+package types_test
+
+import (
+	. "github.com/cloudfoundry/config-server/types"
+
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry/config-server/types/typesfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InstrumentedValueGeneratorFactory", func() {
+	var (
+		fakeFactory   *typesfakes.FakeValueGeneratorFactory
+		fakeGenerator *typesfakes.FakeValueGenerator
+		observedTypes []string
+		factory       ValueGeneratorFactory
+	)
+
+	BeforeEach(func() {
+		fakeFactory = &typesfakes.FakeValueGeneratorFactory{}
+		fakeGenerator = &typesfakes.FakeValueGenerator{}
+		observedTypes = nil
+		factory = NewInstrumentedValueGeneratorFactory(fakeFactory, func(valueType string, duration time.Duration) {
+			Expect(duration).To(BeNumerically(">=", 0))
+			observedTypes = append(observedTypes, valueType)
+		})
+	})
+
+	It("reports every generation by the type of the generator", func() {
+		fakeFactory.GetGeneratorReturns(fakeGenerator, nil)
+		fakeGenerator.GenerateReturns("s3cr3t", nil)
+
+		generator, err := factory.GetGenerator("password")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fakeFactory.GetGeneratorArgsForCall(0)).To(Equal("password"))
+
+		Expect(generator.Generate(map[string]interface{}{"length": 10})).To(Equal("s3cr3t"))
+		Expect(fakeGenerator.GenerateArgsForCall(0)).To(Equal(map[string]interface{}{"length": 10}))
+		Expect(observedTypes).To(Equal([]string{"password"}))
+	})
+
+	It("returns errors of the wrapped factory", func() {
+		fakeFactory.GetGeneratorReturns(nil, errors.New("Unsupported value type: bad_type"))
+
+		_, err := factory.GetGenerator("bad_type")
+		Expect(err).To(HaveOccurred())
+		Expect(observedTypes).To(BeEmpty())
+	})
+})
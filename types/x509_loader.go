@@ -7,23 +7,38 @@ import (
 	"io/ioutil"
 
 	"github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cloudfoundry/config-server/config"
 )
 
 type x509Loader struct {
 	certFilePath, keyFilePath string
+	namedCAs                  map[string]config.CAConfig
 }
 
-func NewX509Loader(certFilePath, keyFilePath string) CertsLoader {
-	return x509Loader{certFilePath, keyFilePath}
+// NewX509Loader returns a CertsLoader that reads CAs from files. The CA given
+// by certFilePath and keyFilePath is used when no CA name is requested; named
+// CAs are looked up in namedCAs.
+func NewX509Loader(certFilePath, keyFilePath string, namedCAs map[string]config.CAConfig) CertsLoader {
+	return x509Loader{certFilePath, keyFilePath, namedCAs}
 }
 
-func (l x509Loader) LoadCerts(_ string) (*x509.Certificate, *rsa.PrivateKey, error) {
-	crt, err := l.parseCertificate(l.certFilePath)
+func (l x509Loader) LoadCerts(caName string) (*x509.Certificate, *rsa.PrivateKey, error) {
+	certFilePath, keyFilePath := l.certFilePath, l.keyFilePath
+
+	if caName != "" {
+		caConfig, found := l.namedCAs[caName]
+		if !found {
+			return nil, nil, errors.Errorf("CA '%s' not found", caName)
+		}
+		certFilePath, keyFilePath = caConfig.CertificateFilePath, caConfig.PrivateKeyFilePath
+	}
+
+	crt, err := l.parseCertificate(certFilePath)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	key, err := l.parsePrivateKey(l.keyFilePath)
+	key, err := l.parsePrivateKey(keyFilePath)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -32,12 +47,16 @@ func (l x509Loader) LoadCerts(_ string) (*x509.Certificate, *rsa.PrivateKey, err
 }
 
 func (l x509Loader) parseCertificate(certFilePath string) (*x509.Certificate, error) {
-	cf, e := ioutil.ReadFile(l.certFilePath)
+	cf, e := ioutil.ReadFile(certFilePath)
 	if e != nil {
 		return nil, errors.Error("Failed to load certificate file")
 	}
 
 	cpb, _ := pem.Decode(cf)
+	if cpb == nil {
+		return nil, errors.Error("Failed to decode certificate file")
+	}
+
 	crt, e := x509.ParseCertificate(cpb.Bytes)
 
 	if e != nil {
@@ -48,12 +67,15 @@ func (l x509Loader) parseCertificate(certFilePath string) (*x509.Certificate, er
 }
 
 func (l x509Loader) parsePrivateKey(keyFilePath string) (*rsa.PrivateKey, error) {
-	kf, e := ioutil.ReadFile(l.keyFilePath)
+	kf, e := ioutil.ReadFile(keyFilePath)
 	if e != nil {
 		return nil, errors.Error("Failed to load private key file")
 	}
 
 	kpb, _ := pem.Decode(kf)
+	if kpb == nil {
+		return nil, errors.Error("Failed to decode private key file")
+	}
 
 	key, e := x509.ParsePKCS1PrivateKey(kpb.Bytes)
 	if e != nil {
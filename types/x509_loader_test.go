@@ -0,0 +1,80 @@
+package types_test
+
+import (
+	. "github.com/cloudfoundry/config-server/types"
+
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry/config-server/config"
+	"github.com/cloudfoundry/config-server/types/typesfakes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("X509Loader", func() {
+	var (
+		tempDir    string
+		defaultCA  CertResponse
+		natsCA     CertResponse
+		x509Loader CertsLoader
+	)
+
+	writeCA := func(name string, ca CertResponse) config.CAConfig {
+		caConfig := config.CAConfig{
+			CertificateFilePath: filepath.Join(tempDir, name+".crt"),
+			PrivateKeyFilePath:  filepath.Join(tempDir, name+".key"),
+		}
+		Expect(ioutil.WriteFile(caConfig.CertificateFilePath, []byte(ca.Certificate), 0600)).To(Succeed())
+		Expect(ioutil.WriteFile(caConfig.PrivateKeyFilePath, []byte(ca.PrivateKey), 0600)).To(Succeed())
+		return caConfig
+	}
+
+	BeforeEach(func() {
+		var err error
+		tempDir, err = ioutil.TempDir("", "x509-loader")
+		Expect(err).ToNot(HaveOccurred())
+
+		generator := NewCertificateGenerator(&typesfakes.FakeCertsLoader{})
+		defaultCA = getCertResp(generator, map[interface{}]interface{}{"common_name": "default", "is_ca": true})
+		natsCA = getCertResp(generator, map[interface{}]interface{}{"common_name": "nats", "is_ca": true})
+
+		defaultCAConfig := writeCA("default", defaultCA)
+		x509Loader = NewX509Loader(defaultCAConfig.CertificateFilePath, defaultCAConfig.PrivateKeyFilePath, map[string]config.CAConfig{
+			"nats":   writeCA("nats", natsCA),
+			"broken": {CertificateFilePath: filepath.Join(tempDir, "missing.crt"), PrivateKeyFilePath: filepath.Join(tempDir, "missing.key")},
+		})
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tempDir)
+	})
+
+	It("loads the default CA when no CA name is given", func() {
+		crt, key, err := x509Loader.LoadCerts("")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(crt.Subject.CommonName).To(Equal("default"))
+		Expect(key).ToNot(BeNil())
+	})
+
+	It("loads the named CA", func() {
+		crt, key, err := x509Loader.LoadCerts("nats")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(crt.Subject.CommonName).To(Equal("nats"))
+		Expect(key).ToNot(BeNil())
+	})
+
+	It("returns an error when named CA is not configured", func() {
+		_, _, err := x509Loader.LoadCerts("uaa")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("CA 'uaa' not found"))
+	})
+
+	It("returns an error when named CA files cannot be read", func() {
+		_, _, err := x509Loader.LoadCerts("broken")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Failed to load certificate file"))
+	})
+})